@@ -2,13 +2,37 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"orcrux/bip39"
+	"orcrux/securemem"
 	"orcrux/shamir"
+	"os"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
 	ctx context.Context
+
+	mu            sync.Mutex
+	pendingSealed []byte // Sealed shard file awaiting UnlockShard
+
+	dealerKey        ed25519.PrivateKey // Signs split shards and manifests, if loaded
+	pinnedKey        ed25519.PublicKey  // Dealer key that incoming signatures must match
+	strictSignatures bool               // Reject unsigned shards and manifests
+
+	ceremony       *ceremony       // Current or last key ceremony
+	reconstruction *reconstruction // Current or last reconstruction session
+
+	clipboard     clipboard      // System clipboard, set at startup
+	clipboardHold *clipboardHold // Value copied by the app, until it is cleared
 }
 
 // NewApp creates a new App application struct
@@ -20,25 +44,120 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.clipboard = runtimeClipboard{ctx: ctx}
+
+	if path, err := pinnedDealerKeyPath(); err == nil {
+		pub, err := loadPinnedDealerKey(path)
+		if err != nil {
+			runtime.LogErrorf(ctx, "ignoring pinned dealer key: %v", err)
+		}
+		a.pinnedKey = pub
+	}
+}
+
+// beforeClose is called when the window is about to close. A shard or secret
+// still held on the clipboard is cleared, so it does not outlive the app.
+func (a *App) beforeClose(ctx context.Context) bool {
+	if _, err := a.releaseClipboard(); err != nil {
+		runtime.LogErrorf(ctx, "clearing the clipboard: %v", err)
+	}
+	return false
 }
 
 // Response represents the standard response format
 type Response struct {
-	Error *string     `json:"error"`
-	Data  interface{} `json:"data"`
+	Error  *string                `json:"error"`
+	Code   string                 `json:"code,omitempty"`   // Machine-readable error code
+	Index  *int                   `json:"index,omitempty"`  // Index of the shard the error refers to
+	Params map[string]interface{} `json:"params,omitempty"` // Details of an invalid parameter
+	Data   interface{}            `json:"data"`
 }
 
-func (a *App) Split(secret string, shards int, shardsNeeded int, output string) string {
-	out, err := shamir.Split([]byte(secret), shards, shardsNeeded, output)
+// errorCodes maps the shamir sentinel errors to the codes sent to the frontend.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{shamir.ErrEmptySecret, "empty_secret"},
+	{shamir.ErrInvalidShardCount, "invalid_shard_count"},
+	{shamir.ErrInvalidThreshold, "invalid_threshold"},
+	{shamir.ErrInvalidOutput, "invalid_output"},
+	{shamir.ErrNoShards, "no_shards"},
+	{shamir.ErrTooFewShards, "too_few_shards"},
+	{shamir.ErrMixedEncodings, "mixed_encodings"},
+	{shamir.ErrDuplicateShard, "duplicate_shard"},
+	{shamir.ErrInconsistentLength, "inconsistent_length"},
+	{shamir.ErrInvalidPadding, "invalid_padding"},
+	{shamir.ErrPaddingMismatch, "padding_mismatch"},
+	{shamir.ErrMixedTransforms, "mixed_transforms"},
+	{shamir.ErrDecompressedTooLarge, "decompressed_too_large"},
+	{bip39.ErrInvalidWordCount, "mnemonic_word_count"},
+	{bip39.ErrUnknownWord, "mnemonic_unknown_word"},
+	{bip39.ErrChecksum, "mnemonic_checksum"},
+	{bip39.ErrInvalidEntropy, "not_a_mnemonic"},
+	{ErrManifestDigest, "manifest_digest"},
+	{ErrUnsigned, "unsigned"},
+	{ErrBadSignature, "bad_signature"},
+	{ErrNoDealerKey, "no_dealer_key"},
+	{ErrNoCeremony, "no_ceremony"},
+	{ErrCeremonyInProgress, "ceremony_in_progress"},
+	{ErrCeremonyStage, "ceremony_stage"},
+	{ErrAcknowledgement, "acknowledgement_mismatch"},
+	{ErrNoSession, "no_session"},
+	{ErrSessionComplete, "session_complete"},
+	{ErrNoClipboard, "no_clipboard"},
+	{errSealed, "passphrase_required"},
+	{errPGPEncrypted, "pgp_encrypted"},
+	{errShardFile, "shard_file"},
+}
+
+// describeError fills the structured error fields of a response from err.
+func (r *Response) describeError(err error) {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			r.Code = c.code
+			break
+		}
+	}
+
+	var spe *shamir.ShareParseError
+	if errors.As(err, &spe) {
+		index := spe.Index
+		r.Index = &index
+		if r.Code == "" {
+			r.Code = "invalid_share"
+		}
+	}
 
+	var pe *shamir.ParamError
+	if errors.As(err, &pe) {
+		r.Params = map[string]interface{}{"name": pe.Name, "value": pe.Value, "min": pe.Min, "max": pe.Max}
+	}
+
+	var te *shamir.TranscriptionError
+	if errors.As(err, &te) {
+		r.Params = map[string]interface{}{"line": te.Line, "block": te.Block}
+	}
+}
+
+// RecoveredSecret is the binary-safe result of a reconstruction.
+type RecoveredSecret struct {
+	Data        string `json:"data"`        // Base64-encoded secret bytes
+	ContentType string `json:"contentType"` // Detected MIME type of the secret
+	Size        int    `json:"size"`        // Secret length in bytes
+}
+
+// newResponse marshals a result or an error into the standard JSON response.
+func newResponse(data interface{}, err error) string {
 	response := Response{}
 	if err != nil {
 		errorMsg := err.Error()
 		response.Error = &errorMsg
 		response.Data = nil
+		response.describeError(err)
 	} else {
 		response.Error = nil
-		response.Data = out
+		response.Data = data
 	}
 
 	jsonResponse, jsonErr := json.Marshal(response)
@@ -50,24 +169,135 @@ func (a *App) Split(secret string, shards int, shardsNeeded int, output string)
 	return string(jsonResponse)
 }
 
+// Encodings returns the names of the share encodings Split accepts as output,
+// so the frontend can offer every registered encoding.
+func (a *App) Encodings() []string {
+	return shamir.Encodings()
+}
+
+// Split splits a text secret into shards, byte for byte.
+//
+// The secret arrives as a string, which is immutable: neither it nor the
+// JSON it was decoded from can be wiped, and both stay in memory until the
+// garbage collector reclaims them. SplitPath reads the secret into a buffer
+// that is wiped after use.
+//
+// Parameters:
+//   - secret: The text to split
+//   - shards: Total number of shards to generate (2-255)
+//   - shardsNeeded: Number of shards required to reconstruct (2-shards)
+//   - output: Name of a registered share encoding, optionally followed by
+//     "+deflate" to compress the secret and "+pad" or "+padN" to hide its
+//     length
+//
+// Returns:
+//   - A JSON response with the newline-separated shards, or an error if the
+//     parameters are invalid
+func (a *App) Split(secret string, shards int, shardsNeeded int, output string) string {
+	out, err := shamir.Split([]byte(secret), shards, shardsNeeded, output)
+	return a.splitResponse(out, err)
+}
+
+// SplitMnemonic splits a BIP-39 seed phrase.
+//
+// The checksum of the phrase is verified and the entropy it encodes is split
+// instead of the text, which gives shorter shards. RecomposeMnemonic turns the
+// recovered entropy back into the phrase.
+//
+// Parameters:
+//   - mnemonic: The English BIP-39 mnemonic to split
+//   - shards, shardsNeeded, output: As for Split
+//
+// Returns:
+//   - A JSON response with the newline-separated shards, or an error if the
+//     parameters are invalid or the mnemonic is not valid
+func (a *App) SplitMnemonic(mnemonic string, shards int, shardsNeeded int, output string) string {
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	defer securemem.Wipe(entropy)
+	out, err := shamir.Split(entropy, shards, shardsNeeded, output)
+	return a.splitResponse(out, err)
+}
+
+// SplitBytes splits a binary secret given as standard base64.
+//
+// Unlike Split, the secret never goes through a UTF-8 string, so raw keys and
+// keystores are split byte for byte.
+func (a *App) SplitBytes(secretBase64 string, shards int, shardsNeeded int, output string) string {
+	secret, err := base64.StdEncoding.DecodeString(secretBase64)
+	if err != nil {
+		return newResponse(nil, fmt.Errorf("secret is not valid base64: %w", err))
+	}
+	defer securemem.Wipe(secret)
+	out, err := shamir.Split(secret, shards, shardsNeeded, output)
+	return a.splitResponse(out, err)
+}
+
+// SplitPath splits the exact contents of the file at path.
+func (a *App) SplitPath(path string, shards int, shardsNeeded int, output string) string {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	defer securemem.Wipe(secret)
+	out, err := shamir.Split(secret, shards, shardsNeeded, output)
+	return a.splitResponse(out, err)
+}
+
 func (a *App) Recompose(shards []string) string {
+	shards, err := a.verifiedShards(shards)
+	if err != nil {
+		return newResponse(nil, err)
+	}
 	out, err := shamir.Recompose(shards)
-
-	response := Response{}
 	if err != nil {
-		errorMsg := err.Error()
-		response.Error = &errorMsg
-		response.Data = nil
-	} else {
-		response.Error = nil
-		response.Data = string(out)
+		return newResponse(nil, err)
 	}
+	defer securemem.Wipe(out)
+	return newResponse(string(out), nil)
+}
 
-	jsonResponse, jsonErr := json.Marshal(response)
-	if jsonErr != nil {
-		// Fallback to simple error format if JSON marshaling fails
-		return jsonErr.Error()
+// RecomposeBytes reconstructs a secret without assuming it is text.
+//
+// The secret is returned as base64 along with its detected content type, so
+// binary secrets survive the trip through JSON and the frontend unchanged.
+func (a *App) RecomposeBytes(shards []string) string {
+	shards, err := a.verifiedShards(shards)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	out, err := shamir.Recompose(shards)
+	if err != nil {
+		return newResponse(nil, err)
 	}
+	defer securemem.Wipe(out)
+	return newResponse(RecoveredSecret{
+		Data:        base64.StdEncoding.EncodeToString(out),
+		ContentType: http.DetectContentType(out),
+		Size:        len(out),
+	}, nil)
+}
 
-	return string(jsonResponse)
+// RecomposeMnemonic reconstructs a BIP-39 seed phrase split by SplitMnemonic.
+//
+// Parameters:
+//   - shards: The shards to combine
+//
+// Returns:
+//   - A JSON response with the English mnemonic, or an error if the shards
+//     do not combine or the secret is not 16 to 32 bytes of entropy
+func (a *App) RecomposeMnemonic(shards []string) string {
+	shards, err := a.verifiedShards(shards)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	out, err := shamir.Recompose(shards)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	defer securemem.Wipe(out)
+	mnemonic, err := bip39.NewMnemonic(out)
+	return newResponse(mnemonic, err)
 }
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"orcrux/shamir"
 )
 
 // TestNewApp tests the App constructor
@@ -152,13 +159,13 @@ func TestAppSplit(t *testing.T) {
 				t.Errorf("Split() returned %d lines, expected %d", len(lines), tt.expectedLines)
 			}
 
-			// Verify each line format: "xx:encoded_data"
+			// Verify each line format: "xx:encoding:encoded_data"
 			for i, line := range lines {
 				if line == "" {
 					continue
 				}
 				parts := strings.Split(line, ":")
-				if len(parts) != 2 {
+				if len(parts) != 3 {
 					t.Errorf("Line %d has invalid format: %s", i, line)
 					continue
 				}
@@ -169,7 +176,7 @@ func TestAppSplit(t *testing.T) {
 				}
 
 				// Check that encoded data is not empty
-				if len(parts[1]) == 0 {
+				if len(parts[2]) == 0 {
 					t.Errorf("Line %d has empty encoded data", i)
 				}
 			}
@@ -507,7 +514,7 @@ func TestAppErrorHandling(t *testing.T) {
 			shards:        3,
 			shardsNeeded:  2,
 			output:        "invalid",
-			expectedError: "output must be 'hex' or 'base64'",
+			expectedError: "output must be a registered encoding",
 		},
 	}
 
@@ -535,6 +542,321 @@ func TestAppErrorHandling(t *testing.T) {
 	}
 }
 
+// TestAppBinaryRoundTrip tests that binary secrets survive SplitBytes and RecomposeBytes unchanged
+func TestAppBinaryRoundTrip(t *testing.T) {
+	app := NewApp()
+
+	// Every byte value, including invalid UTF-8 sequences
+	secret := make([]byte, 256)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+
+	for _, output := range []string{"hex", "base64"} {
+		t.Run(output, func(t *testing.T) {
+			var splitResponse Response
+			if err := json.Unmarshal([]byte(app.SplitBytes(base64.StdEncoding.EncodeToString(secret), 3, 2, output)), &splitResponse); err != nil {
+				t.Fatalf("Failed to parse split JSON response: %v", err)
+			}
+			if splitResponse.Error != nil {
+				t.Fatalf("SplitBytes() returned unexpected error: %s", *splitResponse.Error)
+			}
+			shareLines := strings.Split(strings.TrimSpace(splitResponse.Data.(string)), "\n")
+
+			var response struct {
+				Error *string         `json:"error"`
+				Data  RecoveredSecret `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(app.RecomposeBytes(shareLines[:2])), &response); err != nil {
+				t.Fatalf("Failed to parse recompose JSON response: %v", err)
+			}
+			if response.Error != nil {
+				t.Fatalf("RecomposeBytes() returned unexpected error: %s", *response.Error)
+			}
+
+			got, err := base64.StdEncoding.DecodeString(response.Data.Data)
+			if err != nil {
+				t.Fatalf("RecomposeBytes() data is not base64: %v", err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Error("RecomposeBytes() did not return the original bytes")
+			}
+			if response.Data.Size != len(secret) {
+				t.Errorf("RecomposeBytes() size = %d, want %d", response.Data.Size, len(secret))
+			}
+			if response.Data.ContentType != "application/octet-stream" {
+				t.Errorf("RecomposeBytes() content type = %q, want application/octet-stream", response.Data.ContentType)
+			}
+		})
+	}
+}
+
+// TestAppSplitBytesErrors tests input validation of the binary-safe split methods
+func TestAppSplitBytesErrors(t *testing.T) {
+	app := NewApp()
+
+	tests := []struct {
+		name   string
+		result string
+	}{
+		{name: "invalid base64", result: app.SplitBytes("not base64!", 3, 2, "hex")},
+		{name: "empty secret", result: app.SplitBytes("", 3, 2, "hex")},
+		{name: "missing file", result: app.SplitPath(filepath.Join(t.TempDir(), "missing"), 3, 2, "hex")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response Response
+			if err := json.Unmarshal([]byte(tt.result), &response); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+			if response.Error == nil {
+				t.Errorf("expected an error, got: %+v", response)
+			}
+		})
+	}
+}
+
+// TestAppSplitPath tests splitting the exact contents of a file
+func TestAppSplitPath(t *testing.T) {
+	app := NewApp()
+
+	secret := []byte{0x00, 0xff, 0xfe, 0x80, 'k', 'e', 'y'}
+	path := filepath.Join(t.TempDir(), "secret.bin")
+	if err := os.WriteFile(path, secret, 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	var splitResponse Response
+	if err := json.Unmarshal([]byte(app.SplitPath(path, 3, 2, "base64")), &splitResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if splitResponse.Error != nil {
+		t.Fatalf("SplitPath() returned unexpected error: %s", *splitResponse.Error)
+	}
+
+	shareLines := strings.Split(strings.TrimSpace(splitResponse.Data.(string)), "\n")
+	var response struct {
+		Error *string         `json:"error"`
+		Data  RecoveredSecret `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(app.RecomposeBytes(shareLines[1:])), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Error != nil {
+		t.Fatalf("RecomposeBytes() returned unexpected error: %s", *response.Error)
+	}
+	if response.Data.Data != base64.StdEncoding.EncodeToString(secret) {
+		t.Error("SplitPath() round trip did not preserve the file contents")
+	}
+}
+
+func TestAppStructuredErrors(t *testing.T) {
+	app := NewApp()
+
+	var response Response
+	if err := json.Unmarshal([]byte(app.Split("secret", 5, 7, "hex")), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Code != "invalid_threshold" {
+		t.Errorf("Split() code = %q, want %q", response.Code, "invalid_threshold")
+	}
+	if response.Params["name"] != "shardsNeeded" || response.Params["value"] != float64(7) || response.Params["max"] != float64(5) {
+		t.Errorf("Split() params = %v", response.Params)
+	}
+
+	response = Response{}
+	if err := json.Unmarshal([]byte(app.Recompose([]string{"01:616263", "02:646566", "01:616264"})), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Code != "duplicate_shard" {
+		t.Errorf("Recompose() code = %q, want %q", response.Code, "duplicate_shard")
+	}
+	if response.Index == nil || *response.Index != 2 {
+		t.Errorf("Recompose() index = %v, want 2", response.Index)
+	}
+
+	response = Response{}
+	if err := json.Unmarshal([]byte(app.Recompose([]string{"01:616263", "garbage"})), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Code != "invalid_share" || response.Index == nil || *response.Index != 1 {
+		t.Errorf("Recompose() code = %q, index = %v, want invalid_share at 1", response.Code, response.Index)
+	}
+
+	response = Response{}
+	grouped := shamir.Grouped.Encode([]byte("abc"))
+	typo := "y"
+	if grouped[0] == 'y' {
+		typo = "b"
+	}
+	shard := "02:grouped:" + typo + grouped[1:]
+	if err := json.Unmarshal([]byte(app.Recompose([]string{"01:grouped:" + shamir.Grouped.Encode([]byte("def")), shard})), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Index == nil || *response.Index != 1 || response.Params["line"] != float64(1) || response.Params["block"] != float64(1) {
+		t.Errorf("Recompose() index = %v, params = %v, want line 1 block 1 of shard 1", response.Index, response.Params)
+	}
+}
+
+func TestAppSplitMnemonic(t *testing.T) {
+	app := NewApp()
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+	var splitResponse Response
+	if err := json.Unmarshal([]byte(app.SplitMnemonic(mnemonic, 3, 2, "hex")), &splitResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if splitResponse.Error != nil {
+		t.Fatalf("SplitMnemonic() returned unexpected error: %s", *splitResponse.Error)
+	}
+	shards := strings.Split(splitResponse.Data.(string), "\n")
+
+	// The 16 bytes of entropy are split, not the 75 bytes of text
+	if got := len(strings.SplitN(shards[0], ":", 3)[2]); got != 32 {
+		t.Errorf("shard data is %d hex digits, want 32", got)
+	}
+
+	var response Response
+	if err := json.Unmarshal([]byte(app.RecomposeMnemonic(shards[1:])), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Error != nil {
+		t.Fatalf("RecomposeMnemonic() returned unexpected error: %s", *response.Error)
+	}
+	if response.Data != mnemonic {
+		t.Errorf("RecomposeMnemonic() = %q, want %q", response.Data, mnemonic)
+	}
+}
+
+func TestAppSplitMnemonicInvalid(t *testing.T) {
+	app := NewApp()
+
+	tests := map[string]string{
+		"legal winner thank year wave sausage worth useful legal winner thank year": "mnemonic_checksum",
+		"legal winner thank year": "mnemonic_word_count",
+		"legal winner thank year wave sausage worth useful legal winner thank yelow": "mnemonic_unknown_word",
+	}
+	for mnemonic, code := range tests {
+		var response Response
+		if err := json.Unmarshal([]byte(app.SplitMnemonic(mnemonic, 3, 2, "hex")), &response); err != nil {
+			t.Fatalf("Failed to parse JSON response: %v", err)
+		}
+		if response.Error == nil || response.Code != code {
+			t.Errorf("SplitMnemonic(%q) error = %v, code = %q, want %s", mnemonic, response.Error, response.Code, code)
+		}
+	}
+}
+
+func TestAppSplitKeepsMnemonicText(t *testing.T) {
+	app := NewApp()
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+	// Split takes the text as given, even when it reads as a seed phrase
+	var splitResponse Response
+	if err := json.Unmarshal([]byte(app.Split(mnemonic, 3, 2, "hex")), &splitResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if splitResponse.Error != nil {
+		t.Fatalf("Split() returned unexpected error: %s", *splitResponse.Error)
+	}
+	shards := strings.Split(splitResponse.Data.(string), "\n")
+	secret, err := shamir.Recompose(shards[:2])
+	if err != nil || string(secret) != mnemonic {
+		t.Errorf("Split() shards recompose to %q, %v, want the text", secret, err)
+	}
+}
+
+func TestAppRecomposeMnemonicNotEntropy(t *testing.T) {
+	app := NewApp()
+	out, err := shamir.Split([]byte("not a seed"), 3, 2, "hex")
+	if err != nil {
+		t.Fatalf("shamir.Split() error = %v", err)
+	}
+
+	var response Response
+	if err := json.Unmarshal([]byte(app.RecomposeMnemonic(strings.Split(out, "\n"))), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Code != "not_a_mnemonic" {
+		t.Errorf("RecomposeMnemonic() code = %q, want not_a_mnemonic", response.Code)
+	}
+}
+
+func TestAppSplitPadded(t *testing.T) {
+	app := NewApp()
+
+	var splitResponse Response
+	if err := json.Unmarshal([]byte(app.Split("1234", 3, 2, "base64+pad64")), &splitResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if splitResponse.Error != nil {
+		t.Fatalf("Split() returned unexpected error: %s", *splitResponse.Error)
+	}
+	shards := strings.Split(strings.TrimSpace(splitResponse.Data.(string)), "\n")
+
+	var response Response
+	if err := json.Unmarshal([]byte(app.Recompose(shards[:2])), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Data != "1234" {
+		t.Errorf("Recompose() = %v, want the unpadded secret", response.Data)
+	}
+
+	response = Response{}
+	if err := json.Unmarshal([]byte(app.Split("1234", 3, 2, "hex+pad8")), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Code != "invalid_padding" || response.Params["name"] != "padding" {
+		t.Errorf("Split() code = %q, params = %v, want invalid_padding", response.Code, response.Params)
+	}
+}
+
+func TestAppSplitCompressed(t *testing.T) {
+	app := NewApp()
+	secret := strings.Repeat(`{"key":"value"}`, 40)
+
+	var splitResponse Response
+	if err := json.Unmarshal([]byte(app.Split(secret, 3, 2, "base64+deflate")), &splitResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if splitResponse.Error != nil {
+		t.Fatalf("Split() returned unexpected error: %s", *splitResponse.Error)
+	}
+	shards := strings.Split(strings.TrimSpace(splitResponse.Data.(string)), "\n")
+	if len(shards[0]) >= len(secret) {
+		t.Errorf("compressed shard has %d characters for a %d byte secret", len(shards[0]), len(secret))
+	}
+
+	var response Response
+	if err := json.Unmarshal([]byte(app.Recompose(shards[1:])), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if response.Data != secret {
+		t.Error("Recompose() did not decompress the secret")
+	}
+}
+
+func TestAppEncodings(t *testing.T) {
+	app := NewApp()
+	encodings := app.Encodings()
+	for _, want := range []string{"hex", "base64", "base32", "base58", "zbase32", "bech32", "grouped"} {
+		if !slices.Contains(encodings, want) {
+			t.Errorf("Encodings() = %v, missing %q", encodings, want)
+		}
+	}
+
+	for _, output := range encodings {
+		var response Response
+		if err := json.Unmarshal([]byte(app.Split("secret", 3, 2, output)), &response); err != nil {
+			t.Fatalf("Failed to parse JSON response: %v", err)
+		}
+		if response.Error != nil {
+			t.Errorf("Split(%s) returned unexpected error: %s", output, *response.Error)
+		}
+	}
+}
+
 // Mock context for testing
 type mockContext struct{}
 
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// formatTar marks shard files holding a tar archive of a directory.
+	formatTar = "tar"
+
+	// formatTarGzip marks shard files holding a gzip-compressed tar archive.
+	formatTarGzip = "tar+gzip"
+)
+
+// errExtractionStopped is reported to the reconstruction side of the pipe
+// when extraction ends before consuming the whole archive.
+var errExtractionStopped = errors.New("archive extraction stopped")
+
+// archiveEpoch is the modification time given to every archive entry, so the
+// same directory contents always produce the same archive bytes.
+var archiveEpoch = time.Unix(0, 0).UTC()
+
+// writeDirArchive writes the directory at root to w as a deterministic tar
+// stream, optionally gzip-compressed.
+//
+// Entries are named relative to the parent of root, so the archive holds a
+// single top-level directory. They are written in lexical order with
+// normalised modification times and ownership, keeping only the permission
+// bits. Only regular files and directories are supported.
+func writeDirArchive(w io.Writer, root string, compress bool) (err error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+
+	if compress {
+		var zw *gzip.Writer
+		zw, err = gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := zw.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = zw
+	}
+
+	tw := tar.NewWriter(w)
+	base := filepath.Base(filepath.Clean(root))
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(base, filepath.ToSlash(rel))
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: archiveEpoch,
+			Format:  tar.FormatPAX,
+		}
+
+		switch {
+		case d.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			return tw.WriteHeader(hdr)
+		case info.Mode().IsRegular():
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = info.Size()
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(tw, f); err != nil {
+				return err
+			}
+			return nil
+		default:
+			return fmt.Errorf("unsupported file type for %s: only regular files and directories can be split", name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// splitDirToDir packs the directory at src into a deterministic archive and
+// splits it into n shard files written to dir, any t of which restore it.
+//
+// The archive is never written to disk in plain form: it is generated once to
+// compute its size and digest for the shard headers, then generated again
+// and streamed straight into the split. Because the archive is deterministic,
+// both passes match unless the directory changed in between, in which case
+// the shard files are removed and an error is returned.
+func splitDirToDir(src, dir string, n, t int, compress bool) ([]string, error) {
+	h := sha256.New()
+	counter := &countingWriter{}
+	if err := writeDirArchive(io.MultiWriter(h, counter), src, compress); err != nil {
+		return nil, err
+	}
+
+	format := formatTar
+	if compress {
+		format = formatTarGzip
+	}
+	source := shardSource{
+		Filename: filepath.Base(filepath.Clean(src)),
+		Size:     counter.n,
+		SHA256:   hex.EncodeToString(h.Sum(nil)),
+		Format:   format,
+	}
+
+	open := func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(writeDirArchive(pw, src, compress))
+		}()
+		return pr, nil
+	}
+	return splitReaderToDir(open, source, dir, n, t)
+}
+
+// archiveEntryPath validates the name of an archive entry and returns its
+// path relative to the extraction directory. Entries must live under the
+// top-level directory recorded in the shard header; absolute paths and any
+// ".." component are refused.
+func archiveEntryPath(name, top string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("refusing unsafe archive entry %q", name)
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." || part == "." || part == "" {
+			return "", fmt.Errorf("refusing unsafe archive entry %q", name)
+		}
+	}
+	clean := path.Clean(name)
+	if clean != top && !strings.HasPrefix(clean, top+"/") {
+		return "", fmt.Errorf("refusing archive entry outside %s: %q", top, name)
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// extractArchive extracts a tar stream produced by writeDirArchive into dest.
+// Directory permissions are applied last, so read-only directories do not
+// prevent their contents from being written.
+func extractArchive(r io.Reader, dest, top string) error {
+	tr := tar.NewReader(r)
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var dirs []dirMode
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := archiveEntryPath(hdr.Name, top)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		mode := os.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{target, mode})
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("refusing unsupported archive entry %q", hdr.Name)
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreArchive reconstructs an archive from shard readers and extracts it
+// into dir/name. Extraction happens in a staging directory that is only
+// moved into place once the archive matches the size and SHA-256 recorded
+// in the shard headers.
+func restoreArchive(header shardFileHeader, xs []byte, readers []io.Reader, dir, name string) (string, error) {
+	if header.Format != formatTar && header.Format != formatTarGzip {
+		return "", fmt.Errorf("unsupported shard file format: %q", header.Format)
+	}
+
+	staging, err := os.MkdirTemp(dir, ".orcrux-restore-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	pr, pw := io.Pipe()
+	combined := make(chan error, 1)
+	go func() {
+		h := sha256.New()
+		err := combineVerified(io.MultiWriter(pw, h), h, xs, readers, header.Size, header.SHA256)
+		pw.CloseWithError(err)
+		combined <- err
+	}()
+
+	extractErr := func() error {
+		var r io.Reader = pr
+		if header.Format == formatTarGzip {
+			zr, err := gzip.NewReader(pr)
+			if err != nil {
+				return err
+			}
+			defer zr.Close()
+			r = zr
+		}
+		if err := extractArchive(r, staging, name); err != nil {
+			return err
+		}
+		// Drain trailing padding so the checksum covers the whole stream
+		_, err := io.Copy(io.Discard, pr)
+		return err
+	}()
+	pr.CloseWithError(errExtractionStopped)
+
+	// A failed reconstruction also breaks extraction, so report it first
+	// unless it only failed because extraction had already stopped.
+	if err := <-combined; err != nil && !errors.Is(err, errExtractionStopped) {
+		return "", err
+	}
+	if extractErr != nil {
+		return "", extractErr
+	}
+
+	dest := filepath.Join(dir, name)
+	if _, err := os.Stat(filepath.Join(staging, name)); err != nil {
+		return "", errors.New("archive does not contain the expected directory")
+	}
+	if err := os.Rename(filepath.Join(staging, name), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// SplitDirectory splits a whole directory into shard files.
+//
+// This function presents a native directory picker for the folder to split,
+// then another for the shard files. The folder is packed into a
+// deterministic tar archive (sorted entries, normalised timestamps,
+// permission bits kept), optionally gzip-compressed, and streamed through the
+// shamir package. CombineToFile restores the directory tree.
+//
+// Parameters:
+//   - shards: Total number of shard files to create (2-255)
+//   - shardsNeeded: Number of shard files required to restore the directory
+//   - compress: Whether to gzip the archive before splitting
+//
+// Returns:
+//   - A JSON Response whose data is the list of shard file paths, or null if
+//     the user cancelled one of the dialogs
+func (a *App) SplitDirectory(shards int, shardsNeeded int, compress bool) string {
+	src, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select a folder to split"})
+	if err != nil || src == "" {
+		return newResponse(nil, err)
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select where to save the shard files"})
+	if err != nil || dir == "" {
+		return newResponse(nil, err)
+	}
+
+	paths, err := splitDirToDir(src, dir, shards, shardsNeeded, compress)
+	return newResponse(paths, err)
+}
@@ -0,0 +1,150 @@
+// Package bip39 converts between BIP-39 mnemonics and the entropy they
+// encode, using the English word list.
+//
+// Only the entropy encoding is implemented: deriving a wallet seed from a
+// mnemonic is not needed to split one.
+package bip39
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//go:embed english.txt
+var englishText string
+
+var (
+	wordList  = strings.Fields(englishText)
+	wordIndex = func() map[string]int {
+		index := make(map[string]int, len(wordList))
+		for i, w := range wordList {
+			index[w] = i
+		}
+		return index
+	}()
+)
+
+// Sentinel errors returned by EntropyFromMnemonic and NewMnemonic.
+var (
+	// ErrInvalidWordCount is returned for a mnemonic that is not 12, 15, 18,
+	// 21 or 24 words long.
+	ErrInvalidWordCount = errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+
+	// ErrUnknownWord is returned for a word that is not in the word list.
+	ErrUnknownWord = errors.New("word is not in the BIP-39 English word list")
+
+	// ErrChecksum is returned when the checksum of a mnemonic does not match
+	// its entropy, usually because a word was mistyped or swapped.
+	ErrChecksum = errors.New("mnemonic checksum mismatch")
+
+	// ErrInvalidEntropy is returned for entropy that is not 16 to 32 bytes in
+	// steps of 4.
+	ErrInvalidEntropy = errors.New("entropy must be 16, 20, 24, 28 or 32 bytes")
+)
+
+// words splits a mnemonic into lowercase words, ignoring extra whitespace.
+func words(mnemonic string) []string {
+	return strings.Fields(strings.ToLower(mnemonic))
+}
+
+// validWordCount reports whether n is a BIP-39 mnemonic length.
+func validWordCount(n int) bool {
+	return n >= 12 && n <= 24 && n%3 == 0
+}
+
+// LooksLikeMnemonic reports whether text has the length of a mnemonic and
+// consists only of words from the list. The checksum is not verified, so a
+// mistyped mnemonic is still recognised and can be rejected with a useful
+// error by EntropyFromMnemonic.
+func LooksLikeMnemonic(text string) bool {
+	ws := words(text)
+	if !validWordCount(len(ws)) {
+		return false
+	}
+	for _, w := range ws {
+		if _, ok := wordIndex[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EntropyFromMnemonic validates a mnemonic and returns the entropy it
+// encodes. Words are matched case-insensitively.
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	ws := words(mnemonic)
+	if !validWordCount(len(ws)) {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidWordCount, len(ws))
+	}
+
+	// Each word holds 11 bits: the entropy followed by its checksum
+	bits := make([]bool, 0, len(ws)*11)
+	for i, w := range ws {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("word %d %q: %w", i+1, w, ErrUnknownWord)
+		}
+		for b := 10; b >= 0; b-- {
+			bits = append(bits, idx>>b&1 == 1)
+		}
+	}
+
+	checksumBits := len(bits) / 33
+	entropy := make([]byte, (len(bits)-checksumBits)/8)
+	for i := range entropy {
+		for b := 0; b < 8; b++ {
+			if bits[i*8+b] {
+				entropy[i] |= 1 << (7 - b)
+			}
+		}
+	}
+
+	want := checksum(entropy)
+	for i, bit := range bits[len(entropy)*8:] {
+		if bit != want[i] {
+			return nil, ErrChecksum
+		}
+	}
+	return entropy, nil
+}
+
+// NewMnemonic returns the mnemonic encoding entropy.
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("%w, got %d", ErrInvalidEntropy, len(entropy))
+	}
+
+	bits := make([]bool, 0, len(entropy)*8+len(entropy)/4)
+	for _, b := range entropy {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, b>>i&1 == 1)
+		}
+	}
+	bits = append(bits, checksum(entropy)...)
+
+	ws := make([]string, len(bits)/11)
+	for i := range ws {
+		idx := 0
+		for _, bit := range bits[i*11 : i*11+11] {
+			idx <<= 1
+			if bit {
+				idx |= 1
+			}
+		}
+		ws[i] = wordList[idx]
+	}
+	return strings.Join(ws, " "), nil
+}
+
+// checksum returns the first len(entropy)/4 bits of the SHA-256 of entropy.
+func checksum(entropy []byte) []bool {
+	sum := sha256.Sum256(entropy)
+	bits := make([]bool, len(entropy)/4)
+	for i := range bits {
+		bits[i] = sum[i/8]>>(7-i%8)&1 == 1
+	}
+	return bits
+}
@@ -0,0 +1,110 @@
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test vectors from the BIP-39 reference implementation
+var vectors = []struct {
+	entropy  string
+	mnemonic string
+}{
+	{"00000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f", "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+	{"80808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+	{"ffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"},
+	{"000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent"},
+	{"808080808080808080808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always"},
+	{"0000000000000000000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+	{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote"},
+	{"9e885d952ad362caeb4efe34a8e91bd2", "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic"},
+}
+
+func TestWordList(t *testing.T) {
+	if len(wordList) != 2048 {
+		t.Fatalf("word list has %d words, want 2048", len(wordList))
+	}
+	if wordList[0] != "abandon" || wordList[2047] != "zoo" {
+		t.Errorf("word list starts with %q and ends with %q", wordList[0], wordList[2047])
+	}
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+
+		mnemonic, err := NewMnemonic(entropy)
+		if err != nil {
+			t.Fatalf("NewMnemonic(%s) error = %v", v.entropy, err)
+		}
+		if mnemonic != v.mnemonic {
+			t.Errorf("NewMnemonic(%s) = %q, want %q", v.entropy, mnemonic, v.mnemonic)
+		}
+
+		got, err := EntropyFromMnemonic(v.mnemonic)
+		if err != nil {
+			t.Fatalf("EntropyFromMnemonic(%q) error = %v", v.mnemonic, err)
+		}
+		if !bytes.Equal(got, entropy) {
+			t.Errorf("EntropyFromMnemonic(%q) = %x, want %s", v.mnemonic, got, v.entropy)
+		}
+	}
+}
+
+func TestEntropyFromMnemonicNormalizes(t *testing.T) {
+	got, err := EntropyFromMnemonic("  Legal WINNER thank year wave\nsausage worth useful legal winner thank yellow ")
+	if err != nil {
+		t.Fatalf("EntropyFromMnemonic() error = %v", err)
+	}
+	if hex.EncodeToString(got) != "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f" {
+		t.Errorf("EntropyFromMnemonic() = %x", got)
+	}
+}
+
+func TestEntropyFromMnemonicErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		want     error
+	}{
+		{name: "too short", mnemonic: "abandon abandon abandon", want: ErrInvalidWordCount},
+		{name: "not a multiple of three", mnemonic: strings.Repeat("abandon ", 13), want: ErrInvalidWordCount},
+		{name: "unknown word", mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abouts", want: ErrUnknownWord},
+		{name: "bad checksum", mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", want: ErrChecksum},
+		{name: "swapped words", mnemonic: "legal winner thank year wave sausage worth useful legal winner yellow thank", want: ErrChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EntropyFromMnemonic(tt.mnemonic); !errors.Is(err, tt.want) {
+				t.Errorf("EntropyFromMnemonic() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMnemonicInvalidEntropy(t *testing.T) {
+	for _, n := range []int{0, 15, 17, 36} {
+		if _, err := NewMnemonic(make([]byte, n)); !errors.Is(err, ErrInvalidEntropy) {
+			t.Errorf("NewMnemonic(%d bytes) error = %v, want %v", n, err, ErrInvalidEntropy)
+		}
+	}
+}
+
+func TestLooksLikeMnemonic(t *testing.T) {
+	tests := map[string]bool{
+		vectors[0].mnemonic: true,
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon": true,
+		"correct horse battery staple":                    false,
+		"hello world this is not a seed phrase at all ok": false,
+		"": false,
+	}
+	for text, want := range tests {
+		if got := LooksLikeMnemonic(text); got != want {
+			t.Errorf("LooksLikeMnemonic(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
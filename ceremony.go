@@ -0,0 +1,563 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Ceremony stages, in order.
+const (
+	stageRegister = "register" // Participants are being registered
+	stageSplit    = "split"    // Parameters are confirmed, waiting for the secret
+	stageHandoff  = "handoff"  // Shards are being handed to their custodians
+	stageWipe     = "wipe"     // Every shard was acknowledged, material must be wiped
+	stageDone     = "done"     // The transcript is closed
+)
+
+// Participant roles.
+const (
+	roleCustodian = "custodian" // Receives a shard
+	roleWitness   = "witness"   // Observes and confirms the steps
+)
+
+var (
+	// ErrNoCeremony is returned by ceremony steps when no ceremony was started.
+	ErrNoCeremony = errors.New("no ceremony in progress")
+
+	// ErrCeremonyInProgress is returned when starting a ceremony while
+	// another one is open.
+	ErrCeremonyInProgress = errors.New("a ceremony is already in progress")
+
+	// ErrCeremonyStage is returned for a step taken out of order.
+	ErrCeremonyStage = errors.New("step not allowed at this stage of the ceremony")
+
+	// ErrAcknowledgement is returned when the shard a custodian reads back
+	// is not the one they were given.
+	ErrAcknowledgement = errors.New("shard read back does not match the custodian's shard")
+)
+
+// ceremonyParticipant is a person registered for the ceremony.
+type ceremonyParticipant struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// ceremonyShard is the shard dealt to one custodian, held in memory until
+// the material is wiped.
+type ceremonyShard struct {
+	Custodian    string
+	X            int
+	SHA256       string
+	Text         []byte
+	Revealed     bool
+	Acknowledged bool
+}
+
+// ceremony tracks a key ceremony and writes every step to its transcript.
+type ceremony struct {
+	title        string
+	stage        string
+	participants []ceremonyParticipant
+	threshold    int
+	output       string
+	shards       []*ceremonyShard
+	transcript   *transcript
+}
+
+// CustodianState is the handoff status of one custodian's shard.
+type CustodianState struct {
+	Name         string `json:"name"`
+	X            int    `json:"x,omitempty"`
+	Fingerprint  string `json:"fingerprint,omitempty"` // SHA-256 of the shard
+	Revealed     bool   `json:"revealed"`
+	Acknowledged bool   `json:"acknowledged"`
+}
+
+// CeremonyState is the state of a ceremony as shown to the operator. It
+// never contains shard data.
+type CeremonyState struct {
+	Title      string           `json:"title"`
+	Stage      string           `json:"stage"`
+	Witnesses  []string         `json:"witnesses"`
+	Custodians []CustodianState `json:"custodians"`
+	Threshold  int              `json:"threshold,omitempty"`
+	Output     string           `json:"output,omitempty"`
+	Transcript string           `json:"transcript"` // Path of the transcript file
+	Head       string           `json:"head"`       // Hash of the last transcript entry
+}
+
+// startCeremony opens a transcript at path and records the start of a ceremony.
+func startCeremony(path, title string) (*ceremony, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("ceremony title is required")
+	}
+	t, err := createTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &ceremony{title: title, stage: stageRegister, transcript: t}
+	if err := t.append("ceremony_started", map[string]string{"title": title}); err != nil {
+		t.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// require returns ErrCeremonyStage unless the ceremony is at stage.
+func (c *ceremony) require(stage string) error {
+	if c.stage != stage {
+		return fmt.Errorf("%w: ceremony is at the %s stage", ErrCeremonyStage, c.stage)
+	}
+	return nil
+}
+
+// custodians returns the names of the registered custodians, in order.
+func (c *ceremony) custodians() []string {
+	var names []string
+	for _, p := range c.participants {
+		if p.Role == roleCustodian {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// register adds a custodian or a witness.
+func (c *ceremony) register(name, role string) error {
+	if err := c.require(stageRegister); err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("participant name is required")
+	}
+	if role != roleCustodian && role != roleWitness {
+		return fmt.Errorf("role must be %q or %q", roleCustodian, roleWitness)
+	}
+	for _, p := range c.participants {
+		if strings.EqualFold(p.Name, name) {
+			return fmt.Errorf("%s is already registered", p.Name)
+		}
+	}
+	if role == roleCustodian && len(c.custodians()) == 255 {
+		return shamir.ErrInvalidShardCount
+	}
+
+	p := ceremonyParticipant{Name: name, Role: role}
+	if err := c.transcript.append("participant_registered", p); err != nil {
+		return err
+	}
+	c.participants = append(c.participants, p)
+	return nil
+}
+
+// confirm fixes the threshold and the output encoding. The number of shards
+// is the number of registered custodians.
+func (c *ceremony) confirm(threshold int, output, confirmedBy string) error {
+	if err := c.require(stageRegister); err != nil {
+		return err
+	}
+	n := len(c.custodians())
+	if n < 2 {
+		return shamir.ErrInvalidShardCount
+	}
+	if threshold < 2 || threshold > n {
+		return shamir.ErrInvalidThreshold
+	}
+	if err := shamir.CheckOutput(output); err != nil {
+		return err
+	}
+	if !c.isWitness(confirmedBy) {
+		return fmt.Errorf("parameters must be confirmed by a registered witness, not %q", confirmedBy)
+	}
+
+	err := c.transcript.append("parameters_confirmed", map[string]interface{}{
+		"shards":      n,
+		"threshold":   threshold,
+		"output":      output,
+		"custodians":  c.custodians(),
+		"confirmedBy": strings.TrimSpace(confirmedBy),
+	})
+	if err != nil {
+		return err
+	}
+	c.threshold, c.output, c.stage = threshold, output, stageSplit
+	return nil
+}
+
+// isWitness reports whether name is a registered witness.
+func (c *ceremony) isWitness(name string) bool {
+	for _, p := range c.participants {
+		if p.Role == roleWitness && strings.EqualFold(p.Name, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// split splits the secret with the confirmed parameters and assigns one
+// shard to each custodian. Shards are signed when key is not nil. Only
+// their fingerprints are written to the transcript: shares are drawn from
+// fresh randomness, so a fingerprint reveals nothing about the secret. The
+// size of the secret is left out as well.
+func (c *ceremony) split(secret []byte, key ed25519.PrivateKey) error {
+	if err := c.require(stageSplit); err != nil {
+		return err
+	}
+	names := c.custodians()
+	out, err := shamir.Split(secret, len(names), c.threshold, c.output)
+	if err != nil {
+		return err
+	}
+	dealer := ""
+	if key != nil {
+		if out, err = signShards(key, out); err != nil {
+			return err
+		}
+		dealer = keyFingerprint(key.Public().(ed25519.PublicKey))
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	shards := make([]*ceremonyShard, len(lines))
+	ledger := make([]ManifestShard, len(lines))
+	for i, line := range lines {
+		text, _, _ := splitShardSignature(line)
+		share, err := shamir.ParseShare(text)
+		if err != nil {
+			wipeShards(shards[:i])
+			return err
+		}
+		shards[i] = &ceremonyShard{
+			Custodian: names[i],
+			X:         int(share.X),
+			SHA256:    shardFingerprint(share),
+			Text:      []byte(line),
+		}
+		securemem.Wipe(share.Data)
+		// Shards stay in memory until handed off; keep them out of swap
+		securemem.Lock(shards[i].Text)
+		ledger[i] = ManifestShard{X: shards[i].X, Custodian: names[i], SHA256: shards[i].SHA256}
+	}
+
+	err = c.transcript.append("secret_split", map[string]interface{}{
+		"shards":    ledger,
+		"dealerKey": dealer,
+	})
+	if err != nil {
+		wipeShards(shards)
+		return err
+	}
+	c.shards, c.stage = shards, stageHandoff
+	return nil
+}
+
+// shard returns the shard of custodian i for handoff and records that it
+// was revealed.
+func (c *ceremony) shard(i int) (string, error) {
+	if err := c.require(stageHandoff); err != nil {
+		return "", err
+	}
+	if i < 0 || i >= len(c.shards) {
+		return "", fmt.Errorf("no custodian at index %d", i)
+	}
+	s := c.shards[i]
+	if s.Acknowledged {
+		return "", fmt.Errorf("%s already acknowledged their shard", s.Custodian)
+	}
+	if err := c.transcript.append("shard_revealed", map[string]interface{}{"custodian": s.Custodian, "x": s.X}); err != nil {
+		return "", err
+	}
+	s.Revealed = true
+	return string(s.Text), nil
+}
+
+// acknowledge records that custodian i received their shard. The custodian
+// proves it by reading back the shard from the copy they keep; it is
+// compared by fingerprint, so case and lookalike characters do not matter.
+func (c *ceremony) acknowledge(i int, readBack string) error {
+	if err := c.require(stageHandoff); err != nil {
+		return err
+	}
+	if i < 0 || i >= len(c.shards) {
+		return fmt.Errorf("no custodian at index %d", i)
+	}
+	s := c.shards[i]
+	if !s.Revealed || s.Acknowledged {
+		return fmt.Errorf("%w: %s has no shard awaiting acknowledgement", ErrCeremonyStage, s.Custodian)
+	}
+	text, _, err := splitShardSignature(readBack)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAcknowledgement, err)
+	}
+	share, err := shamir.ParseShare(text)
+	if err != nil || int(share.X) != s.X || shardFingerprint(share) != s.SHA256 {
+		return ErrAcknowledgement
+	}
+
+	err = c.transcript.append("shard_acknowledged", map[string]interface{}{"custodian": s.Custodian, "x": s.X, "sha256": s.SHA256})
+	if err != nil {
+		return err
+	}
+	s.Acknowledged = true
+
+	for _, s := range c.shards {
+		if !s.Acknowledged {
+			return nil
+		}
+	}
+	c.stage = stageWipe
+	return nil
+}
+
+// wipe erases the shards held in memory and closes the transcript.
+func (c *ceremony) wipe() error {
+	if err := c.require(stageWipe); err != nil {
+		return err
+	}
+	wipeShards(c.shards)
+	if err := c.transcript.append("material_wiped", map[string]int{"shards": len(c.shards)}); err != nil {
+		return err
+	}
+	return c.finish(eventCeremonyCompleted, nil)
+}
+
+// abort wipes any shards and closes the transcript with the reason given.
+func (c *ceremony) abort(reason string) error {
+	if c.stage == stageDone {
+		return fmt.Errorf("%w: ceremony is already closed", ErrCeremonyStage)
+	}
+	wipeShards(c.shards)
+	return c.finish(eventCeremonyAborted, map[string]string{"reason": strings.TrimSpace(reason), "stage": c.stage})
+}
+
+// finish writes the closing event and closes the transcript.
+func (c *ceremony) finish(event string, data interface{}) error {
+	err := c.transcript.append(event, data)
+	c.stage = stageDone
+	if closeErr := c.transcript.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// wipeShards overwrites the shard text held in memory.
+func wipeShards(shards []*ceremonyShard) {
+	for _, s := range shards {
+		if s == nil {
+			continue
+		}
+		securemem.WipeAndUnlock(s.Text)
+		s.Text = nil
+	}
+}
+
+// state returns the ceremony state shown to the operator.
+func (c *ceremony) state() CeremonyState {
+	st := CeremonyState{
+		Title:      c.title,
+		Stage:      c.stage,
+		Witnesses:  []string{},
+		Custodians: []CustodianState{},
+		Threshold:  c.threshold,
+		Output:     c.output,
+		Transcript: c.transcript.path,
+		Head:       c.transcript.head,
+	}
+	for _, p := range c.participants {
+		if p.Role == roleWitness {
+			st.Witnesses = append(st.Witnesses, p.Name)
+		}
+	}
+	for i, name := range c.custodians() {
+		cs := CustodianState{Name: name}
+		if i < len(c.shards) {
+			s := c.shards[i]
+			cs.X, cs.Fingerprint, cs.Revealed, cs.Acknowledged = s.X, s.SHA256, s.Revealed, s.Acknowledged
+		}
+		st.Custodians = append(st.Custodians, cs)
+	}
+	return st
+}
+
+// withCeremony runs step on the current ceremony and returns the new state
+// as a JSON response.
+func (a *App) withCeremony(step func(c *ceremony) error) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ceremony == nil {
+		return newResponse(nil, ErrNoCeremony)
+	}
+	if err := step(a.ceremony); err != nil {
+		return newResponse(nil, err)
+	}
+	return newResponse(a.ceremony.state(), nil)
+}
+
+// StartCeremony starts a key ceremony and opens its transcript.
+//
+// The ceremony walks through participant registration, parameter
+// confirmation, the split, the handoff of each shard with the custodian's
+// acknowledgement, and the wiping of the shards. Every step is appended to
+// a hash-chained transcript that `orcrux verify-transcript` checks later.
+// The transcript records fingerprints only, never shard data or the secret.
+//
+// Parameters:
+//   - title: A name for the ceremony, written to the transcript
+//
+// Returns:
+//   - A JSON response with the CeremonyState, null data if the save dialog
+//     was cancelled, or an error if a ceremony is already in progress or
+//     the transcript cannot be created
+func (a *App) StartCeremony(title string) string {
+	a.mu.Lock()
+	busy := a.ceremony != nil && a.ceremony.stage != stageDone
+	a.mu.Unlock()
+	if busy {
+		return newResponse(nil, ErrCeremonyInProgress)
+	}
+
+	fd := runtime.SaveDialogOptions{
+		Title:           "Save ceremony transcript",
+		DefaultFilename: "ceremony-transcript.jsonl",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Transcripts", Pattern: "*.jsonl"},
+		},
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, fd)
+	if err != nil || path == "" {
+		return newResponse(nil, err)
+	}
+	return a.startCeremony(path, title)
+}
+
+// startCeremony starts a ceremony whose transcript is written to path.
+func (a *App) startCeremony(path, title string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ceremony != nil && a.ceremony.stage != stageDone {
+		return newResponse(nil, ErrCeremonyInProgress)
+	}
+	c, err := startCeremony(path, title)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	a.ceremony = c
+	return newResponse(c.state(), nil)
+}
+
+// RegisterParticipant registers a custodian, who will receive a shard, or a
+// witness, who confirms the parameters.
+//
+// Parameters:
+//   - name: The participant's name, unique within the ceremony
+//   - role: "custodian" or "witness"
+//
+// Returns:
+//   - A JSON response with the CeremonyState, or an error
+func (a *App) RegisterParticipant(name string, role string) string {
+	return a.withCeremony(func(c *ceremony) error { return c.register(name, role) })
+}
+
+// ConfirmParameters closes registration and fixes the split parameters. One
+// shard is made per registered custodian.
+//
+// Parameters:
+//   - shardsNeeded: Number of shards required to reconstruct (2-custodians)
+//   - output: The share encoding, as accepted by Split
+//   - confirmedBy: Name of the registered witness confirming the parameters
+//
+// Returns:
+//   - A JSON response with the CeremonyState, or an error
+func (a *App) ConfirmParameters(shardsNeeded int, output string, confirmedBy string) string {
+	return a.withCeremony(func(c *ceremony) error { return c.confirm(shardsNeeded, output, confirmedBy) })
+}
+
+// CeremonySplit splits the secret with the confirmed parameters. Shards are
+// signed if a dealer key is loaded, and are kept in memory until handed off.
+// The secret itself arrives as a string, which cannot be wiped: it stays in
+// memory until the garbage collector reclaims it.
+//
+// Parameters:
+//   - secret: The secret to split
+//
+// Returns:
+//   - A JSON response with the CeremonyState, or an error
+func (a *App) CeremonySplit(secret string) string {
+	key := a.signingKey()
+	defer securemem.Wipe(key)
+	return a.withCeremony(func(c *ceremony) error { return c.split([]byte(secret), key) })
+}
+
+// RevealCeremonyShard returns the shard of one custodian, to be shown or
+// saved for them. The reveal is recorded in the transcript.
+//
+// Parameters:
+//   - index: Position of the custodian in CeremonyState.Custodians
+//
+// Returns:
+//   - A JSON response with the shard, or an error
+func (a *App) RevealCeremonyShard(index int) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ceremony == nil {
+		return newResponse(nil, ErrNoCeremony)
+	}
+	shard, err := a.ceremony.shard(index)
+	return newResponse(shard, err)
+}
+
+// AcknowledgeHandoff records that a custodian received their shard. Once
+// every custodian has acknowledged, the ceremony moves to the wipe stage.
+//
+// Parameters:
+//   - index: Position of the custodian in CeremonyState.Custodians
+//   - shard: The shard read back by the custodian from the copy they keep
+//
+// Returns:
+//   - A JSON response with the CeremonyState, or an error if the shard read
+//     back is not the one that was revealed
+func (a *App) AcknowledgeHandoff(index int, shard string) string {
+	return a.withCeremony(func(c *ceremony) error { return c.acknowledge(index, shard) })
+}
+
+// WipeCeremony erases the shards held in memory, records it, and closes the
+// transcript. The final hash in the returned state should be noted on
+// paper, so the transcript can be checked with -head later.
+//
+// Returns:
+//   - A JSON response with the final CeremonyState, or an error
+func (a *App) WipeCeremony() string {
+	return a.withCeremony(func(c *ceremony) error { return c.wipe() })
+}
+
+// AbortCeremony wipes any shards and closes the transcript with a reason.
+//
+// Parameters:
+//   - reason: Why the ceremony was stopped
+//
+// Returns:
+//   - A JSON response with the final CeremonyState, or an error
+func (a *App) AbortCeremony(reason string) string {
+	return a.withCeremony(func(c *ceremony) error { return c.abort(reason) })
+}
+
+// Ceremony returns the state of the current ceremony.
+//
+// Returns:
+//   - A JSON response with the CeremonyState, or null data if no ceremony
+//     was started
+func (a *App) Ceremony() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ceremony == nil {
+		return newResponse(nil, nil)
+	}
+	return newResponse(a.ceremony.state(), nil)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestCeremony starts a ceremony with two custodians and a witness.
+func newTestCeremony(t *testing.T) (*ceremony, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ceremony.jsonl")
+	c, err := startCeremony(path, "Root key")
+	if err != nil {
+		t.Fatalf("startCeremony() error = %v", err)
+	}
+	for _, p := range []ceremonyParticipant{{"Alice", roleCustodian}, {"Bob", roleCustodian}, {"Wendy", roleWitness}} {
+		if err := c.register(p.Name, p.Role); err != nil {
+			t.Fatalf("register(%s) error = %v", p.Name, err)
+		}
+	}
+	return c, path
+}
+
+func TestCeremony(t *testing.T) {
+	c, path := newTestCeremony(t)
+	key, _ := newDealerKey(t)
+
+	if err := c.split([]byte("root key"), nil); !errors.Is(err, ErrCeremonyStage) {
+		t.Errorf("split() before confirmation error = %v, want ErrCeremonyStage", err)
+	}
+	if err := c.confirm(2, "hex", "Alice"); err == nil {
+		t.Error("confirm() by a custodian should fail")
+	}
+	if err := c.confirm(3, "hex", "Wendy"); !errors.Is(err, shamir.ErrInvalidThreshold) {
+		t.Errorf("confirm() above the custodian count error = %v", err)
+	}
+	if err := c.confirm(2, "hex", "wendy"); err != nil {
+		t.Fatalf("confirm() error = %v", err)
+	}
+	if err := c.register("Carol", roleCustodian); !errors.Is(err, ErrCeremonyStage) {
+		t.Errorf("register() after confirmation error = %v, want ErrCeremonyStage", err)
+	}
+	if err := c.split([]byte("root key"), key); err != nil {
+		t.Fatalf("split() error = %v", err)
+	}
+
+	var shards []string
+	for i := range c.shards {
+		if err := c.acknowledge(i, ""); !errors.Is(err, ErrCeremonyStage) {
+			t.Errorf("acknowledge() before reveal error = %v", err)
+		}
+		shard, err := c.shard(i)
+		if err != nil {
+			t.Fatalf("shard(%d) error = %v", i, err)
+		}
+		shards = append(shards, shard)
+		if i > 0 {
+			if err := c.acknowledge(i, shards[0]); !errors.Is(err, ErrAcknowledgement) {
+				t.Errorf("acknowledge() with another custodian's shard error = %v", err)
+			}
+		}
+		// The custodian's copy was transcribed without the signature and in
+		// upper case
+		text, _, _ := splitShardSignature(shard)
+		if err := c.acknowledge(i, strings.ToUpper(text)); err != nil {
+			t.Fatalf("acknowledge(%d) error = %v", i, err)
+		}
+	}
+	if c.stage != stageWipe {
+		t.Fatalf("stage after handoff = %q, want %q", c.stage, stageWipe)
+	}
+
+	stripped, err := checkShardSignatures(key.Public().(ed25519.PublicKey), true, shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := shamir.Recompose(stripped); err != nil || string(secret) != "root key" {
+		t.Errorf("handed off shards recompose to %q, %v", secret, err)
+	}
+
+	var held [][]byte
+	for _, s := range c.shards {
+		held = append(held, s.Text)
+	}
+	if err := c.wipe(); err != nil {
+		t.Fatalf("wipe() error = %v", err)
+	}
+	for i, s := range c.shards {
+		if s.Text != nil || len(held[i]) == 0 || !securemem.IsZero(held[i]) {
+			t.Error("wipe() left shard material in memory")
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	summary, err := verifyTranscript(strings.NewReader(string(data)))
+	if err != nil || summary.Closed != eventCeremonyCompleted || summary.Head != c.state().Head {
+		t.Errorf("verifyTranscript() = %+v, %v", summary, err)
+	}
+	for _, shard := range shards {
+		text, _, _ := splitShardSignature(shard)
+		if strings.Contains(string(data), strings.SplitN(text, ":", 3)[2]) {
+			t.Error("transcript contains shard data")
+		}
+	}
+	if strings.Contains(string(data), "root key") {
+		t.Error("transcript contains the secret")
+	}
+	if strings.Contains(string(data), "secretSize") {
+		t.Error("transcript records the size of the secret")
+	}
+	for _, event := range []string{"participant_registered", "parameters_confirmed", "secret_split", "shard_revealed", "shard_acknowledged", "material_wiped"} {
+		if !strings.Contains(string(data), `"event":"`+event+`"`) {
+			t.Errorf("transcript has no %s event", event)
+		}
+	}
+}
+
+func TestCeremonyAbort(t *testing.T) {
+	c, path := newTestCeremony(t)
+	if err := c.abort("fire alarm"); err != nil {
+		t.Fatalf("abort() error = %v", err)
+	}
+	if err := c.abort("again"); !errors.Is(err, ErrCeremonyStage) {
+		t.Errorf("abort() of a closed ceremony error = %v", err)
+	}
+	summary, err := verifyTranscript(mustOpen(t, path))
+	if err != nil || summary.Closed != eventCeremonyAborted {
+		t.Errorf("verifyTranscript() = %+v, %v", summary, err)
+	}
+}
+
+func TestAppCeremony(t *testing.T) {
+	app := NewApp()
+	var response Response
+	json.Unmarshal([]byte(app.RegisterParticipant("Alice", roleCustodian)), &response)
+	if response.Code != "no_ceremony" {
+		t.Errorf("RegisterParticipant() without a ceremony code = %q", response.Code)
+	}
+
+	path := filepath.Join(t.TempDir(), "ceremony.jsonl")
+	json.Unmarshal([]byte(app.startCeremony(path, "Root key")), &response)
+	if response.Error != nil {
+		t.Fatalf("startCeremony() error = %s", *response.Error)
+	}
+	json.Unmarshal([]byte(app.startCeremony(path+"2", "Other")), &response)
+	if response.Code != "ceremony_in_progress" {
+		t.Errorf("second startCeremony() code = %q", response.Code)
+	}
+
+	app.RegisterParticipant("Alice", roleCustodian)
+	app.RegisterParticipant("Bob", roleCustodian)
+	app.RegisterParticipant("Wendy", roleWitness)
+	app.ConfirmParameters(2, "base64", "Wendy")
+	json.Unmarshal([]byte(app.CeremonySplit("root key")), &response)
+	state := response.Data.(map[string]interface{})
+	if state["stage"] != stageHandoff || len(state["custodians"].([]interface{})) != 2 {
+		t.Errorf("CeremonySplit() state = %v", state)
+	}
+
+	json.Unmarshal([]byte(app.WipeCeremony()), &response)
+	if response.Code != "ceremony_stage" {
+		t.Errorf("WipeCeremony() before handoff code = %q", response.Code)
+	}
+	json.Unmarshal([]byte(app.AbortCeremony("test")), &response)
+	if response.Data.(map[string]interface{})["stage"] != stageDone {
+		t.Errorf("AbortCeremony() = %+v", response)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// defaultClipboardClearAfter is how long a copied value stays on the
+// clipboard when no timeout is given.
+const defaultClipboardClearAfter = 30 * time.Second
+
+// eventClipboardCleared is emitted to the frontend when a copied value
+// expires. Its payload is true if the clipboard was cleared, false if the
+// user had copied something else in the meantime.
+const eventClipboardCleared = "clipboard:cleared"
+
+// ErrNoClipboard is returned when the clipboard cannot be used, such as
+// before the window is created.
+var ErrNoClipboard = errors.New("the clipboard is not available")
+
+// clipboard is the system clipboard. It is an interface so the clearing
+// logic can be tested without a window.
+type clipboard interface {
+	Text() (string, error)
+	SetText(text string) error
+}
+
+// runtimeClipboard is the clipboard of the Wails runtime.
+type runtimeClipboard struct {
+	ctx context.Context
+}
+
+func (c runtimeClipboard) Text() (string, error) { return runtime.ClipboardGetText(c.ctx) }
+
+func (c runtimeClipboard) SetText(text string) error { return runtime.ClipboardSetText(c.ctx, text) }
+
+// clipboardHold is a value we wrote to the clipboard. Only its hash is kept,
+// so the value itself does not stay in memory until it is cleared.
+type clipboardHold struct {
+	digest    [sha256.Size]byte
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// ClipboardState describes the value held on the clipboard by the app.
+type ClipboardState struct {
+	Held      bool       `json:"held"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // When the clipboard will be cleared
+	Cleared   bool       `json:"cleared"`             // Whether a clear removed our value
+}
+
+// clearIfHeld clears the clipboard if it still holds the value of h, and
+// reports whether it did. A value copied since by the user is left alone.
+func clearIfHeld(cb clipboard, h *clipboardHold) (bool, error) {
+	text, err := cb.Text()
+	if err != nil {
+		return false, err
+	}
+	if sha256.Sum256([]byte(text)) != h.digest {
+		return false, nil
+	}
+	return true, cb.SetText("")
+}
+
+// clipboardState returns the state of the current hold. The caller must
+// hold a.mu.
+func (a *App) clipboardState() ClipboardState {
+	if a.clipboardHold == nil {
+		return ClipboardState{}
+	}
+	expiresAt := a.clipboardHold.expiresAt
+	return ClipboardState{Held: true, ExpiresAt: &expiresAt}
+}
+
+// expireClipboard clears the clipboard when h times out, if h is still the
+// current hold.
+func (a *App) expireClipboard(h *clipboardHold) {
+	a.mu.Lock()
+	if a.clipboardHold != h {
+		a.mu.Unlock()
+		return
+	}
+	a.clipboardHold = nil
+	cleared, err := clearIfHeld(a.clipboard, h)
+	a.mu.Unlock()
+
+	if a.ctx != nil {
+		if err != nil {
+			runtime.LogErrorf(a.ctx, "clearing the clipboard: %v", err)
+		}
+		runtime.EventsEmit(a.ctx, eventClipboardCleared, cleared)
+	}
+}
+
+// releaseClipboard drops the current hold and clears the clipboard if it
+// still holds the held value, reporting whether it did.
+func (a *App) releaseClipboard() (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h := a.clipboardHold
+	if h == nil {
+		return false, nil
+	}
+	h.timer.Stop()
+	a.clipboardHold = nil
+	return clearIfHeld(a.clipboard, h)
+}
+
+// CopyToClipboard writes a shard or a recovered secret to the clipboard and
+// schedules it to be cleared.
+//
+// When the timeout expires the clipboard is cleared only if it still holds
+// this value, so anything the user copied afterwards is kept. The
+// "clipboard:cleared" event is then emitted. Copying again replaces the
+// previous schedule.
+//
+// Parameters:
+//   - text: The value to copy
+//   - clearAfterSeconds: How long the value is kept, or 0 for 30 seconds
+//
+// Returns:
+//   - A JSON response with the ClipboardState, or an error if the clipboard
+//     cannot be written
+func (a *App) CopyToClipboard(text string, clearAfterSeconds int) string {
+	clearAfter := time.Duration(clearAfterSeconds) * time.Second
+	if clearAfter <= 0 {
+		clearAfter = defaultClipboardClearAfter
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.clipboard == nil {
+		return newResponse(nil, ErrNoClipboard)
+	}
+	if a.clipboardHold != nil {
+		a.clipboardHold.timer.Stop()
+		a.clipboardHold = nil
+	}
+	if err := a.clipboard.SetText(text); err != nil {
+		return newResponse(nil, err)
+	}
+
+	h := &clipboardHold{digest: sha256.Sum256([]byte(text)), expiresAt: time.Now().Add(clearAfter)}
+	h.timer = time.AfterFunc(clearAfter, func() { a.expireClipboard(h) })
+	a.clipboardHold = h
+	return newResponse(a.clipboardState(), nil)
+}
+
+// ClearClipboard clears the clipboard now if it still holds the last value
+// copied with CopyToClipboard.
+//
+// Returns:
+//   - A JSON response with the ClipboardState, whose cleared field tells
+//     whether our value was removed, or an error if the clipboard cannot be
+//     read or written
+func (a *App) ClearClipboard() string {
+	cleared, err := a.releaseClipboard()
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	return newResponse(ClipboardState{Cleared: cleared}, nil)
+}
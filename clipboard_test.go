@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// fakeClipboard is an in-memory clipboard.
+type fakeClipboard struct {
+	mu   sync.Mutex
+	text string
+	err  error
+}
+
+func (c *fakeClipboard) Text() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text, c.err
+}
+
+func (c *fakeClipboard) SetText(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.text = text
+	return nil
+}
+
+func TestAppCopyToClipboard(t *testing.T) {
+	app := NewApp()
+	var response Response
+	json.Unmarshal([]byte(app.CopyToClipboard("01:hex:abcd", 0)), &response)
+	if response.Code != "no_clipboard" {
+		t.Errorf("CopyToClipboard() without a clipboard code = %q", response.Code)
+	}
+
+	cb := &fakeClipboard{}
+	app.clipboard = cb
+	var state struct {
+		Data ClipboardState `json:"data"`
+	}
+	json.Unmarshal([]byte(app.CopyToClipboard("01:hex:abcd", 60)), &state)
+	if cb.text != "01:hex:abcd" || !state.Data.Held || state.Data.ExpiresAt == nil {
+		t.Fatalf("CopyToClipboard() = %+v, clipboard %q", state.Data, cb.text)
+	}
+
+	// Expire the hold without waiting for its timer
+	hold := app.clipboardHold
+	app.expireClipboard(hold)
+	if cb.text != "" || app.clipboardHold != nil {
+		t.Errorf("clipboard after expiry = %q, want it cleared", cb.text)
+	}
+}
+
+func TestAppClipboardKeepsUserValue(t *testing.T) {
+	cb := &fakeClipboard{}
+	app := NewApp()
+	app.clipboard = cb
+
+	app.CopyToClipboard("secret", 60)
+	hold := app.clipboardHold
+	cb.SetText("copied by the user")
+	app.expireClipboard(hold)
+	if cb.text != "copied by the user" {
+		t.Errorf("clipboard = %q, the user's value should be kept", cb.text)
+	}
+
+	// A stale hold does not clear a newer copy
+	app.CopyToClipboard("first", 60)
+	stale := app.clipboardHold
+	app.CopyToClipboard("second", 60)
+	app.expireClipboard(stale)
+	if cb.text != "second" || app.clipboardHold == nil {
+		t.Errorf("clipboard = %q after a stale expiry, want the newer copy held", cb.text)
+	}
+
+	var state struct {
+		Data ClipboardState `json:"data"`
+	}
+	json.Unmarshal([]byte(app.ClearClipboard()), &state)
+	if !state.Data.Cleared || state.Data.Held || cb.text != "" {
+		t.Errorf("ClearClipboard() = %+v, clipboard %q", state.Data, cb.text)
+	}
+	state.Data = ClipboardState{}
+	json.Unmarshal([]byte(app.ClearClipboard()), &state)
+	if state.Data.Cleared {
+		t.Error("ClearClipboard() with nothing held should not report a clear")
+	}
+}
+
+func TestClearIfHeld(t *testing.T) {
+	app := NewApp()
+	cb := &fakeClipboard{}
+	app.clipboard = cb
+	app.CopyToClipboard("value", 60)
+	hold := app.clipboardHold
+	hold.timer.Stop()
+
+	cb.err = errors.New("no display")
+	if _, err := clearIfHeld(cb, hold); err == nil {
+		t.Error("clearIfHeld() should report clipboard errors")
+	}
+	cb.err = nil
+	if cleared, err := clearIfHeld(cb, hold); !cleared || err != nil {
+		t.Errorf("clearIfHeld() = %v, %v, want the value cleared", cleared, err)
+	}
+	if cleared, _ := clearIfHeld(cb, hold); cleared {
+		t.Error("clearIfHeld() cleared a clipboard that no longer holds the value")
+	}
+}
+
+func TestAppBeforeCloseClearsClipboard(t *testing.T) {
+	app := NewApp()
+	cb := &fakeClipboard{}
+	app.clipboard = cb
+	if app.beforeClose(context.Background()) {
+		t.Error("beforeClose() with nothing copied prevented the window from closing")
+	}
+
+	app.CopyToClipboard("01:hex:abcd", 60)
+	if app.beforeClose(context.Background()) {
+		t.Error("beforeClose() prevented the window from closing")
+	}
+	if cb.text != "" || app.clipboardHold != nil {
+		t.Errorf("clipboard after closing = %q, want it cleared", cb.text)
+	}
+
+	// A value the user copied afterwards is kept
+	app.CopyToClipboard("01:hex:abcd", 60)
+	cb.SetText("copied by the user")
+	app.beforeClose(context.Background())
+	if cb.text != "copied by the user" {
+		t.Errorf("clipboard after closing = %q, the user's value should be kept", cb.text)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"strings"
+	"unicode/utf8"
+)
+
+// result is the JSON envelope returned to JavaScript. It has the shape of the
+// desktop app's Response, so pages can handle both the same way.
+type result struct {
+	Error *string     `json:"error"`
+	Index *int        `json:"index,omitempty"` // Index of the shard the error refers to
+	Data  interface{} `json:"data"`
+}
+
+// recovered is the data returned by combine.
+type recovered struct {
+	Data string  `json:"data"`           // Base64-encoded secret bytes
+	Text *string `json:"text,omitempty"` // The secret as text, if it is valid UTF-8
+	Size int     `json:"size"`
+}
+
+// verified is the data returned by verify.
+type verified struct {
+	Valid bool  `json:"valid"`
+	Size  int   `json:"size"`
+	Match *bool `json:"match,omitempty"` // Whether the secret equals the expected one
+}
+
+// encodeResult marshals data or err into the result envelope.
+func encodeResult(data interface{}, err error) string {
+	var r result
+	if err != nil {
+		msg := err.Error()
+		r.Error = &msg
+		var spe *shamir.ShareParseError
+		if errors.As(err, &spe) {
+			index := spe.Index
+			r.Index = &index
+		}
+	} else {
+		r.Data = data
+	}
+
+	out, jsonErr := json.Marshal(r)
+	if jsonErr != nil {
+		return jsonErr.Error()
+	}
+	return string(out)
+}
+
+// split splits a text secret and returns the shards as an array. The
+// secret is a JavaScript string copied into Go, and neither copy can be
+// wiped.
+func split(secret string, shards, shardsNeeded int, output string) string {
+	out, err := shamir.Split([]byte(secret), shards, shardsNeeded, output)
+	if err != nil {
+		return encodeResult(nil, err)
+	}
+	return encodeResult(strings.Split(strings.TrimSpace(out), "\n"), nil)
+}
+
+// shardSignatureSep separates a shard from the dealer signature the desktop
+// app appends to it, as in "01:hex:...#<signature>".
+const shardSignatureSep = "#"
+
+// stripSignatures returns the shards without their dealer signatures. The
+// page has no pinned dealer key, so signatures are not checked.
+func stripSignatures(shards []string) []string {
+	stripped := make([]string, len(shards))
+	for i, s := range shards {
+		share, _, _ := strings.Cut(s, shardSignatureSep)
+		stripped[i] = strings.TrimSpace(share)
+	}
+	return stripped
+}
+
+// combine reconstructs the secret, as base64 and as text when possible.
+func combine(shards []string) string {
+	out, err := shamir.Recompose(stripSignatures(shards))
+	if err != nil {
+		return encodeResult(nil, err)
+	}
+	defer securemem.Wipe(out)
+	r := recovered{Data: base64.StdEncoding.EncodeToString(out), Size: len(out)}
+	if utf8.Valid(out) {
+		text := string(out)
+		r.Text = &text
+	}
+	return encodeResult(r, nil)
+}
+
+// verify checks that shards combine, and that they combine to expected if it
+// is not nil, without returning the secret.
+func verify(shards []string, expected *string) string {
+	out, err := shamir.Recompose(stripSignatures(shards))
+	if err != nil {
+		return encodeResult(nil, err)
+	}
+	defer securemem.Wipe(out)
+	v := verified{Valid: true, Size: len(out)}
+	if expected != nil {
+		match := subtle.ConstantTimeCompare(out, []byte(*expected)) == 1
+		v.Match = &match
+	}
+	return encodeResult(v, nil)
+}
+
+// encodings lists the share encodings split accepts.
+func encodings() string {
+	return encodeResult(shamir.Encodings(), nil)
+}
+
+// errArgs reports a call with missing arguments.
+func errArgs(usage string) error {
+	return fmt.Errorf("missing arguments, usage: orcrux.%s", usage)
+}
+
+// splitLines splits pasted shards on newlines, dropping blank lines.
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
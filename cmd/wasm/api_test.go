@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"orcrux/shamir"
+)
+
+// decodeResult parses a result envelope, failing the test on invalid JSON.
+func decodeResult(t *testing.T, out string) result {
+	t.Helper()
+	var r result
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("invalid result %q: %v", out, err)
+	}
+	return r
+}
+
+func TestSplitCombine(t *testing.T) {
+	r := decodeResult(t, split("hunter2", 3, 2, "hex"))
+	if r.Error != nil {
+		t.Fatalf("split() error = %s", *r.Error)
+	}
+	var shards []string
+	for _, s := range r.Data.([]interface{}) {
+		shards = append(shards, s.(string))
+	}
+	if len(shards) != 3 {
+		t.Fatalf("split() returned %d shards, want 3", len(shards))
+	}
+
+	r = decodeResult(t, combine(shards[1:]))
+	data := r.Data.(map[string]interface{})
+	if data["text"] != "hunter2" || data["size"] != float64(7) {
+		t.Errorf("combine() = %v", data)
+	}
+
+	expected := "hunter2"
+	r = decodeResult(t, verify(shards[:2], &expected))
+	if v := r.Data.(map[string]interface{}); v["valid"] != true || v["match"] != true {
+		t.Errorf("verify() = %v", v)
+	}
+}
+
+func TestCombineErrorIndex(t *testing.T) {
+	r := decodeResult(t, combine([]string{"01:hex:616263", "garbage"}))
+	if r.Error == nil || r.Index == nil || *r.Index != 1 {
+		t.Errorf("combine() error = %v, index = %v, want an error at index 1", r.Error, r.Index)
+	}
+}
+
+func TestCombineBinary(t *testing.T) {
+	out, _ := shamir.Split([]byte{0xff, 0xfe, 0x00}, 2, 2, "hex")
+	r := decodeResult(t, combine(splitLines(out)))
+	data := r.Data.(map[string]interface{})
+	if _, ok := data["text"]; ok || data["data"] != "//4A" {
+		t.Errorf("combine() of binary data = %v, want base64 only", data)
+	}
+}
+
+func TestCombineSignedShards(t *testing.T) {
+	// Shards signed by the desktop app end with "#" and a base64url signature
+	sig := base64.RawURLEncoding.EncodeToString(make([]byte, 64))
+	out, _ := shamir.Split([]byte("hunter2"), 3, 2, "hex")
+	var signed []string
+	for _, s := range splitLines(out) {
+		signed = append(signed, s+shardSignatureSep+sig)
+	}
+
+	r := decodeResult(t, combine(signed[:2]))
+	if r.Error != nil {
+		t.Fatalf("combine() of signed shards error = %s", *r.Error)
+	}
+	if data := r.Data.(map[string]interface{}); data["text"] != "hunter2" {
+		t.Errorf("combine() of signed shards = %v", data)
+	}
+	expected := "hunter2"
+	r = decodeResult(t, verify(signed[1:], &expected))
+	if v, ok := r.Data.(map[string]interface{}); !ok || v["match"] != true {
+		t.Errorf("verify() of signed shards = %v, error %v", r.Data, r.Error)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	got := splitLines(" 01:hex:61 \r\n\n02:hex:62\n")
+	if len(got) != 2 || got[0] != "01:hex:61" || got[1] != "02:hex:62" {
+		t.Errorf("splitLines() = %q", got)
+	}
+}
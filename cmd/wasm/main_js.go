@@ -0,0 +1,70 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+// main exposes the shamir core to JavaScript as a global "orcrux" object:
+//
+//	orcrux.split(secret, shards, shardsNeeded, output)
+//	orcrux.combine(shards)
+//	orcrux.verify(shards, expectedSecret?)
+//	orcrux.encodings()
+//
+// Every function returns a JSON string in the result envelope.
+func main() {
+	js.Global().Set("orcrux", js.ValueOf(map[string]interface{}{
+		"split":     js.FuncOf(jsSplit),
+		"combine":   js.FuncOf(jsCombine),
+		"verify":    js.FuncOf(jsVerify),
+		"encodings": js.FuncOf(func(js.Value, []js.Value) interface{} { return encodings() }),
+	}))
+
+	// Keep the functions alive for the lifetime of the page
+	select {}
+}
+
+func jsSplit(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 3 {
+		return encodeResult(nil, errArgs("split(secret, shards, shardsNeeded, output)"))
+	}
+	output := "base64"
+	if len(args) > 3 && args[3].Type() == js.TypeString {
+		output = args[3].String()
+	}
+	return split(args[0].String(), args[1].Int(), args[2].Int(), output)
+}
+
+func jsCombine(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return encodeResult(nil, errArgs("combine(shards)"))
+	}
+	return combine(stringArray(args[0]))
+}
+
+func jsVerify(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return encodeResult(nil, errArgs("verify(shards, expectedSecret)"))
+	}
+	var expected *string
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		s := args[1].String()
+		expected = &s
+	}
+	return verify(stringArray(args[0]), expected)
+}
+
+// stringArray converts a JavaScript array to strings, or a single string to
+// its lines.
+func stringArray(v js.Value) []string {
+	if v.Type() == js.TypeString {
+		return splitLines(v.String())
+	}
+	out := make([]string, v.Length())
+	for i := range out {
+		out[i] = v.Index(i).String()
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// page is the static HTML page. bundle inlines wasm_exec.js and the WASM
+// module into it, so the result works from file:// without any request.
+//
+//go:embed index.html
+var page string
+
+// Placeholders replaced by bundle.
+const (
+	wasmExecPlaceholder = "/*{{WASM_EXEC}}*/"
+	wasmDataPlaceholder = "{{WASM_BASE64}}"
+)
+
+// main builds the WebAssembly module and writes the self-contained page:
+//
+//	go run ./cmd/wasm -o orcrux.html
+func main() {
+	out := flag.String("o", "orcrux.html", "path of the HTML page to write")
+	flag.Parse()
+
+	if err := build(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "wrote", *out)
+}
+
+// build compiles this package for js/wasm and writes the bundled page to out.
+func build(out string) error {
+	dir, err := os.MkdirTemp("", "orcrux-wasm")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	wasmPath := filepath.Join(dir, "orcrux.wasm")
+	if err := buildWASM(wasmPath); err != nil {
+		return err
+	}
+	wasm, err := os.ReadFile(wasmPath)
+	if err != nil {
+		return err
+	}
+
+	execJS, err := readWASMExec()
+	if err != nil {
+		return err
+	}
+
+	html, err := bundle(page, execJS, wasm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, []byte(html), 0644)
+}
+
+// buildWASM compiles orcrux/cmd/wasm to a WebAssembly module at out.
+func buildWASM(out string) error {
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags=-s -w", "-o", out, "orcrux/cmd/wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building the WASM module: %w", err)
+	}
+	return nil
+}
+
+// readWASMExec returns the wasm_exec.js support script of the Go toolchain
+// that compiled the module, which must match it.
+func readWASMExec() ([]byte, error) {
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return nil, fmt.Errorf("locating GOROOT: %w", err)
+	}
+	root := strings.TrimSpace(string(goroot))
+
+	// Go 1.24 moved the script from misc/wasm to lib/wasm
+	for _, dir := range []string{"lib", "misc"} {
+		data, err := os.ReadFile(filepath.Join(root, dir, "wasm", "wasm_exec.js"))
+		if err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("wasm_exec.js not found in %s", root)
+}
+
+// bundle inlines the support script and the base64-encoded module into the
+// page template.
+func bundle(template string, execJS, wasm []byte) (string, error) {
+	if !strings.Contains(template, wasmExecPlaceholder) || !strings.Contains(template, wasmDataPlaceholder) {
+		return "", errors.New("page template is missing a placeholder")
+	}
+	// A closing tag inside the script would end it early
+	if strings.Contains(strings.ToLower(string(execJS)), "</script") {
+		return "", errors.New("wasm_exec.js cannot be inlined")
+	}
+	html := strings.Replace(template, wasmExecPlaceholder, string(execJS), 1)
+	return strings.Replace(html, wasmDataPlaceholder, base64.StdEncoding.EncodeToString(wasm), 1), nil
+}
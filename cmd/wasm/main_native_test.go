@@ -0,0 +1,170 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"orcrux/shamir"
+)
+
+func TestBundle(t *testing.T) {
+	wasm := []byte{0x00, 0x61, 0x73, 0x6d}
+	html, err := bundle(page, []byte("class Go {}"), wasm)
+	if err != nil {
+		t.Fatalf("bundle() error = %v", err)
+	}
+	if strings.Contains(html, wasmExecPlaceholder) || strings.Contains(html, wasmDataPlaceholder) {
+		t.Error("bundle() left a placeholder in the page")
+	}
+	if !strings.Contains(html, "class Go {}") || !strings.Contains(html, base64.StdEncoding.EncodeToString(wasm)) {
+		t.Error("bundle() did not inline the script and module")
+	}
+}
+
+func TestBundleRejects(t *testing.T) {
+	if _, err := bundle("<html></html>", nil, nil); err == nil {
+		t.Error("bundle() should reject a template without placeholders")
+	}
+	if _, err := bundle(page, []byte("x = '</script>'"), nil); err == nil {
+		t.Error("bundle() should reject a script closing its own tag")
+	}
+}
+
+func TestPageIsOffline(t *testing.T) {
+	// The page must not load anything: no src or href attributes, and a CSP
+	// forbidding connections
+	for _, attr := range []string{"src=", "href="} {
+		if strings.Contains(page, attr) {
+			t.Errorf("page contains %q", attr)
+		}
+	}
+	if !strings.Contains(page, "default-src 'none'") {
+		t.Error("page has no restrictive Content-Security-Policy")
+	}
+}
+
+// nodeHarness loads the WASM module in Node.js and prints the results of the
+// calls read from stdin, each a [function, ...arguments] array.
+const nodeHarness = `
+globalThis.fs = require("fs");
+require(process.argv[2]);
+const go = new Go();
+WebAssembly.instantiate(fs.readFileSync(process.argv[3]), go.importObject).then(({ instance }) => {
+  go.run(instance);
+  const calls = JSON.parse(fs.readFileSync(0, "utf8"));
+  process.stdout.write(JSON.stringify(calls.map(([fn, ...args]) => orcrux[fn](...args))));
+  process.exit(0);
+});
+`
+
+// TestWASMMatchesNative builds the WASM module, runs it in Node.js and checks
+// that it gives the same results as the native build. Shares are randomized,
+// so the shards split by the WASM module are recomposed natively instead of
+// being compared.
+func TestWASMMatchesNative(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping WASM build in short mode")
+	}
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found, skipping the WASM cross-check")
+	}
+	execJS, err := readWASMExec()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	dir := t.TempDir()
+	wasmPath := filepath.Join(dir, "orcrux.wasm")
+	if err := buildWASM(wasmPath); err != nil {
+		t.Fatalf("buildWASM() error = %v", err)
+	}
+	execPath := filepath.Join(dir, "wasm_exec.js")
+	harnessPath := filepath.Join(dir, "harness.js")
+	if err := os.WriteFile(execPath, execJS, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(harnessPath, []byte(nodeHarness), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	nativeShards := splitLines(mustSplit(t, "binary \x00 and ünïcode", 5, 3, "bech32"))
+	signed := []string{nativeShards[1] + "#" + strings.Repeat("A", 86), nativeShards[3] + "#" + strings.Repeat("A", 86), nativeShards[4]}
+	expected := "hunter2"
+	calls := []interface{}{
+		[]interface{}{"split", "hunter2", 5, 3, "hex"},
+		[]interface{}{"split", "a longer secret with padding", 3, 2, "base64+deflate+pad64"},
+		[]interface{}{"split", "x", 1, 2, "hex"},
+		[]interface{}{"combine", nativeShards[2:]},
+		[]interface{}{"combine", strings.Join(nativeShards[:3], "\n")},
+		[]interface{}{"combine", []string{nativeShards[0], "garbage"}},
+		[]interface{}{"verify", splitLines(mustSplit(t, "hunter2", 3, 2, "grouped")), expected},
+		[]interface{}{"combine", signed},
+		[]interface{}{"encodings"},
+	}
+	// The first two calls are checked by recomposing their shards
+	randomized := map[int]string{0: "hunter2", 1: "a longer secret with padding"}
+	want := []string{
+		"",
+		"",
+		split("x", 1, 2, "hex"),
+		combine(nativeShards[2:]),
+		combine(nativeShards[:3]),
+		combine([]string{nativeShards[0], "garbage"}),
+		verify(splitLines(mustSplit(t, "hunter2", 3, 2, "grouped")), &expected),
+		combine(signed),
+		encodings(),
+	}
+
+	input, _ := json.Marshal(calls)
+	cmd := exec.Command(node, harnessPath, execPath, wasmPath)
+	cmd.Stdin = strings.NewReader(string(input))
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("node error = %v", err)
+	}
+
+	var got []string
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("invalid node output %q: %v", out, err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("node returned %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if secret, ok := randomized[i]; ok {
+			var r struct {
+				Error *string  `json:"error"`
+				Data  []string `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(got[i]), &r); err != nil || r.Error != nil {
+				t.Errorf("call %v: wasm = %s", calls[i], got[i])
+				continue
+			}
+			if out, err := shamir.Recompose(r.Data); err != nil || string(out) != secret {
+				t.Errorf("call %v: wasm shards recompose to %q, %v", calls[i], out, err)
+			}
+			continue
+		}
+		if got[i] != want[i] {
+			t.Errorf("call %v:\nwasm   = %s\nnative = %s", calls[i], got[i], want[i])
+		}
+	}
+}
+
+func mustSplit(t *testing.T, secret string, n, threshold int, output string) string {
+	t.Helper()
+	out, err := shamir.Split([]byte(secret), n, threshold, output)
+	if err != nil {
+		t.Fatalf("shamir.Split() error = %v", err)
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxDroppedFileSize is the largest file ImportDroppedFiles reads. Shard
+// files, QR images and armored shards are far smaller.
+const maxDroppedFileSize = 16 << 20
+
+// errShardFile is reported for a dropped shard file of a split file, which
+// is combined with the other shard files rather than recomposed as text.
+var errShardFile = errors.New("this is a shard of a split file, combine it with Restore file instead")
+
+// errPGPEncrypted is reported for a dropped PGP message: decrypting it needs
+// the custodian's private key, which is selected with ImportShardPGP.
+var errPGPEncrypted = errors.New("shard is encrypted with PGP, import it with your private key instead")
+
+// DroppedFile is the outcome of importing one dropped file.
+type DroppedFile struct {
+	Name   string   `json:"name"`
+	Shards []string `json:"shards"`
+	Error  string   `json:"error,omitempty"`
+	Code   string   `json:"code,omitempty"` // Machine-readable error code
+}
+
+// DropResult is the outcome of importing a set of dropped files.
+type DropResult struct {
+	Files  []DroppedFile `json:"files"`
+	Shards []string      `json:"shards"` // Shards of every file, in drop order
+	Sealed bool          `json:"sealed"` // A sealed file is waiting for UnlockShard
+}
+
+// droppedQR is a part of a shard split across several QR codes.
+type droppedQR struct {
+	file    int // Index of the file in the drop
+	payload string
+}
+
+// importDroppedFiles reads each file with the importer matching its
+// contents. Parts of a shard split across QR codes are joined across files.
+// The contents of the last sealed file are returned for UnlockShard.
+func importDroppedFiles(paths []string) (DropResult, []byte) {
+	result := DropResult{Files: make([]DroppedFile, len(paths)), Shards: []string{}}
+	var sealed []byte
+	qrParts := make(map[string][]droppedQR) // Sequenced QR payloads by shard tag
+	var qrTags []string
+
+	for i, path := range paths {
+		f := &result.Files[i]
+		f.Name, f.Shards = filepath.Base(path), []string{}
+		data, err := readDroppedFile(path)
+		if err != nil {
+			f.fail(err)
+			continue
+		}
+
+		switch {
+		case isSealed(data):
+			if sealed != nil {
+				f.fail(errors.New("drop sealed files one at a time"))
+				securemem.Wipe(data)
+				continue
+			}
+			sealed = data
+			f.fail(errSealed)
+			continue
+		case strings.HasPrefix(http.DetectContentType(data), "image/"):
+			payload, err := decodeQRImage(data)
+			if err != nil {
+				f.fail(err)
+				break
+			}
+			part, ok, err := parseQRPayload(strings.TrimSpace(payload))
+			switch {
+			case err != nil:
+				f.fail(err)
+			case ok:
+				if _, seen := qrParts[part.tag]; !seen {
+					qrTags = append(qrTags, part.tag)
+				}
+				qrParts[part.tag] = append(qrParts[part.tag], droppedQR{file: i, payload: payload})
+			default:
+				f.Shards = append(f.Shards, strings.TrimSpace(payload))
+			}
+		case isShardFile(data):
+			f.fail(errShardFile)
+		case isAgeEncrypted(string(data)):
+			// Decrypted with the custodian's identity when recomposing
+			f.Shards = append(f.Shards, normalizeArmor(string(data)))
+		case isPGPEncrypted(data):
+			f.fail(errPGPEncrypted)
+		default:
+			shards, err := parseShardText(string(data))
+			if err != nil {
+				f.fail(err)
+				break
+			}
+			f.Shards = shards
+		}
+		securemem.Wipe(data)
+	}
+
+	// Join QR sequences, crediting the shard to the file of its first part
+	for _, tag := range qrTags {
+		parts := qrParts[tag]
+		payloads := make([]string, len(parts))
+		for i, p := range parts {
+			payloads[i] = p.payload
+		}
+		shard, err := joinQRPayloads(payloads)
+		if err != nil {
+			for _, p := range parts {
+				result.Files[p.file].fail(err)
+			}
+			continue
+		}
+		first := &result.Files[parts[0].file]
+		first.Shards = append(first.Shards, shard)
+	}
+
+	for _, f := range result.Files {
+		result.Shards = append(result.Shards, f.Shards...)
+	}
+	result.Sealed = sealed != nil
+	return result, sealed
+}
+
+// fail records err as the outcome of the file.
+func (f *DroppedFile) fail(err error) {
+	var r Response
+	r.describeError(err)
+	f.Error, f.Code, f.Shards = err.Error(), r.Code, []string{}
+}
+
+// readDroppedFile reads a dropped file, refusing directories and files larger
+// than maxDroppedFileSize.
+func readDroppedFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, errors.New("folders cannot be imported as shards")
+	}
+	if info.Size() > maxDroppedFileSize {
+		return nil, fmt.Errorf("file is larger than %d MiB", maxDroppedFileSize>>20)
+	}
+	return os.ReadFile(path)
+}
+
+// parseShardText returns the shards of a text file holding one shard per
+// line, as written by SaveFileDialog. Every line must parse as a shard;
+// untagged shards of the original format are accepted.
+func parseShardText(text string) ([]string, error) {
+	var shards []string
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		share, _, err := splitShardSignature(line)
+		if err == nil {
+			_, err = shamir.ParseLegacyShare(share)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		shards = append(shards, line)
+	}
+	if len(shards) == 0 {
+		return nil, shamir.ErrNoShards
+	}
+	return shards, nil
+}
+
+// ImportDroppedFiles imports shard files dropped onto the window.
+//
+// Each file is read with the importer matching its contents: text files with
+// one shard per line, QR code images, including shards split across several
+// codes, and armored age files. A sealed file is kept pending, as with
+// UploadFile, until UnlockShard is called. PGP messages need the custodian's
+// private key and are reported as errors pointing to ImportShardPGP, and
+// shard files of a split file as errors pointing to CombineToFile.
+//
+// Parameters:
+//   - paths: Absolute paths of the dropped files, as given by the runtime
+//
+// Returns:
+//   - A JSON response with the DropResult: the shards and any error of each
+//     file, and every shard in drop order
+func (a *App) ImportDroppedFiles(paths []string) string {
+	result, sealed := importDroppedFiles(paths)
+	if sealed != nil {
+		a.mu.Lock()
+		a.pendingSealed = sealed
+		a.mu.Unlock()
+	}
+	return newResponse(result, nil)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+// writeDropFile writes a file to drop into dir and returns its path.
+func writeDropFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportDroppedFiles(t *testing.T) {
+	dir := t.TempDir()
+	shards := splitShards(t, "dropped secret", 4, 2, "base64")
+
+	// A shard cut into two QR codes, with small chunks so they decode quickly
+	long := splitShards(t, strings.Repeat("long secret ", 20), 2, 2, "base64")[0]
+	payloads := qrPayloads(long, len(long)/2+1)
+	if len(payloads) != 2 {
+		t.Fatalf("qrPayloads() = %d payloads, want 2", len(payloads))
+	}
+	var qrPaths []string
+	for i, payload := range payloads {
+		img, err := renderQRPNG(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qrPaths = append(qrPaths, writeDropFile(t, dir, qrFileName("long.png", i+1, 2, ".png"), img))
+	}
+	single, err := renderQRPNG(shards[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := encryptShard(shards[3], identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		writeDropFile(t, dir, "shards.txt", []byte(shards[0]+"\r\n\r\n"+shards[1]+"\n")),
+		qrPaths[1],
+		writeDropFile(t, dir, "single.png", single),
+		writeDropFile(t, dir, "custodian.age", []byte(encrypted)),
+		qrPaths[0],
+		writeDropFile(t, dir, "notes.txt", []byte(shards[0]+"\nnot a shard\n")),
+		writeDropFile(t, dir, "message.asc", []byte("-----BEGIN PGP MESSAGE-----\n\nwcBMA\n-----END PGP MESSAGE-----\n")),
+		writeDropFile(t, dir, "legacy.txt", []byte("01:9ab59f9a\n03:5d0e5c5d\n")),
+		writeDropFile(t, dir, "report.pdf.1.orcrux", []byte(shardFileMagic+"\nx: 01\n")),
+		filepath.Join(dir, "missing.txt"),
+		dir,
+	}
+	result, sealed := importDroppedFiles(paths)
+	if sealed != nil || result.Sealed {
+		t.Error("importDroppedFiles() reported a sealed file")
+	}
+
+	want := []struct {
+		shards int
+		code   string
+	}{
+		{2, ""},
+		{1, ""}, // The QR sequence is credited to its first file in the drop
+		{1, ""},
+		{1, ""},
+		{0, ""},
+		{0, "invalid_share"},
+		{0, "pgp_encrypted"},
+		{2, ""},
+		{0, "shard_file"},
+		{0, ""},
+		{0, ""},
+	}
+	for i, w := range want {
+		f := result.Files[i]
+		if len(f.Shards) != w.shards || f.Code != w.code {
+			t.Errorf("file %d (%s) = %d shards, code %q, error %q; want %d shards, code %q", i, f.Name, len(f.Shards), f.Code, f.Error, w.shards, w.code)
+		}
+	}
+	if result.Files[4].Error != "" {
+		t.Errorf("second QR part error = %q", result.Files[4].Error)
+	}
+	for _, i := range []int{5, 9, 10} {
+		if result.Files[i].Error == "" {
+			t.Errorf("file %d (%s) should report an error", i, result.Files[i].Name)
+		}
+	}
+	if !strings.Contains(result.Files[5].Error, "line 2") {
+		t.Errorf("invalid text file error = %q, want the line number", result.Files[5].Error)
+	}
+
+	if !strings.Contains(result.Files[8].Error, "Restore file") {
+		t.Errorf("shard file error = %q, want it to point to Restore file", result.Files[8].Error)
+	}
+
+	got := result.Shards
+	if len(got) != 7 || got[0] != shards[0] || got[1] != shards[1] || got[2] != long || got[3] != shards[2] {
+		t.Fatalf("Shards = %q", got)
+	}
+	decrypted, err := decryptShard(got[4], strings.NewReader(identity.String()))
+	if err != nil || decrypted != shards[3] {
+		t.Errorf("dropped age shard decrypts to %q, %v", decrypted, err)
+	}
+}
+
+func TestImportDroppedFilesQRIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	long := splitShards(t, strings.Repeat("long secret ", 20), 2, 2, "base64")[0]
+	img, err := renderQRPNG(qrPayloads(long, len(long)/2+1)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := importDroppedFiles([]string{writeDropFile(t, dir, "part1.png", img)})
+	if f := result.Files[0]; f.Error == "" || len(result.Shards) != 0 {
+		t.Errorf("a lone part of a QR sequence = %+v, want an error", f)
+	}
+}
+
+func TestAppImportDroppedSealedFile(t *testing.T) {
+	dir := t.TempDir()
+	shards := splitShards(t, "sealed secret", 2, 2, "hex")
+	sealed, err := sealShard([]byte(strings.Join(shards, "\n")), "pass", testSealParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := sealShard([]byte(shards[0]), "pass", testSealParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	var response struct {
+		Data DropResult `json:"data"`
+	}
+	json.Unmarshal([]byte(app.ImportDroppedFiles([]string{
+		writeDropFile(t, dir, "shards.sealed", sealed),
+		writeDropFile(t, dir, "other.sealed", other),
+	})), &response)
+	files := response.Data.Files
+	if !response.Data.Sealed || files[0].Code != "passphrase_required" || files[1].Error == "" {
+		t.Fatalf("ImportDroppedFiles() = %+v", response.Data)
+	}
+
+	plain, err := app.UnlockShard("pass")
+	if err != nil {
+		t.Fatalf("UnlockShard() error = %v", err)
+	}
+	if plain != strings.Join(shards, "\n") {
+		t.Errorf("UnlockShard() = %q, want the first sealed file", plain)
+	}
+}
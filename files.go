@@ -1,8 +1,13 @@
 package main
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"orcrux/securemem"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -14,13 +19,17 @@ import (
 // returned as a string. If no file is selected or an error occurs during file
 // operations, appropriate error values are returned.
 //
+// If the selected file is sealed with a passphrase, it is kept pending and a
+// "passphrase required" error is returned; the frontend should then ask for
+// the passphrase and call UnlockShard.
+//
 // Returns:
 //   - A string containing the file contents if successful
 //   - An empty string if no file was selected (user cancelled the dialog)
 //   - An error if the file dialog fails to open or if file reading operations fail
 //
 // File filters:
-//   - Only text files (*.txt) are shown in the file picker
+//   - Text files (*.txt) and sealed shard files (*.sealed) are shown in the file picker
 //
 // Example usage:
 //
@@ -36,7 +45,7 @@ func (a *App) UploadFile() (string, error) {
 	fd := runtime.OpenDialogOptions{
 		Title: "Select a file",
 		Filters: []runtime.FileFilter{
-			{DisplayName: "Text files", Pattern: "*.txt"},
+			{DisplayName: "Shard files", Pattern: "*.txt;*" + sealedExt},
 		},
 	}
 	path, err := runtime.OpenFileDialog(a.ctx, fd)
@@ -50,6 +59,12 @@ func (a *App) UploadFile() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if isSealed(content) {
+		a.mu.Lock()
+		a.pendingSealed = content
+		a.mu.Unlock()
+		return "", errSealed
+	}
 	return string(content), nil
 }
 
@@ -64,11 +79,15 @@ func (a *App) UploadFile() (string, error) {
 //   - content: The byte array to write to the file (cannot be empty)
 //   - defaultName: The default filename to suggest in the save dialog
 //
+// Content sealed with SealShards is detected and saved as a sealed shard file
+// readable by the current user only.
+//
 // Returns:
 //   - An error if content is empty, no path was selected, or if file I/O operations fail
 //
 // File filters:
-//   - Only text files (*.txt) are shown in the file picker
+//   - Only text files (*.txt) are shown in the file picker, or sealed shard
+//     files (*.sealed) when the content is sealed
 //
 // Example usage:
 //
@@ -88,6 +107,13 @@ func (a *App) SaveFileDialog(content []byte, defaultName string) error {
 			{DisplayName: "Text files", Pattern: "*.txt"},
 		},
 	}
+	perm := os.FileMode(0644)
+	if isSealed(content) {
+		fd.Title = "Save sealed shards file"
+		fd.DefaultFilename = strings.TrimSuffix(defaultName, filepath.Ext(defaultName)) + sealedExt
+		fd.Filters = []runtime.FileFilter{{DisplayName: "Sealed shard files", Pattern: "*" + sealedExt}}
+		perm = 0600
+	}
 
 	path, err := runtime.SaveFileDialog(a.ctx, fd)
 	if err != nil {
@@ -97,10 +123,51 @@ func (a *App) SaveFileDialog(content []byte, defaultName string) error {
 		return nil // User cancelled the dialog
 	}
 
-	err = os.WriteFile(path, content, 0644)
+	err = os.WriteFile(path, content, perm)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// SaveRecoveredSecret opens a file dialog and writes a recovered secret to disk byte for byte.
+//
+// The secret is passed as base64, exactly as returned by RecomposeBytes, so
+// binary content is written without any text conversion. The file is created
+// readable by the current user only.
+//
+// Parameters:
+//   - dataBase64: The recovered secret, encoded as standard base64 (cannot be empty)
+//   - defaultName: The default filename to suggest in the save dialog
+//
+// Returns:
+//   - An error if the data is empty or not valid base64, or if file I/O operations fail
+//
+// File filters:
+//   - None, any file type can be written
+func (a *App) SaveRecoveredSecret(dataBase64 string, defaultName string) error {
+	content, err := base64.StdEncoding.DecodeString(dataBase64)
+	if err != nil {
+		return fmt.Errorf("secret is not valid base64: %w", err)
+	}
+	defer securemem.Wipe(content)
+	if len(content) == 0 {
+		return errors.New("content is required")
+	}
+
+	fd := runtime.SaveDialogOptions{
+		Title:           "Save recovered secret",
+		DefaultFilename: defaultName,
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, fd)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // User cancelled the dialog
+	}
+
+	return os.WriteFile(path, content, 0600)
+}
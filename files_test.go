@@ -128,6 +128,27 @@ func TestApp_SaveFileDialog_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestApp_SaveRecoveredSecret_Validation(t *testing.T) {
+	app := &App{}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty data", data: ""},
+		{name: "invalid base64", data: "%%%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Validation happens before any runtime call, so no context is needed
+			if err := app.SaveRecoveredSecret(tt.data, "secret.bin"); err == nil {
+				t.Error("SaveRecoveredSecret() should have returned an error")
+			}
+		})
+	}
+}
+
 func TestApp_UploadFile_Validation(t *testing.T) {
 	app := &App{}
 
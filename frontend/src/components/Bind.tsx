@@ -1,45 +1,203 @@
-import { useState } from "react";
-import { Recompose as RecomposeFn, UploadFile as UploadFileFn } from "../../wailsjs/go/main/App";
+import { CSSProperties, useEffect, useState } from "react";
+import { RecomposeBytes as RecomposeBytesFn, SaveRecoveredSecret as SaveRecoveredSecretFn, UploadFile as UploadFileFn, ImportShardQR as ImportShardQRFn, CombineToFile as CombineToFileFn, DecryptShard as DecryptShardFn, UnlockShard as UnlockShardFn, ImportShardPGP as ImportShardPGPFn, RecomposeMnemonic as RecomposeMnemonicFn, OpenManifest as OpenManifestFn, CheckManifest as CheckManifestFn, ImportDroppedFiles as ImportDroppedFilesFn } from "../../wailsjs/go/main/App";
+import { OnFileDrop, OnFileDropOff } from "../../wailsjs/runtime/runtime";
 import { motion } from "framer-motion";
 
 import { Button } from "./ui/button";
 import { Textarea } from "./ui/textarea";
 import { Label } from "./ui/label";
-import { RecomposeResult } from "../types/core";
+import { DropImportResult, DroppedFile, FileOperationResult, Manifest, ManifestCheck, ManifestCheckResult, ManifestResult, RecomposeBytesResult, RecomposeResult, ShardCheck } from "../types/core";
 import { bindVariants } from "../lib/motions";
 import { Input } from "./ui/input";
 import BindManualController from "./BindManualController";
+import DealerKey from "./DealerKey";
+import ReconstructionSession from "./ReconstructionSession";
+import ClipboardStatus from "./ClipboardStatus";
+import { copyToClipboard } from "../lib/clipboard";
 import { bindActiveColors, bindIdleColors } from "@/lib/colors";
 
 export default function Bind() {
   const [shards, setShards] = useState(["", ""])
   const [result, setResult] = useState<RecomposeResult>({ error: null, data: null })
+  const [recovered, setRecovered] = useState<RecomposeBytesResult["data"]>(null)
+  const [errorIndex, setErrorIndex] = useState<number | null>(null)
+  const [locked, setLocked] = useState<"sealed" | "pgp" | null>(null)
+  const [passphrase, setPassphrase] = useState("")
+  const [manifest, setManifest] = useState<Manifest | null>(null)
+  const [manifestCheck, setManifestCheck] = useState<ManifestCheck | null>(null)
+  const [oneAtATime, setOneAtATime] = useState(false)
+  const [dropErrors, setDropErrors] = useState<DroppedFile[]>([])
+
+  // Files dropped on the shard area are imported and added to the inputs
+  useEffect(() => {
+    OnFileDrop(async (_x, _y, paths) => {
+      const parsed = JSON.parse(await ImportDroppedFilesFn(paths)) as DropImportResult
+      if (parsed.error || !parsed.data) {
+        setResult({ error: parsed.error, data: null })
+        return
+      }
+      setDropErrors(parsed.data.files.filter(f => f.error && f.code !== "passphrase_required"))
+      if (parsed.data.shards.length > 0) addShards(parsed.data.shards)
+      if (parsed.data.sealed) setLocked("sealed")
+    }, true)
+    return () => OnFileDropOff()
+  }, [])
 
   const onReset = () => {
     setResult({ error: null, data: null })
+    setRecovered(null)
+    setErrorIndex(null)
+    setManifestCheck(null)
     setShards(["", ""])
     window.parent.postMessage({ type: 'color-change', color1: bindIdleColors[0], color2: bindIdleColors[1] }, '*')
   }
 
   const onRecompose = async () => {
     setResult({ error: null, data: null })
+    setRecovered(null)
+    setErrorIndex(null)
     if (shards.length < 2 || shards.some(shard => shard === "")) {
       return
     }
-    const result = await RecomposeFn(shards)
-    const parsedResult = JSON.parse(result) as RecomposeResult
-    setResult(parsedResult)
+    // Encrypted shards are decrypted with the custodian's private key first
+    const decrypted = [...shards]
+    for (const [i, shard] of shards.entries()) {
+      if (!shard.trim().startsWith("-----BEGIN AGE ENCRYPTED FILE-----")) continue
+      const parsed = JSON.parse(await DecryptShardFn(shard)) as RecomposeResult
+      if (parsed.error) {
+        setResult({ error: `Shard ${i + 1}: ${parsed.error}`, data: null })
+        return
+      }
+      if (!parsed.data) return
+      decrypted[i] = parsed.data
+    }
+    setShards(decrypted)
+    // With a manifest loaded, only shards it lists are recomposed
+    if (manifest) {
+      const checked = JSON.parse(await CheckManifestFn(JSON.stringify(manifest), decrypted)) as ManifestCheckResult
+      setManifestCheck(checked.data)
+      if (checked.error || !checked.data?.ready) {
+        setResult({ error: checked.error ?? describeCheck(checked.data!), data: null })
+        return
+      }
+    }
+    const result = await RecomposeBytesFn(decrypted)
+    const parsedResult = JSON.parse(result) as RecomposeBytesResult
+    setRecovered(parsedResult.data)
+    setErrorIndex(parsedResult.index ?? null)
+    setResult({ error: parsedResult.error, data: parsedResult.data ? describeSecret(parsedResult.data) : null })
     window.parent.postMessage({ type: 'color-change', color1: bindActiveColors[0], color2: bindActiveColors[1] }, '*')
   }
 
-  const onUpload = async () => {
-    const fileContent = await UploadFileFn()
-    if (!fileContent) return
+  const onShowMnemonic = async () => {
+    const parsedResult = JSON.parse(await RecomposeMnemonicFn(shards)) as RecomposeResult
+    setResult({ error: parsedResult.error, data: parsedResult.data ?? result.data })
+  }
+
+  const onSaveSecret = async () => {
+    if (!recovered) return
+    try {
+      await SaveRecoveredSecretFn(recovered.data, "recovered-secret")
+    } catch (err) {
+      setResult({ error: String(err), data: result.data })
+    }
+  }
+
+  const loadShards = async (fileContent: string) => {
     const shards = fileContent.split('\n').filter(line => line.trim() !== '')
     setShards(shards)
     await onRecompose()
   }
 
+  const onUpload = async () => {
+    try {
+      const fileContent = await UploadFileFn()
+      if (!fileContent) return
+      await loadShards(fileContent)
+    } catch (err) {
+      if (String(err) === "passphrase required") {
+        setLocked("sealed")
+      } else {
+        setResult({ error: String(err), data: null })
+      }
+    }
+  }
+
+  const addShard = (shard: string) => {
+    const emptyIndex = shards.findIndex(s => s === "")
+    setShards(emptyIndex === -1 ? [...shards, shard] : shards.map((s, i) => i === emptyIndex ? shard : s))
+  }
+
+  // addShards fills the empty inputs first, then appends the rest
+  const addShards = (added: string[]) => setShards(current => {
+    const next = [...current]
+    for (const shard of added) {
+      const emptyIndex = next.findIndex(s => s === "")
+      if (emptyIndex === -1) next.push(shard)
+      else next[emptyIndex] = shard
+    }
+    return next
+  })
+
+  const onImportPGP = async (keyPassphrase: string) => {
+    try {
+      const shard = await ImportShardPGPFn(keyPassphrase)
+      setLocked(null)
+      setPassphrase("")
+      if (shard) addShard(shard)
+    } catch (err) {
+      if (String(err).includes("passphrase")) setLocked("pgp")
+      setResult({ error: String(err), data: null })
+    }
+  }
+
+  const onUnlock = async () => {
+    if (locked === "pgp") {
+      await onImportPGP(passphrase)
+      return
+    }
+    try {
+      const fileContent = await UnlockShardFn(passphrase)
+      setLocked(null)
+      setPassphrase("")
+      setResult({ error: null, data: null })
+      await loadShards(fileContent)
+    } catch (err) {
+      setResult({ error: String(err), data: null })
+    }
+  }
+
+  const onLoadManifest = async () => {
+    const parsed = JSON.parse(await OpenManifestFn()) as ManifestResult
+    if (parsed.error) {
+      setResult({ error: parsed.error, data: null })
+      return
+    }
+    if (parsed.data) {
+      setManifest(parsed.data)
+      setManifestCheck(null)
+    }
+  }
+
+  const onCombineFile = async () => {
+    const parsedResult = JSON.parse(await CombineToFileFn()) as FileOperationResult
+    if (parsedResult.error) {
+      setResult({ error: parsedResult.error, data: null })
+    } else if (parsedResult.data) {
+      setResult({ error: null, data: `File restored to ${parsedResult.data}` })
+    }
+  }
+
+  const onImportQR = async () => {
+    try {
+      const shard = await ImportShardQRFn()
+      if (!shard) return
+      addShard(shard)
+    } catch (err) {
+      setResult({ error: String(err), data: null })
+    }
+  }
+
   return (
     <motion.div
       variants={bindVariants.container}
@@ -51,11 +209,59 @@ export default function Bind() {
         <Button variant="outline" size="sm" onClick={onUpload}>
           Upload
         </Button>
-        <p className="text-sm text-crystal-200">This will upload the shards from your local machine.</p>
+        <Button variant="outline" size="sm" onClick={onImportQR}>
+          Scan QR
+        </Button>
+        <Button variant="outline" size="sm" onClick={() => onImportPGP("")}>
+          Import PGP
+        </Button>
+        <Button variant="outline" size="sm" onClick={onCombineFile}>
+          Restore file
+        </Button>
+        <Button variant="outline" size="sm" onClick={onLoadManifest}>
+          Load manifest
+        </Button>
+        <Button variant="outline" size="sm" onClick={() => setOneAtATime(!oneAtATime)}>
+          {oneAtATime ? "All shards at once" : "One custodian at a time"}
+        </Button>
+        <p className="text-sm text-crystal-200">This will upload the shards from your local machine, or drop shard files, QR images and encrypted shards below.</p>
       </div>
-      <div className="grid grid-cols-2 gap-6">
-        {/* Left Column - Controls and Shards */}
-        <div className="flex flex-col">
+      {dropErrors.length > 0 && (
+        <div className="flex flex-col items-center">
+          {dropErrors.map((f, i) => (
+            <p key={i} className="text-sm text-red-500">{f.name}: {f.error}</p>
+          ))}
+          <Button variant="ghost" size="sm" onClick={() => setDropErrors([])}>Dismiss</Button>
+        </div>
+      )}
+      <DealerKey mode="verify" />
+      {manifest && (
+        <p className="text-sm text-crystal-200">
+          Checking shards against set {manifest.setId}: {manifest.threshold} of {manifest.shares} needed, created {new Date(manifest.createdAt).toLocaleString()}.
+        </p>
+      )}
+      {locked && (
+        <div className="flex items-center gap-2">
+          <Input
+            type="password"
+            placeholder={locked === "pgp" ? "Passphrase of your PGP private key" : "This file is sealed, enter its passphrase"}
+            value={passphrase}
+            onChange={(e) => setPassphrase(e.target.value)}
+            onKeyDown={(e) => e.key === "Enter" && onUnlock()}
+            className="w-72"
+          />
+          <Button size="sm" onClick={onUnlock} disabled={!passphrase}>
+            Unlock
+          </Button>
+        </div>
+      )}
+      {oneAtATime && <ReconstructionSession manifest={manifest} />}
+      {!oneAtATime && <div className="grid grid-cols-2 gap-6">
+        {/* Left Column - Controls and Shards, which accepts dropped files */}
+        <div
+          className="flex flex-col rounded-md [&.wails-drop-target-active]:ring-2 [&.wails-drop-target-active]:ring-crystal-400"
+          style={{ "--wails-drop-target": "drop" } as CSSProperties}
+        >
           <BindManualController shards={shards} onAdd={() => setShards([...shards, ""])} onRemove={() => setShards(shards.slice(0, -1))} onReset={onReset} />
 
           <motion.div variants={bindVariants.item} className="grid grid-cols-1 gap-3 mt-4 overflow-y-scroll max-h-[135px] w-full">
@@ -76,9 +282,15 @@ export default function Bind() {
                     placeholder={`Paste shard ${i + 1} here...`}
                     value={shard}
                     onChange={(e) => setShards(shards.map((s, j) => j === i ? e.target.value : s))}
-                    className="flex-1"
+                    className={errorIndex === i ? "flex-1 border-red-500" : "flex-1"}
+                    aria-invalid={errorIndex === i}
                   />
                 </div>
+                {manifestCheck?.shards.filter(c => c.index === i).map(c => (
+                  <p key={c.index} className={c.status === "ok" ? "text-xs text-crystal-300 mt-1" : "text-xs text-red-500 mt-1"}>
+                    {describeShardCheck(c)}
+                  </p>
+                ))}
               </motion.div>
             ))}
           </motion.div>
@@ -102,6 +314,24 @@ export default function Bind() {
               className="h-full"
             >
               {result.data && <Textarea value={result.data} readOnly className="h-full min-h-[200px] resize-none" />}
+              {result.data && (
+                <div className="mt-2 flex items-center gap-2">
+                  <Button variant="outline" size="sm" onClick={() => copyToClipboard(result.data!)}>
+                    Copy
+                  </Button>
+                  <ClipboardStatus />
+                </div>
+              )}
+              {recovered && (
+                <Button variant="outline" size="sm" onClick={onSaveSecret} className="mt-2">
+                  Save recovered secret to file
+                </Button>
+              )}
+              {recovered && isMnemonicEntropy(recovered) && (
+                <Button variant="outline" size="sm" onClick={onShowMnemonic} className="mt-2 ml-2">
+                  Show as BIP-39 mnemonic
+                </Button>
+              )}
               {result.error && <p className="text-red-500">{result.error}</p>}
             </motion.div>
           ) : (
@@ -110,7 +340,42 @@ export default function Bind() {
             </div>
           )}
         </div>
-      </div>
+      </div>}
     </motion.div>
   );
-}
\ No newline at end of file
+}
+
+// isMnemonicEntropy reports whether a binary secret has the size of BIP-39
+// entropy, which is what Split stores for a seed phrase.
+function isMnemonicEntropy(secret: NonNullable<RecomposeBytesResult["data"]>): boolean {
+  return !secret.contentType.startsWith("text/") && secret.size >= 16 && secret.size <= 32 && secret.size % 4 === 0
+}
+
+// describeSecret renders a recovered secret for display: text is decoded as
+// UTF-8, anything else is summarised so binary data is never mangled.
+function describeSecret(secret: NonNullable<RecomposeBytesResult["data"]>): string {
+  if (!secret.contentType.startsWith("text/")) {
+    return `Binary secret (${secret.size} bytes, ${secret.contentType}). Use "Save recovered secret to file" to keep the exact bytes.`
+  }
+  const bytes = Uint8Array.from(atob(secret.data), c => c.charCodeAt(0))
+  return new TextDecoder().decode(bytes)
+}
+
+// describeShardCheck explains the manifest status of one shard.
+function describeShardCheck(check: ShardCheck): string {
+  switch (check.status) {
+    case "ok": return `Held by ${check.custodian}`
+    case "duplicate": return `Same shard as another one, held by ${check.custodian}`
+    case "mismatch": return `Does not match the shard given to ${check.custodian}`
+    case "unknown": return "Not part of this shard set"
+    default: return check.reason ?? "Invalid shard"
+  }
+}
+
+// describeCheck explains why a manifest check does not allow recomposing.
+function describeCheck(check: ManifestCheck): string {
+  if (check.shards.some(c => c.status !== "ok" && c.status !== "duplicate")) {
+    return "Some shards do not match the manifest"
+  }
+  return `${check.matched} of the ${check.threshold} shards needed match the manifest`
+}
@@ -0,0 +1,182 @@
+import { useEffect, useState } from "react";
+import { AbortCeremony as AbortCeremonyFn, AcknowledgeHandoff as AcknowledgeHandoffFn, Ceremony as CeremonyFn, CeremonySplit as CeremonySplitFn, ConfirmParameters as ConfirmParametersFn, Encodings as EncodingsFn, RegisterParticipant as RegisterParticipantFn, RevealCeremonyShard as RevealCeremonyShardFn, StartCeremony as StartCeremonyFn, WipeCeremony as WipeCeremonyFn } from "../../wailsjs/go/main/App";
+
+import { Button } from "./ui/button";
+import { Input } from "./ui/input";
+import { Label } from "./ui/label";
+import { Textarea } from "./ui/textarea";
+import { CeremonyResult, CeremonyState, RecomposeResult } from "../types/core";
+
+const STAGE_LABELS: Record<CeremonyState["stage"], string> = {
+  register: "1. Register participants and confirm parameters",
+  split: "2. Split the secret",
+  handoff: "3. Hand each shard to its custodian",
+  wipe: "4. Wipe the shards",
+  done: "Ceremony closed",
+}
+
+export default function Ceremony() {
+  const [state, setState] = useState<CeremonyState | null>(null)
+  const [error, setError] = useState<string | null>(null)
+  const [encodings, setEncodings] = useState<string[]>(["base64", "hex"])
+  const [title, setTitle] = useState("")
+  const [name, setName] = useState("")
+  const [threshold, setThreshold] = useState(2)
+  const [output, setOutput] = useState("base64")
+  const [witness, setWitness] = useState("")
+  const [secret, setSecret] = useState("")
+  // The shard is hidden once copied, so the read-back comes from the
+  // custodian's copy and not from the screen
+  const [revealed, setRevealed] = useState<{ index: number, shard: string, hidden: boolean } | null>(null)
+  const [readBack, setReadBack] = useState("")
+  const [reason, setReason] = useState("")
+
+  useEffect(() => {
+    CeremonyFn().then(result => setState((JSON.parse(result) as CeremonyResult).data))
+    EncodingsFn().then(setEncodings).catch(() => {})
+  }, [])
+
+  // step runs a ceremony step and shows the new state or the error
+  const step = async (call: Promise<string>) => {
+    const parsed = JSON.parse(await call) as CeremonyResult
+    setError(parsed.error)
+    if (parsed.data) setState(parsed.data)
+    return !parsed.error
+  }
+
+  const onRegister = async (role: "custodian" | "witness") => {
+    if (await step(RegisterParticipantFn(name, role))) setName("")
+  }
+
+  const onSplit = async () => {
+    if (await step(CeremonySplitFn(secret))) setSecret("")
+  }
+
+  const onReveal = async (index: number) => {
+    const parsed = JSON.parse(await RevealCeremonyShardFn(index)) as RecomposeResult
+    setError(parsed.error)
+    if (parsed.data) {
+      setRevealed({ index, shard: parsed.data, hidden: false })
+      setReadBack("")
+      await step(CeremonyFn())
+    }
+  }
+
+  const onAcknowledge = async (index: number) => {
+    if (await step(AcknowledgeHandoffFn(index, readBack))) {
+      setRevealed(null)
+      setReadBack("")
+    }
+  }
+
+  const onAbort = async () => {
+    setRevealed(null)
+    await step(AbortCeremonyFn(reason))
+  }
+
+  const open = state && state.stage !== "done"
+  return (
+    <div className="flex flex-col gap-3 p-4 w-full max-h-[330px] overflow-y-auto">
+      {!open && (
+        <div className="flex items-center gap-2">
+          <Input placeholder="Ceremony title" value={title} onChange={(e) => setTitle(e.target.value)} />
+          <Button size="sm" onClick={() => step(StartCeremonyFn(title))} disabled={!title.trim()}>
+            Start ceremony
+          </Button>
+        </div>
+      )}
+      {state && <p className="text-sm font-semibold text-crystal-100">{state.title}: {STAGE_LABELS[state.stage]}</p>}
+
+      {state?.stage === "register" && (
+        <>
+          <div className="flex items-center gap-2">
+            <Input placeholder="Participant name" value={name} onChange={(e) => setName(e.target.value)} />
+            <Button size="sm" variant="outline" onClick={() => onRegister("custodian")} disabled={!name.trim()}>Add custodian</Button>
+            <Button size="sm" variant="outline" onClick={() => onRegister("witness")} disabled={!name.trim()}>Add witness</Button>
+          </div>
+          <p className="text-sm text-crystal-200">
+            Custodians: {state.custodians.map(c => c.name).join(", ") || "none"}. Witnesses: {state.witnesses.join(", ") || "none"}.
+          </p>
+          <div className="flex items-end gap-2">
+            <div>
+              <Label htmlFor="ceremony-threshold">Shards needed</Label>
+              <Input id="ceremony-threshold" type="number" min={2} max={Math.max(2, state.custodians.length)} value={threshold} onChange={(e) => setThreshold(Number(e.target.value))} className="w-24" />
+            </div>
+            <div>
+              <Label htmlFor="ceremony-output">Encoding</Label>
+              <select id="ceremony-output" value={output} onChange={(e) => setOutput(e.target.value)} className="h-9 rounded-md border bg-transparent px-2 text-sm">
+                {encodings.map(enc => <option key={enc} value={enc} className="text-black">{enc}</option>)}
+              </select>
+            </div>
+            <div>
+              <Label htmlFor="ceremony-witness">Confirmed by</Label>
+              <select id="ceremony-witness" value={witness} onChange={(e) => setWitness(e.target.value)} className="h-9 rounded-md border bg-transparent px-2 text-sm">
+                <option value="" className="text-black">Witness...</option>
+                {state.witnesses.map(w => <option key={w} value={w} className="text-black">{w}</option>)}
+              </select>
+            </div>
+            <Button size="sm" onClick={() => step(ConfirmParametersFn(threshold, output, witness))} disabled={!witness}>
+              Confirm {threshold} of {state.custodians.length}
+            </Button>
+          </div>
+        </>
+      )}
+
+      {state?.stage === "split" && (
+        <>
+          <Textarea placeholder="Enter the secret..." value={secret} onChange={(e) => setSecret(e.target.value)} className="max-h-[100px]" />
+          <Button size="sm" onClick={onSplit} disabled={!secret}>
+            Split into {state.custodians.length} shards, {state.threshold} needed
+          </Button>
+        </>
+      )}
+
+      {state?.stage === "handoff" && state.custodians.map((c, i) => (
+        <div key={c.name} className="flex flex-col gap-1 border border-crystal-500/20 rounded-sm p-2">
+          <div className="flex items-center justify-between gap-2">
+            <span className="text-sm text-crystal-100">{c.name}</span>
+            {c.acknowledged
+              ? <span className="text-sm text-crystal-300">Acknowledged</span>
+              : <Button size="sm" variant="outline" onClick={() => onReveal(i)} disabled={revealed !== null && revealed.index !== i}>Reveal shard</Button>}
+          </div>
+          {revealed?.index === i && !revealed.hidden && (
+            <>
+              <pre className="text-xs text-crystal-200 font-mono whitespace-pre-wrap break-all">{revealed.shard}</pre>
+              <Button size="sm" variant="outline" onClick={() => setRevealed({ ...revealed, shard: "", hidden: true })}>
+                Custodian has copied the shard
+              </Button>
+            </>
+          )}
+          {revealed?.index === i && revealed.hidden && (
+            <>
+              <div className="flex items-center gap-2">
+                <Input placeholder="Shard read back from the custodian's copy" value={readBack} onChange={(e) => setReadBack(e.target.value)} />
+                <Button size="sm" onClick={() => onAcknowledge(i)} disabled={!readBack.trim()}>Acknowledge</Button>
+              </div>
+            </>
+          )}
+        </div>
+      ))}
+
+      {state?.stage === "wipe" && (
+        <Button size="sm" onClick={() => step(WipeCeremonyFn())}>
+          Wipe shards and close the transcript
+        </Button>
+      )}
+
+      {state?.stage === "done" && (
+        <p className="text-sm text-crystal-200 break-all">
+          Transcript saved to {state.transcript}. Note its final hash on paper: {state.head}
+        </p>
+      )}
+
+      {open && (
+        <div className="flex items-center gap-2">
+          <Input placeholder="Reason for aborting" value={reason} onChange={(e) => setReason(e.target.value)} />
+          <Button size="sm" variant="outline" onClick={onAbort} disabled={!reason.trim()}>Abort ceremony</Button>
+        </div>
+      )}
+      {error && <p className="text-sm text-red-500">{error}</p>}
+    </div>
+  )
+}
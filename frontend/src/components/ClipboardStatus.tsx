@@ -0,0 +1,34 @@
+import { useEffect, useState } from "react";
+
+import { Button } from "./ui/button";
+import { clearClipboard, subscribeClipboard } from "../lib/clipboard";
+import { ClipboardState } from "../types/core";
+
+// ClipboardStatus counts down while a copied shard or secret is held on the
+// clipboard, and offers to clear it early.
+export default function ClipboardStatus() {
+  const [state, setState] = useState<ClipboardState>({ held: false, cleared: false })
+  const [now, setNow] = useState(Date.now())
+
+  useEffect(() => subscribeClipboard(setState), [])
+
+  // Tick every second while a value is held
+  useEffect(() => {
+    if (!state.held) return
+    setNow(Date.now())
+    const timer = setInterval(() => setNow(Date.now()), 1000)
+    return () => clearInterval(timer)
+  }, [state.held, state.expiresAt])
+
+  if (!state.held || !state.expiresAt) return null
+  const remaining = Math.max(0, Math.round((new Date(state.expiresAt).getTime() - now) / 1000))
+
+  return (
+    <div className="flex items-center gap-2 text-sm text-crystal-300">
+      <span>Clipboard clears in {remaining} s</span>
+      <Button variant="ghost" size="sm" onClick={clearClipboard} className="h-7 px-2">
+        Clear now
+      </Button>
+    </div>
+  )
+}
@@ -0,0 +1,53 @@
+import { useEffect, useState } from "react";
+import { DealerKey as DealerKeyFn, LoadDealerKey as LoadDealerKeyFn, PinDealerKey as PinDealerKeyFn, SetStrictSignatures as SetStrictSignaturesFn } from "../../wailsjs/go/main/App";
+
+import { Button } from "./ui/button";
+import { Label } from "./ui/label";
+import { DealerKeyResult, DealerKeyStatus } from "../types/core";
+
+type DealerKeyProps = {
+  // "sign" loads the key that signs new shards, "verify" pins the key that
+  // incoming shards must be signed with
+  mode: "sign" | "verify"
+}
+
+export default function DealerKey({ mode }: DealerKeyProps) {
+  const [status, setStatus] = useState<DealerKeyStatus | null>(null)
+  const [error, setError] = useState<string | null>(null)
+
+  useEffect(() => {
+    DealerKeyFn().then(result => setStatus((JSON.parse(result) as DealerKeyResult).data))
+  }, [])
+
+  const onLoad = async () => {
+    const parsed = JSON.parse(await (mode === "sign" ? LoadDealerKeyFn() : PinDealerKeyFn())) as DealerKeyResult
+    setError(parsed.error)
+    if (parsed.data) setStatus(parsed.data)
+  }
+
+  const onStrict = async (strict: boolean) => {
+    await SetStrictSignaturesFn(strict)
+    setStatus(status ? { ...status, strict } : status)
+  }
+
+  const fingerprint = mode === "sign" ? status?.signing : status?.pinned
+  return (
+    <div className="flex items-center gap-2">
+      <Button variant="outline" size="sm" onClick={onLoad}>
+        {mode === "sign" ? "Load signing key" : "Pin dealer key"}
+      </Button>
+      {mode === "verify" && (
+        <>
+          <input type="checkbox" id="strict-signatures" checked={status?.strict ?? false} onChange={(e) => onStrict(e.target.checked)} className="cursor-pointer" />
+          <Label htmlFor="strict-signatures" className="cursor-pointer">Reject unsigned shards</Label>
+        </>
+      )}
+      <p className="text-sm text-crystal-200">
+        {fingerprint
+          ? `${mode === "sign" ? "Shards are signed with" : "Signatures are checked against"} dealer key ${fingerprint}.`
+          : mode === "sign" ? "Shards are not signed." : "No dealer key pinned."}
+      </p>
+      {error && <p className="text-sm text-red-500">{error}</p>}
+    </div>
+  )
+}
@@ -0,0 +1,55 @@
+import { motion } from "framer-motion";
+
+import { Button } from "./ui/button";
+import { EncryptedShardsProps } from "../types/core";
+import { Icon } from "./Icon";
+import { splitResultVariants } from "../lib/motions";
+
+export default function EncryptedShards({ shards, recipients, onBack, onSave }: EncryptedShardsProps) {
+  return (
+    <motion.div
+      variants={splitResultVariants.container}
+      initial="hidden"
+      animate="visible"
+      className="w-full max-w-4xl mx-auto"
+    >
+      <div className="flex items-center justify-between mb-4">
+        <h3 className="font-semibold text-slate-100 mb-1">Encrypted shards</h3>
+        <Button variant="ghost" size="sm" onClick={onBack}>
+          <Icon icon="Back" className="w-4 h-4" />&nbsp;Back
+        </Button>
+      </div>
+      <p className="text-sm text-crystal-200">Each shard is encrypted to one custodian's age key. Send every file to its custodian; they open it on the Bind tab with their private key, or with the age command line tool.</p>
+      <hr className="my-4 border-crystal-500/20" />
+      <div className="grid grid-cols-1 gap-3 max-h-[200px] overflow-y-auto">
+        {shards.map((shard, index) => (
+          <motion.div
+            key={index}
+            variants={splitResultVariants.cardVariants}
+            initial="hidden"
+            animate="visible"
+            transition={{ delay: index * 0.1 }}
+            className="relative bg-crystal-700/50 rounded-md border border-crystal-600/50 p-2 hover:bg-crystal-700/70 hover:border-crystal-500/50 transition-all duration-200"
+          >
+            <div className="flex items-center justify-between gap-3">
+              <div className="flex-1 min-w-0">
+                <p className="text-sm text-crystal-200 font-mono truncate" title={recipients[index]}>
+                  Shard {index + 1} for {recipients[index]}
+                </p>
+              </div>
+              <Button
+                variant="ghost"
+                size="sm"
+                onClick={() => onSave(shard, index)}
+                className="duration-200 h-8 px-2 text-crystal-200 bg-crystal-600/50"
+                title="Save the encrypted shard"
+              >
+                <Icon icon="Download" className="w-4 h-4" />&nbsp;Save As...
+              </Button>
+            </div>
+          </motion.div>
+        ))}
+      </div>
+    </motion.div>
+  )
+}
@@ -0,0 +1,108 @@
+import { useEffect, useState } from "react";
+import { AddSessionShard as AddSessionShardFn, EndReconstruction as EndReconstructionFn, Reconstruction as ReconstructionFn, StartReconstruction as StartReconstructionFn } from "../../wailsjs/go/main/App";
+import { EventsOn } from "../../wailsjs/runtime/runtime";
+
+import { Button } from "./ui/button";
+import { Input } from "./ui/input";
+import { Label } from "./ui/label";
+import { Textarea } from "./ui/textarea";
+import ClipboardStatus from "./ClipboardStatus";
+import { copyToClipboard } from "../lib/clipboard";
+import { Manifest, ReconstructionResult, ReconstructionState } from "../types/core";
+
+type ReconstructionSessionProps = {
+  // manifest restricts the session to the shards it lists, and sets the threshold
+  manifest: Manifest | null
+}
+
+export default function ReconstructionSession({ manifest }: ReconstructionSessionProps) {
+  const [state, setState] = useState<ReconstructionState | null>(null)
+  const [error, setError] = useState<string | null>(null)
+  const [threshold, setThreshold] = useState(2)
+  const [shard, setShard] = useState("")
+  const [now, setNow] = useState(Date.now())
+
+  useEffect(() => {
+    ReconstructionFn().then(result => setState((JSON.parse(result) as ReconstructionResult).data))
+    return EventsOn("reconstruction:wiped", () => {
+      ReconstructionFn().then(result => setState((JSON.parse(result) as ReconstructionResult).data))
+    })
+  }, [])
+
+  // Tick every second while a secret is held, for the wipe countdown
+  useEffect(() => {
+    if (!state?.expiresAt) return
+    const timer = setInterval(() => setNow(Date.now()), 1000)
+    return () => clearInterval(timer)
+  }, [state?.expiresAt])
+
+  const apply = async (call: Promise<string>) => {
+    const parsed = JSON.parse(await call) as ReconstructionResult
+    setError(parsed.index !== undefined ? `Shard rejected: ${parsed.error}` : parsed.error)
+    if (parsed.data) setState(parsed.data)
+    return !parsed.error
+  }
+
+  const onStart = () => apply(StartReconstructionFn(manifest ? 0 : threshold, manifest ? JSON.stringify(manifest) : "", 0))
+
+  const onAdd = async () => {
+    if (await apply(AddSessionShardFn(shard))) setShard("")
+  }
+
+  const active = state && !state.wiped
+  const secretText = state?.secret?.contentType.startsWith("text/")
+    ? new TextDecoder().decode(Uint8Array.from(atob(state.secret.data), c => c.charCodeAt(0)))
+    : null
+  const remaining = state?.expiresAt ? Math.max(0, Math.round((new Date(state.expiresAt).getTime() - now) / 1000)) : 0
+
+  return (
+    <div className="flex flex-col gap-3 w-full">
+      {!active && (
+        <div className="flex items-end gap-2">
+          {manifest ? (
+            <p className="text-sm text-crystal-200">Shards are checked against set {manifest.setId}, {manifest.threshold} needed.</p>
+          ) : (
+            <div>
+              <Label htmlFor="session-threshold">Shards needed</Label>
+              <Input id="session-threshold" type="number" min={2} max={255} value={threshold} onChange={(e) => setThreshold(Number(e.target.value))} className="w-24" />
+            </div>
+          )}
+          <Button size="sm" onClick={onStart}>Start session</Button>
+          {state?.wiped && <p className="text-sm text-crystal-300">The previous session was wiped.</p>}
+        </div>
+      )}
+
+      {active && !state.complete && (
+        <>
+          <p className="text-sm text-crystal-100">
+            {state.collected} of {state.threshold} collected{state.custodians.length > 0 && ` (${state.custodians.join(", ")})`}
+          </p>
+          <div className="flex items-center gap-2">
+            <Input placeholder="Paste the next custodian's shard..." value={shard} onChange={(e) => setShard(e.target.value)} onKeyDown={(e) => e.key === "Enter" && onAdd()} />
+            <Button size="sm" onClick={onAdd} disabled={!shard.trim()}>Add shard</Button>
+          </div>
+        </>
+      )}
+
+      {active && state.secret && (
+        <>
+          <Textarea value={secretText ?? `Binary secret (${state.secret.size} bytes, ${state.secret.contentType})`} readOnly className="min-h-[100px] resize-none" />
+          <p className="text-sm text-crystal-300">The secret will be wiped in {remaining} s.</p>
+          {secretText !== null && (
+            <div className="flex items-center gap-2">
+              <Button size="sm" variant="outline" onClick={() => copyToClipboard(secretText)}>Copy secret</Button>
+              <ClipboardStatus />
+            </div>
+          )}
+        </>
+      )}
+
+      {active && (
+        <Button size="sm" variant="outline" onClick={() => apply(EndReconstructionFn())}>
+          Wipe now
+        </Button>
+      )}
+      {error && <p className="text-sm text-red-500">{error}</p>}
+    </div>
+  )
+}
@@ -1,38 +1,135 @@
 import { useState } from "react";
-import { Split as SplitFn, SaveFileDialog as SaveFileDialogFn } from "../../wailsjs/go/main/App";
+import { Split as SplitFn, SplitMnemonic as SplitMnemonicFn, SplitForRecipients as SplitForRecipientsFn, SaveFileDialog as SaveFileDialogFn, ExportShardQR as ExportShardQRFn, ExportPaperBackup as ExportPaperBackupFn, SplitFile as SplitFileFn, SplitDirectory as SplitDirectoryFn, SealShards as SealShardsFn, ExportShardPGP as ExportShardPGPFn, ExportManifest as ExportManifestFn, NewSetID as NewSetIDFn } from "../../wailsjs/go/main/App";
 
 import SplitResults from "./SplitResults";
 import SplitForm from "./SplitForm";
-import { SplitResult } from "../types/core";
+import EncryptedShards from "./EncryptedShards";
+import DealerKey from "./DealerKey";
+import { EncryptedShardsResult, FileOperationResult, SetIDResult, SplitResult } from "../types/core";
 import { splitActiveColors, splitIdleColors } from "@/lib/colors";
 
 export default function Split() {
   const [step, setStep] = useState<number>(0)
   const [result, setResult] = useState<SplitResult>({ error: null, data: null } as SplitResult)
+  const [threshold, setThreshold] = useState<number>(0)
+  const [setId, setSetId] = useState<string>("")
+  const [fileResult, setFileResult] = useState<FileOperationResult>({ error: null, data: null })
+  const [encrypted, setEncrypted] = useState<{ shards: string[], recipients: string[] }>({ shards: [], recipients: [] })
 
-  const handleSplit = async (secret: string, shards: number, shardsNeeded: number, output: string) => {
+  const handleSplit = async (secret: string, shards: number, shardsNeeded: number, output: string, mnemonic: boolean) => {
     setResult({ error: null, data: null })
-    const result = await SplitFn(secret, shards, shardsNeeded, output)
+    setThreshold(shardsNeeded)
+    const split = mnemonic ? SplitMnemonicFn : SplitFn
+    const result = await split(secret, shards, shardsNeeded, output)
     const parsedResult = JSON.parse(result) as SplitResult
     setResult(parsedResult)
+    if (parsedResult.error) {
+      setFileResult({ error: describeSplitError(parsedResult), data: null })
+      return
+    }
+    // One set ID per split, shared by its paper backup and manifest
+    const id = JSON.parse(await NewSetIDFn()) as SetIDResult
+    if (id.error || !id.data) {
+      setFileResult({ error: id.error, data: null })
+      return
+    }
+    setSetId(id.data)
+    setFileResult({ error: null, data: null })
     setStep(1)
     window.parent.postMessage({ type: 'color-change', color1: splitActiveColors[0], color2: splitActiveColors[1] }, '*')
   }
 
-  const handleDownload = async (data: string) => {
+  const handleSplitForRecipients = async (secret: string, recipients: string[], shardsNeeded: number, output: string) => {
+    setFileResult({ error: null, data: null })
+    const parsedResult = JSON.parse(await SplitForRecipientsFn(secret, recipients, shardsNeeded, output)) as EncryptedShardsResult
+    if (parsedResult.error || !parsedResult.data) {
+      setFileResult({ error: describeSplitError(parsedResult), data: null })
+      return
+    }
+    setEncrypted({ shards: parsedResult.data, recipients })
+    setStep(2)
+    window.parent.postMessage({ type: 'color-change', color1: splitActiveColors[0], color2: splitActiveColors[1] }, '*')
+  }
+
+  const handleSaveEncrypted = async (shard: string, index: number) => {
+    const blob = new Blob([shard], { type: 'text/plain' })
+    await SaveFileDialogFn(Array.from(new Uint8Array(await blob.arrayBuffer())), `shard-${index + 1}.age`)
+  }
+
+  const handleSplitFile = async (shards: number, shardsNeeded: number) => {
+    setFileResult({ error: null, data: null })
+    const result = await SplitFileFn(shards, shardsNeeded)
+    setFileResult(JSON.parse(result) as FileOperationResult)
+  }
+
+  const handleSplitDirectory = async (shards: number, shardsNeeded: number) => {
+    setFileResult({ error: null, data: null })
+    const result = await SplitDirectoryFn(shards, shardsNeeded, true)
+    setFileResult(JSON.parse(result) as FileOperationResult)
+  }
+
+  const handleDownload = async (data: string, passphrase: string) => {
+    if (passphrase) {
+      const sealed = JSON.parse(await SealShardsFn(data, passphrase)) as SplitResult
+      if (sealed.error || !sealed.data) {
+        setResult({ error: sealed.error, data: null })
+        return
+      }
+      data = sealed.data
+    }
     const blob = new Blob([data], { type: 'text/plain' })
     await SaveFileDialogFn(Array.from(new Uint8Array(await blob.arrayBuffer())), "shards.txt")
   }
 
+  const handleExportQR = async (shard: string) => {
+    await ExportShardQRFn(shard, "png")
+  }
+
+  const handleExportPGP = async (shard: string) => {
+    try {
+      await ExportShardPGPFn(shard)
+    } catch (err) {
+      setResult({ error: String(err), data: result.data })
+    }
+  }
+
+  const handlePrint = async (shards: string[]) => {
+    await ExportPaperBackupFn(shards, [], threshold, setId)
+  }
+
+  const handleSaveManifest = async (shards: string[]) => {
+    try {
+      await ExportManifestFn(shards, [], threshold, setId)
+    } catch (err) {
+      setResult({ error: String(err), data: result.data })
+    }
+  }
+
   const handleBack = () => {
     setStep(0)
+    setEncrypted({ shards: [], recipients: [] })
     window.parent.postMessage({ type: 'color-change', color1: splitIdleColors[0], color2: splitIdleColors[1] }, '*')
   }
 
   return (
     <div className="flex flex-col flex items-center justify-between gap-3 p-4">
-      {step === 0 && <SplitForm onSplit={handleSplit} />}
-      {step === 1 && <SplitResults results={result} onBack={handleBack} onDownload={handleDownload} />}
+      {step === 0 && <SplitForm onSplit={handleSplit} onSplitForRecipients={handleSplitForRecipients} onSplitFile={handleSplitFile} onSplitDirectory={handleSplitDirectory} />}
+      {step === 0 && <DealerKey mode="sign" />}
+      {step === 0 && fileResult.error && <p className="text-sm text-red-500">{fileResult.error}</p>}
+      {step === 0 && Array.isArray(fileResult.data) && (
+        <p className="text-sm text-crystal-200">{fileResult.data.length} shard files written to {fileResult.data[0].replace(/[^/\\]+$/, "")}</p>
+      )}
+      {step === 2 && <EncryptedShards shards={encrypted.shards} recipients={encrypted.recipients} onBack={handleBack} onSave={handleSaveEncrypted} />}
+      {step === 1 && <SplitResults results={result} onBack={handleBack} onDownload={handleDownload} onExportQR={handleExportQR} onExportPGP={handleExportPGP} onPrint={handlePrint} onSaveManifest={handleSaveManifest} />}
     </div>
   )
-}
\ No newline at end of file
+}
+// describeSplitError adds the allowed range to parameter errors, so the user
+// knows which control to adjust.
+function describeSplitError(result: SplitResult | EncryptedShardsResult): string {
+  if (result.params) {
+    const { name, value, min, max } = result.params
+    return `${result.error} (${name} is ${value}, allowed ${min} to ${max})`
+  }
+  return result.error ?? ""
+}
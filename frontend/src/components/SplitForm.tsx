@@ -1,5 +1,6 @@
 import { motion } from "framer-motion";
-import { useState } from "react";
+import { useEffect, useState } from "react";
+import { Encodings as EncodingsFn } from "../../wailsjs/go/main/App";
 
 import { Label } from "./ui/label";
 import { Textarea } from "./ui/textarea";
@@ -12,11 +13,42 @@ import { splitFormVariants } from "../lib/motions";
 const MIN_SHARDS = 2
 const MAX_SHARDS = 255
 
-export default function SplitForm({ onSplit }: SplitFormProps) {
+const ENCODING_LABELS: Record<string, string> = {
+  base64: "Base64",
+  hex: "Hex",
+  base32: "Base32",
+  base58: "Base58",
+  zbase32: "z-base-32",
+  bech32: "Bech32",
+  grouped: "Grouped (read aloud)",
+}
+
+// Padding choices, appended to the output encoding so every shard has the
+// same length whatever the secret.
+const PADDING_OPTIONS = [
+  { value: "", label: "None" },
+  { value: "+pad", label: "Next power of two" },
+  { value: "+pad64", label: "64 bytes" },
+  { value: "+pad256", label: "256 bytes" },
+]
+
+export default function SplitForm({ onSplit, onSplitForRecipients, onSplitFile, onSplitDirectory }: SplitFormProps) {
   const [secret, setSecret] = useState<string>('')
   const [shards, setShards] = useState<number>(MIN_SHARDS)
   const [shardsNeeded, setShardsNeeded] = useState<number>(MIN_SHARDS)
-  const [output, setOutput] = useState<'base64' | 'hex'>('base64')
+  const [output, setOutput] = useState<string>('base64')
+  const [encodings, setEncodings] = useState<string[]>(['base64', 'hex'])
+  const [padding, setPadding] = useState<string>('')
+  const [compress, setCompress] = useState<boolean>(false)
+  const [mnemonic, setMnemonic] = useState<boolean>(false)
+  const [recipientKeys, setRecipientKeys] = useState<string>('')
+
+  // One age public key per line; with keys, one shard is made per custodian
+  const recipients = recipientKeys.split('\n').map(key => key.trim()).filter(key => key !== '')
+
+  useEffect(() => {
+    EncodingsFn().then(setEncodings).catch(() => {})
+  }, [])
 
   return (
     <motion.div
@@ -28,6 +60,13 @@ export default function SplitForm({ onSplit }: SplitFormProps) {
       <motion.div variants={splitFormVariants.item} className="grid w-full items-center gap-3">
         <Label htmlFor="secret">Secret</Label>
         <Textarea id="secret" value={secret} onChange={(e) => setSecret(e.target.value)} placeholder="Enter your secret here..." className="max-h-[120px] w-full" />
+        <div className="flex items-center space-x-2">
+          <input type="checkbox" id="mnemonic" checked={mnemonic && recipients.length === 0} disabled={recipients.length > 0} onChange={(e) => setMnemonic(e.target.checked)} className="cursor-pointer" />
+          <Label htmlFor="mnemonic" className="cursor-pointer">BIP-39 seed phrase (split the entropy, gives shorter shards)</Label>
+        </div>
+        <Label htmlFor="recipients">Encrypt to custodians (optional)</Label>
+        <Textarea id="recipients" value={recipientKeys} onChange={(e) => setRecipientKeys(e.target.value)} placeholder="One age public key (age1...) per custodian, one per line" className="max-h-[80px] w-full font-mono" />
+        {recipients.length > 0 && <p className="text-sm text-crystal-200">{recipients.length} custodians: one shard is made and encrypted for each key, in order.</p>}
       </motion.div>
 
       <motion.div variants={splitFormVariants.item} className="grid grid-cols-3 gap-6 mt-4">
@@ -35,29 +74,65 @@ export default function SplitForm({ onSplit }: SplitFormProps) {
         <ShardsSlider label="Shards Needed" value={shardsNeeded} min={MIN_SHARDS} max={shards} onChange={(value) => setShardsNeeded(value)} />
         <div className="flex flex-col gap-2">
           <Label htmlFor="output">Output</Label>
-          <RadioGroup defaultValue="base64" onValueChange={(value) => setOutput(value as 'base64' | 'hex')}>
-            <div className="flex items-center space-x-6">
-              <div className="flex items-center space-x-2">
-                <RadioGroupItem value="base64" id="base64" />
-                <Label htmlFor="base64" className="cursor-pointer">Base64</Label>
-              </div>
-              <div className="flex items-center space-x-2">
-                <RadioGroupItem value="hex" id="hex" />
-                <Label htmlFor="hex" className="cursor-pointer">Hex</Label>
-              </div>
+          <RadioGroup defaultValue="base64" onValueChange={(value) => setOutput(value)}>
+            <div className="flex flex-wrap items-center gap-x-6 gap-y-2">
+              {encodings.map((encoding) => (
+                <div key={encoding} className="flex items-center space-x-2">
+                  <RadioGroupItem value={encoding} id={encoding} />
+                  <Label htmlFor={encoding} className="cursor-pointer">{ENCODING_LABELS[encoding] ?? encoding}</Label>
+                </div>
+              ))}
             </div>
           </RadioGroup>
+          <Label htmlFor="padding" className="mt-2">Hide secret length</Label>
+          <RadioGroup id="padding" defaultValue="" onValueChange={(value) => setPadding(value)}>
+            <div className="flex flex-wrap items-center gap-x-6 gap-y-2">
+              {PADDING_OPTIONS.map((option) => (
+                <div key={option.value} className="flex items-center space-x-2">
+                  <RadioGroupItem value={option.value} id={`padding${option.value}`} />
+                  <Label htmlFor={`padding${option.value}`} className="cursor-pointer">{option.label}</Label>
+                </div>
+              ))}
+            </div>
+          </RadioGroup>
+          <div className="flex items-center space-x-2 mt-2">
+            <input type="checkbox" id="compress" checked={compress} onChange={(e) => setCompress(e.target.checked)} className="cursor-pointer" />
+            <Label htmlFor="compress" className="cursor-pointer">Compress before splitting</Label>
+          </div>
         </div>
       </motion.div>
 
-      <motion.div variants={splitFormVariants.item} className="mt-4">
+      <motion.div variants={splitFormVariants.item} className="mt-4 flex items-center gap-3">
+        <motion.div
+          variants={splitFormVariants.button}
+          whileHover="hover"
+          whileTap="tap"
+        >
+          <Button
+            onClick={() => recipients.length > 0
+              ? onSplitForRecipients(secret, recipients, shardsNeeded, output + (compress ? "+deflate" : "") + padding)
+              : onSplit(secret, shards, shardsNeeded, output + (compress ? "+deflate" : "") + padding, mnemonic)}
+            disabled={!secret || !shards || !shardsNeeded}
+          >
+            {recipients.length > 0 ? "Split and encrypt" : "Split"}
+          </Button>
+        </motion.div>
+        <motion.div
+          variants={splitFormVariants.button}
+          whileHover="hover"
+          whileTap="tap"
+        >
+          <Button variant="outline" onClick={() => onSplitFile(shards, shardsNeeded)} disabled={!shards || !shardsNeeded}>
+            Split a file...
+          </Button>
+        </motion.div>
         <motion.div
           variants={splitFormVariants.button}
           whileHover="hover"
           whileTap="tap"
         >
-          <Button onClick={() => onSplit(secret, shards, shardsNeeded, output)} disabled={!secret || !shards || !shardsNeeded}>
-            Split
+          <Button variant="outline" onClick={() => onSplitDirectory(shards, shardsNeeded)} disabled={!shards || !shardsNeeded}>
+            Split a folder...
           </Button>
         </motion.div>
       </motion.div>
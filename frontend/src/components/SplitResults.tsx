@@ -1,11 +1,17 @@
+import { useState } from "react";
 import { motion } from "framer-motion";
 
 import { Button } from "./ui/button";
+import { Input } from "./ui/input";
 import { SplitResultsProps } from "../types/core";
 import { Icon } from "./Icon";
+import ClipboardStatus from "./ClipboardStatus";
+import { copyToClipboard } from "../lib/clipboard";
 import { splitResultVariants } from "../lib/motions";
 
-export default function SplitResults({ results, onBack, onDownload }: SplitResultsProps) {
+export default function SplitResults({ results, onBack, onDownload, onExportQR, onExportPGP, onPrint, onSaveManifest }: SplitResultsProps) {
+  const [passphrase, setPassphrase] = useState("")
+
   if (!results.data || results.error) return null;
 
   return (
@@ -18,16 +24,30 @@ export default function SplitResults({ results, onBack, onDownload }: SplitResul
       <div className="flex items-center justify-between mb-4">
         <h3 className="font-semibold text-slate-100 mb-1">Shards</h3>
         <div className="flex items-center gap-2">
+          <ClipboardStatus />
           <Button variant="ghost" size="sm" onClick={onBack}>
             <Icon icon="Back" className="w-4 h-4" />&nbsp;Back
           </Button>
         </div>
       </div>
       <div className="my-4 flex justify-start items-center gap-2">
-        <Button disabled={!results.data} size="sm" onClick={() => onDownload(results.data!)}>
+        <Button disabled={!results.data} size="sm" onClick={() => onDownload(results.data!, passphrase)}>
           <Icon icon="Download" className="w-4 h-4" />&nbsp;Save As...
         </Button>
-        <p className="text-sm text-crystal-200">This will open a save dialog to choose where to save the shards as a txt file.</p>
+        <Button disabled={!results.data} size="sm" variant="outline" onClick={() => onPrint(results.data!.split('\n').filter(line => line.trim() !== ''))}>
+          Print backup
+        </Button>
+        <Button disabled={!results.data} size="sm" variant="outline" onClick={() => onSaveManifest(results.data!.split('\n').filter(line => line.trim() !== ''))}>
+          Save manifest
+        </Button>
+        <Input
+          type="password"
+          placeholder="Passphrase (optional)"
+          value={passphrase}
+          onChange={(e) => setPassphrase(e.target.value)}
+          className="w-48 h-8"
+        />
+        <p className="text-sm text-crystal-200">Save the shards as a txt file, sealed if a passphrase is set, or as a PDF with one printable page per custodian. The manifest records who holds which shard, without their contents.</p>
       </div>
       <hr className="my-4 border-crystal-500/20" />
       <div className="grid grid-cols-1 gap-3 md:grid-cols-2 max-h-[200px] overflow-y-auto">
@@ -51,8 +71,27 @@ export default function SplitResults({ results, onBack, onDownload }: SplitResul
                 <Button
                   variant="ghost"
                   size="sm"
-                  onClick={() => navigator.clipboard.writeText(line)}
+                  onClick={() => onExportQR(line)}
+                  className="duration-200 h-8 px-2 text-crystal-200 bg-crystal-600/50"
+                  title="Save as QR code"
+                >
+                  QR
+                </Button>
+                <Button
+                  variant="ghost"
+                  size="sm"
+                  onClick={() => onExportPGP(line)}
+                  className="duration-200 h-8 px-2 text-crystal-200 bg-crystal-600/50"
+                  title="Encrypt to a custodian's PGP key"
+                >
+                  PGP
+                </Button>
+                <Button
+                  variant="ghost"
+                  size="sm"
+                  onClick={() => copyToClipboard(line)}
                   className="duration-200 h-8 px-2 text-crystal-200 bg-crystal-600/50"
+                  title="Copy, cleared from the clipboard after 30 seconds"
                 >
                   <svg
                     className="w-4 h-4"
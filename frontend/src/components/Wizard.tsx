@@ -1,10 +1,12 @@
 import Bind from "./Bind";
+import Ceremony from "./Ceremony";
 import Split from "./Split";
 import TabsSharp from "./customized/tabs/tabs-10";
 
 const tabs = [
   { name: "Split" as const, value: "split", content: <Split /> },
   { name: "Bind" as const, value: "bind", content: <Bind /> },
+  { name: "Ceremony" as const, value: "ceremony", content: <Ceremony /> },
 ]
 
 export default function Wizard() {
@@ -6,7 +6,7 @@ import { useState, useEffect } from "react";
 
 type TabSharpProps = {
   initialTab: string
-  tabs: { name: "Bind" | "Split", value: string, content: React.ReactNode }[]
+  tabs: { name: "Bind" | "Split" | "Ceremony", value: string, content: React.ReactNode }[]
 }
 
 export default function TabsSharp({ tabs, initialTab }: TabSharpProps) {
@@ -1,6 +1,7 @@
 export { default as Add } from "./Add";
 export { default as Back } from "./Back";
 export { default as Bind } from "./Bind";
+export { default as Ceremony } from "./Ceremony";
 export { default as Download } from "./Download";
 export { default as Remove } from "./Remove";
 export { default as Reset } from "./Reset";
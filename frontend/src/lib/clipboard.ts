@@ -0,0 +1,51 @@
+import { ClearClipboard as ClearClipboardFn, CopyToClipboard as CopyToClipboardFn } from "../../wailsjs/go/main/App";
+import { EventsOn } from "../../wailsjs/runtime/runtime";
+import { ClipboardResult, ClipboardState } from "../types/core";
+
+// clipboardTimeout is how long copied shards and secrets stay on the
+// clipboard, in seconds.
+export const clipboardTimeout = 30
+
+type Listener = (state: ClipboardState) => void
+
+const listeners = new Set<Listener>()
+let current: ClipboardState = { held: false, cleared: false }
+let stopEvents: (() => void) | null = null
+
+function publish(state: ClipboardState) {
+  current = state
+  listeners.forEach(listener => listener(state))
+}
+
+// subscribeClipboard calls listener with the clipboard state now and on
+// every change. The backend clears the clipboard on its own when the timeout
+// expires and reports it with the "clipboard:cleared" event.
+export function subscribeClipboard(listener: Listener): () => void {
+  if (!stopEvents) {
+    stopEvents = EventsOn("clipboard:cleared", (cleared: boolean) => publish({ held: false, cleared }))
+  }
+  listeners.add(listener)
+  listener(current)
+  return () => {
+    listeners.delete(listener)
+    if (listeners.size === 0 && stopEvents) {
+      stopEvents()
+      stopEvents = null
+    }
+  }
+}
+
+// copyToClipboard copies text through the backend, which clears it after
+// clipboardTimeout seconds. It returns an error message, or null.
+export async function copyToClipboard(text: string): Promise<string | null> {
+  const result = JSON.parse(await CopyToClipboardFn(text, clipboardTimeout)) as ClipboardResult
+  if (result.data) publish(result.data)
+  return result.error
+}
+
+// clearClipboard clears the clipboard now, if it still holds our value.
+export async function clearClipboard(): Promise<string | null> {
+  const result = JSON.parse(await ClearClipboardFn()) as ClipboardResult
+  if (result.data) publish(result.data)
+  return result.error
+}
@@ -1,14 +1,87 @@
-export type SplitResult = { error: string | null, data: string | null }
+export type ErrorDetails = {
+  code?: string;
+  index?: number;
+  params?: { name: string, value: number, min: number, max: number };
+}
+
+export type SplitResult = { error: string | null, data: string | null } & ErrorDetails
 export type SplitFormProps = {
-  onSplit: (secret: string, shards: number, shardsNeeded: number, output: string) => void;
+  onSplit: (secret: string, shards: number, shardsNeeded: number, output: string, mnemonic: boolean) => void;
+  onSplitForRecipients: (secret: string, recipients: string[], shardsNeeded: number, output: string) => void;
+  onSplitFile: (shards: number, shardsNeeded: number) => void;
+  onSplitDirectory: (shards: number, shardsNeeded: number) => void;
+}
+
+export type EncryptedShardsResult = { error: string | null, data: string[] | null } & ErrorDetails
+export type EncryptedShardsProps = {
+  shards: string[];
+  recipients: string[];
+  onBack: () => void;
+  onSave: (shard: string, index: number) => void;
 }
 
+export type FileOperationResult = { error: string | null, data: string | string[] | null }
+
 export type RecomposeResult = { error: string | null, data: string | null }
+export type RecoveredSecret = { data: string, contentType: string, size: number }
+export type RecomposeBytesResult = { error: string | null, data: RecoveredSecret | null } & ErrorDetails
+export type SetIDResult = { error: string | null, data: string | null }
+export type Manifest = { setId: string, shares: number, threshold: number, createdAt: string, shards: { x: number, custodian: string, sha256: string }[] }
+export type ShardCheck = { index: number, x?: number, custodian?: string, status: "ok" | "unknown" | "mismatch" | "duplicate" | "invalid", reason?: string }
+export type ManifestCheck = { setId: string, threshold: number, matched: number, ready: boolean, shards: ShardCheck[] }
+export type ManifestResult = { error: string | null, data: Manifest | null } & ErrorDetails
+export type ManifestCheckResult = { error: string | null, data: ManifestCheck | null } & ErrorDetails
+export type DealerKeyStatus = { signing?: string, pinned?: string, strict: boolean }
+export type DealerKeyResult = { error: string | null, data: DealerKeyStatus | null } & ErrorDetails
+export type CustodianState = { name: string, x?: number, fingerprint?: string, revealed: boolean, acknowledged: boolean }
+export type CeremonyState = {
+  title: string;
+  stage: "register" | "split" | "handoff" | "wipe" | "done";
+  witnesses: string[];
+  custodians: CustodianState[];
+  threshold?: number;
+  output?: string;
+  transcript: string;
+  head: string;
+}
+export type CeremonyResult = { error: string | null, data: CeremonyState | null } & ErrorDetails
+export type ReconstructionState = {
+  collected: number;
+  threshold: number;
+  custodians: string[];
+  complete: boolean;
+  secret?: RecoveredSecret;
+  expiresAt?: string;
+  wiped: boolean;
+}
+export type ReconstructionResult = { error: string | null, data: ReconstructionState | null } & ErrorDetails
+export type ClipboardState = {
+  held: boolean;
+  expiresAt?: string;
+  cleared: boolean;
+}
+export type ClipboardResult = { error: string | null, data: ClipboardState | null } & ErrorDetails
+export type DroppedFile = {
+  name: string;
+  shards: string[];
+  error?: string;
+  code?: string;
+}
+export type DropResult = {
+  files: DroppedFile[];
+  shards: string[];
+  sealed: boolean;
+}
+export type DropImportResult = { error: string | null, data: DropResult | null } & ErrorDetails
 export type SplitResultsProps = {
   results: {
     error: string | null;
     data: string | null;
   };
   onBack: () => void;
-  onDownload: (data: string) => void;
+  onDownload: (data: string, passphrase: string) => void;
+  onExportQR: (shard: string) => void;
+  onExportPGP: (shard: string) => void;
+  onPrint: (shards: string[]) => void;
+  onSaveManifest: (shards: string[]) => void;
 }
\ No newline at end of file
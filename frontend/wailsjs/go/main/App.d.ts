@@ -1,10 +1,98 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AbortCeremony(arg1:string):Promise<string>;
+
+export function AcknowledgeHandoff(arg1:number,arg2:string):Promise<string>;
+
+export function AddSessionShard(arg1:string):Promise<string>;
+
+export function Ceremony():Promise<string>;
+
+export function CeremonySplit(arg1:string):Promise<string>;
+
+export function CheckManifest(arg1:string,arg2:Array<string>):Promise<string>;
+
+export function ClearClipboard():Promise<string>;
+
+export function CombineToFile():Promise<string>;
+
+export function ConfirmParameters(arg1:number,arg2:string,arg3:string):Promise<string>;
+
+export function CopyToClipboard(arg1:string,arg2:number):Promise<string>;
+
+export function DealerKey():Promise<string>;
+
+export function DecryptShard(arg1:string):Promise<string>;
+
+export function Encodings():Promise<Array<string>>;
+
+export function EndReconstruction():Promise<string>;
+
+export function ExportManifest(arg1:Array<string>,arg2:Array<string>,arg3:number,arg4:string):Promise<void>;
+
+export function ExportPaperBackup(arg1:Array<string>,arg2:Array<string>,arg3:number,arg4:string):Promise<void>;
+
+export function ExportShardPGP(arg1:string):Promise<void>;
+
+export function ExportShardQR(arg1:string,arg2:string):Promise<void>;
+
+export function ImportDroppedFiles(arg1:Array<string>):Promise<string>;
+
+export function ImportShardPGP(arg1:string):Promise<string>;
+
+export function ImportShardQR():Promise<string>;
+
+export function LoadDealerKey():Promise<string>;
+
+export function NewSetID():Promise<string>;
+
+export function OpenManifest():Promise<string>;
+
+export function PinDealerKey():Promise<string>;
+
 export function Recompose(arg1:Array<string>):Promise<string>;
 
+export function RecomposeBytes(arg1:Array<string>):Promise<string>;
+
+export function RecomposeMnemonic(arg1:Array<string>):Promise<string>;
+
+export function Reconstruction():Promise<string>;
+
+export function RegisterParticipant(arg1:string,arg2:string):Promise<string>;
+
+export function RevealCeremonyShard(arg1:number):Promise<string>;
+
 export function SaveFileDialog(arg1:Array<number>,arg2:string):Promise<void>;
 
+export function SaveRecoveredSecret(arg1:string,arg2:string):Promise<void>;
+
+export function SealShards(arg1:string,arg2:string):Promise<string>;
+
+export function SetStrictSignatures(arg1:boolean):Promise<void>;
+
 export function Split(arg1:string,arg2:number,arg3:number,arg4:string):Promise<string>;
 
+export function SplitBytes(arg1:string,arg2:number,arg3:number,arg4:string):Promise<string>;
+
+export function SplitDirectory(arg1:number,arg2:number,arg3:boolean):Promise<string>;
+
+export function SplitFile(arg1:number,arg2:number):Promise<string>;
+
+export function SplitForRecipients(arg1:string,arg2:Array<string>,arg3:number,arg4:string):Promise<string>;
+
+export function SplitMnemonic(arg1:string,arg2:number,arg3:number,arg4:string):Promise<string>;
+
+export function SplitPath(arg1:string,arg2:number,arg3:number,arg4:string):Promise<string>;
+
+export function StartCeremony(arg1:string):Promise<string>;
+
+export function StartReconstruction(arg1:number,arg2:string,arg3:number):Promise<string>;
+
+export function UnlockShard(arg1:string):Promise<string>;
+
 export function UploadFile():Promise<string>;
+
+export function VerifyShard(arg1:string):Promise<string>;
+
+export function WipeCeremony():Promise<string>;
@@ -2,18 +2,194 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AbortCeremony(arg1) {
+  return window['go']['main']['App']['AbortCeremony'](arg1);
+}
+
+export function AcknowledgeHandoff(arg1, arg2) {
+  return window['go']['main']['App']['AcknowledgeHandoff'](arg1, arg2);
+}
+
+export function AddSessionShard(arg1) {
+  return window['go']['main']['App']['AddSessionShard'](arg1);
+}
+
+export function Ceremony() {
+  return window['go']['main']['App']['Ceremony']();
+}
+
+export function CeremonySplit(arg1) {
+  return window['go']['main']['App']['CeremonySplit'](arg1);
+}
+
+export function CheckManifest(arg1, arg2) {
+  return window['go']['main']['App']['CheckManifest'](arg1, arg2);
+}
+
+export function ClearClipboard() {
+  return window['go']['main']['App']['ClearClipboard']();
+}
+
+export function CombineToFile() {
+  return window['go']['main']['App']['CombineToFile']();
+}
+
+export function ConfirmParameters(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ConfirmParameters'](arg1, arg2, arg3);
+}
+
+export function CopyToClipboard(arg1, arg2) {
+  return window['go']['main']['App']['CopyToClipboard'](arg1, arg2);
+}
+
+export function DealerKey() {
+  return window['go']['main']['App']['DealerKey']();
+}
+
+export function DecryptShard(arg1) {
+  return window['go']['main']['App']['DecryptShard'](arg1);
+}
+
+export function Encodings() {
+  return window['go']['main']['App']['Encodings']();
+}
+
+export function EndReconstruction() {
+  return window['go']['main']['App']['EndReconstruction']();
+}
+
+export function ExportManifest(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportManifest'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportPaperBackup(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportPaperBackup'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportShardPGP(arg1) {
+  return window['go']['main']['App']['ExportShardPGP'](arg1);
+}
+
+export function ExportShardQR(arg1, arg2) {
+  return window['go']['main']['App']['ExportShardQR'](arg1, arg2);
+}
+
+export function ImportDroppedFiles(arg1) {
+  return window['go']['main']['App']['ImportDroppedFiles'](arg1);
+}
+
+export function ImportShardPGP(arg1) {
+  return window['go']['main']['App']['ImportShardPGP'](arg1);
+}
+
+export function ImportShardQR() {
+  return window['go']['main']['App']['ImportShardQR']();
+}
+
+export function LoadDealerKey() {
+  return window['go']['main']['App']['LoadDealerKey']();
+}
+
+export function NewSetID() {
+  return window['go']['main']['App']['NewSetID']();
+}
+
+export function OpenManifest() {
+  return window['go']['main']['App']['OpenManifest']();
+}
+
+export function PinDealerKey() {
+  return window['go']['main']['App']['PinDealerKey']();
+}
+
 export function Recompose(arg1) {
   return window['go']['main']['App']['Recompose'](arg1);
 }
 
+export function RecomposeBytes(arg1) {
+  return window['go']['main']['App']['RecomposeBytes'](arg1);
+}
+
+export function RecomposeMnemonic(arg1) {
+  return window['go']['main']['App']['RecomposeMnemonic'](arg1);
+}
+
+export function Reconstruction() {
+  return window['go']['main']['App']['Reconstruction']();
+}
+
+export function RegisterParticipant(arg1, arg2) {
+  return window['go']['main']['App']['RegisterParticipant'](arg1, arg2);
+}
+
+export function RevealCeremonyShard(arg1) {
+  return window['go']['main']['App']['RevealCeremonyShard'](arg1);
+}
+
 export function SaveFileDialog(arg1, arg2) {
   return window['go']['main']['App']['SaveFileDialog'](arg1, arg2);
 }
 
+export function SaveRecoveredSecret(arg1, arg2) {
+  return window['go']['main']['App']['SaveRecoveredSecret'](arg1, arg2);
+}
+
+export function SealShards(arg1, arg2) {
+  return window['go']['main']['App']['SealShards'](arg1, arg2);
+}
+
+export function SetStrictSignatures(arg1) {
+  return window['go']['main']['App']['SetStrictSignatures'](arg1);
+}
+
 export function Split(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['Split'](arg1, arg2, arg3, arg4);
 }
 
+export function SplitBytes(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SplitBytes'](arg1, arg2, arg3, arg4);
+}
+
+export function SplitDirectory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SplitDirectory'](arg1, arg2, arg3);
+}
+
+export function SplitFile(arg1, arg2) {
+  return window['go']['main']['App']['SplitFile'](arg1, arg2);
+}
+
+export function SplitForRecipients(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SplitForRecipients'](arg1, arg2, arg3, arg4);
+}
+
+export function SplitMnemonic(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SplitMnemonic'](arg1, arg2, arg3, arg4);
+}
+
+export function SplitPath(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SplitPath'](arg1, arg2, arg3, arg4);
+}
+
+export function StartCeremony(arg1) {
+  return window['go']['main']['App']['StartCeremony'](arg1);
+}
+
+export function StartReconstruction(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartReconstruction'](arg1, arg2, arg3);
+}
+
+export function UnlockShard(arg1) {
+  return window['go']['main']['App']['UnlockShard'](arg1);
+}
+
 export function UploadFile() {
   return window['go']['main']['App']['UploadFile']();
 }
+
+export function VerifyShard(arg1) {
+  return window['go']['main']['App']['VerifyShard'](arg1);
+}
+
+export function WipeCeremony() {
+  return window['go']['main']['App']['WipeCeremony']();
+}
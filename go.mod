@@ -2,10 +2,18 @@ module orcrux
 
 go 1.23
 
-require github.com/wailsapp/wails/v2 v2.10.2
+require (
+	filippo.io/age v1.2.1
+	github.com/ProtonMail/go-crypto v1.1.6
+	github.com/go-pdf/fpdf v0.9.0
+	github.com/makiuchi-d/gozxing v0.1.1
+	github.com/wailsapp/wails/v2 v2.10.2
+	golang.org/x/crypto v0.33.0
+)
 
 require (
 	github.com/bep/debounce v1.2.1 // indirect
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
@@ -28,10 +36,10 @@ require (
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	github.com/wailsapp/go-webview2 v1.0.19 // indirect
 	github.com/wailsapp/mimetype v1.4.1 // indirect
-	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
 
 // replace github.com/wailsapp/wails/v2 v2.10.2 => /Users/polymorphl/go/pkg/mod
@@ -2,6 +2,11 @@ package main
 
 import (
 	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"orcrux/securemem"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -13,6 +18,28 @@ import (
 var assets embed.FS
 
 func main() {
+	// Secrets must not end up in a core file if the process crashes
+	if err := securemem.DisableCoreDumps(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not disable core dumps:", err)
+	}
+
+	// "orcrux serve" runs the HTTP API instead of the desktop app
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// "orcrux verify-transcript" checks a ceremony transcript
+	if len(os.Args) > 1 && os.Args[1] == "verify-transcript" {
+		if err := runVerifyTranscript(os.Args[2:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -29,6 +56,13 @@ func main() {
 		// todo: set bg to dark green
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 27, A: 1},
 		OnStartup:        app.startup,
+		OnBeforeClose:    app.beforeClose,
+		// Shard files dropped on the window are imported by the Bind tab;
+		// the webview must not navigate to them
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop:     true,
+			DisableWebViewDrop: true,
+		},
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// manifestVersion is the version of the manifest format written by newManifest.
+const manifestVersion = 1
+
+// Shard statuses reported by Manifest.Check.
+const (
+	shardOK        = "ok"        // The shard is listed in the manifest
+	shardUnknown   = "unknown"   // The x-coordinate is not part of the set
+	shardMismatch  = "mismatch"  // The x-coordinate is listed with a different fingerprint
+	shardDuplicate = "duplicate" // Another shard with the same x-coordinate was already checked
+	shardInvalid   = "invalid"   // The shard could not be parsed
+)
+
+// ErrManifestDigest is returned when a manifest does not match its digest,
+// because it was damaged or edited carelessly after it was written.
+var ErrManifestDigest = errors.New("manifest digest does not match its contents")
+
+// Manifest records who received which shard of a set, without any shard
+// contents: each shard is identified by its x-coordinate and a SHA-256
+// fingerprint of its decoded data.
+type Manifest struct {
+	Version   int             `json:"version"`
+	SetID     string          `json:"setId"`
+	Shares    int             `json:"shares"`    // Total number of shards (n)
+	Threshold int             `json:"threshold"` // Shards needed to reconstruct (t)
+	CreatedAt time.Time       `json:"createdAt"`
+	Shards    []ManifestShard `json:"shards"`
+
+	// Digest is the hex SHA-256 of the manifest serialised with an empty
+	// digest and signature. It is unkeyed, so it only catches accidental
+	// damage: anyone editing the manifest can recompute it.
+	Digest string `json:"digest"`
+
+	// Signature is the base64url Ed25519 signature of the digest by the
+	// dealer key, if one was loaded when the manifest was written. It is what
+	// protects the manifest against deliberate edits.
+	Signature string `json:"signature,omitempty"`
+}
+
+// ManifestShard is the ledger entry of one shard.
+type ManifestShard struct {
+	X         int    `json:"x"`
+	Custodian string `json:"custodian"`
+	SHA256    string `json:"sha256"`
+}
+
+// ShardCheck is the result of checking one incoming shard against a manifest.
+type ShardCheck struct {
+	Index     int    `json:"index"` // Position of the shard in the input
+	X         int    `json:"x,omitempty"`
+	Custodian string `json:"custodian,omitempty"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ManifestCheck is the result of checking a set of shards against a manifest.
+type ManifestCheck struct {
+	SetID     string       `json:"setId"`
+	Threshold int          `json:"threshold"`
+	Matched   int          `json:"matched"` // Number of distinct shards listed in the manifest
+	Ready     bool         `json:"ready"`   // Enough listed shards and no rejected ones
+	Shards    []ShardCheck `json:"shards"`
+}
+
+// shardFingerprint returns the hex SHA-256 of a parsed share in its canonical
+// hex form, so the fingerprint does not depend on how the shard was typed or
+// on its signature.
+//
+// A single share is uniformly random, as the coefficients of the split are,
+// so its fingerprint reveals nothing about the secret, however short.
+func shardFingerprint(share shamir.Share) string {
+	sum := sha256.Sum256([]byte(share.Format(shamir.Hex)))
+	return hex.EncodeToString(sum[:])
+}
+
+// newManifest builds and seals the manifest of a freshly split set of shards.
+// Labels name the custodian of each shard; empty labels get numbered defaults.
+// setID is the ID of the split, shared with its paper backup.
+func newManifest(setID string, shards []string, labels []string, threshold int) (*Manifest, error) {
+	// The paper backup rules apply: a valid set ID, at least 2 shards, a
+	// valid threshold and one label per shard
+	backup := paperBackup{SetID: setID, Threshold: threshold, Shards: shards, Labels: labels}
+	if err := backup.validate(); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:   manifestVersion,
+		SetID:     setID,
+		Shares:    len(shards),
+		Threshold: threshold,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	seen := make(map[byte]bool)
+	for i, text := range shards {
+		text, _, err := splitShardSignature(text)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i+1, err)
+		}
+		share, err := shamir.ParseShare(text)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i+1, err)
+		}
+		if seen[share.X] {
+			return nil, fmt.Errorf("shard %d: %w", i+1, shamir.ErrDuplicateShard)
+		}
+		seen[share.X] = true
+		m.Shards = append(m.Shards, ManifestShard{
+			X:         int(share.X),
+			Custodian: backup.label(i),
+			SHA256:    shardFingerprint(share),
+		})
+	}
+	if err := m.seal(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// digest computes the digest of the manifest, ignoring the Digest and
+// Signature fields.
+func (m Manifest) digest() (string, error) {
+	m.Digest, m.Signature = "", ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// seal sets the Digest field from the other fields.
+func (m *Manifest) seal() error {
+	digest, err := m.digest()
+	if err != nil {
+		return err
+	}
+	m.Digest = digest
+	return nil
+}
+
+// parseManifest decodes a manifest and checks its version, digest and
+// consistency.
+func parseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	digest, err := m.digest()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(digest, m.Digest) {
+		return nil, ErrManifestDigest
+	}
+	if len(m.Shards) != m.Shares || m.Threshold < 2 || m.Threshold > m.Shares {
+		return nil, errors.New("invalid manifest: inconsistent shard counts")
+	}
+	return &m, nil
+}
+
+// Check compares incoming shards with the manifest. Every non-blank shard
+// gets a status; the set is ready when at least Threshold distinct listed
+// shards were given and none was rejected.
+func (m *Manifest) Check(shards []string) ManifestCheck {
+	entries := make(map[int]ManifestShard, len(m.Shards))
+	for _, s := range m.Shards {
+		entries[s.X] = s
+	}
+
+	result := ManifestCheck{SetID: m.SetID, Threshold: m.Threshold, Shards: []ShardCheck{}}
+	rejected := false
+	seen := make(map[int]bool)
+	for i, text := range shards {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		check := ShardCheck{Index: i}
+		text, _, err := splitShardSignature(text)
+		var share shamir.Share
+		if err == nil {
+			share, err = shamir.ParseLegacyShare(text)
+		}
+		switch {
+		case err != nil:
+			check.Status, check.Reason = shardInvalid, err.Error()
+		default:
+			check.X = int(share.X)
+			entry, listed := entries[check.X]
+			switch {
+			case !listed:
+				check.Status = shardUnknown
+			case entry.SHA256 != shardFingerprint(share):
+				check.Status, check.Custodian = shardMismatch, entry.Custodian
+			case seen[check.X]:
+				check.Status, check.Custodian = shardDuplicate, entry.Custodian
+			default:
+				check.Status, check.Custodian = shardOK, entry.Custodian
+				seen[check.X] = true
+				result.Matched++
+			}
+		}
+		if check.Status != shardOK && check.Status != shardDuplicate {
+			rejected = true
+		}
+		result.Shards = append(result.Shards, check)
+	}
+	result.Ready = !rejected && result.Matched >= m.Threshold
+	return result
+}
+
+// ExportManifest writes the custody manifest of a set of shards to a file
+// chosen by the user.
+//
+// The manifest holds the set ID of the split, the number of shards and the threshold,
+// the creation time, and the custodian label and SHA-256 fingerprint of each
+// shard. It never contains shard data, so it can be kept with the secret's
+// owner or an auditor. It is signed when a dealer key is loaded.
+//
+// Parameters:
+//   - shards: The shards of the set, as returned by Split
+//   - labels: One custodian label per shard, or empty for numbered defaults
+//   - shardsNeeded: The threshold used when the shards were created
+//   - setID: The set ID of the split, as returned by NewSetID
+//
+// Returns:
+//   - An error if a shard cannot be parsed, validation fails or the file
+//     cannot be written. Cancelling the dialog is not an error.
+func (a *App) ExportManifest(shards []string, labels []string, shardsNeeded int, setID string) error {
+	m, err := newManifest(setID, shards, labels, shardsNeeded)
+	if err != nil {
+		return err
+	}
+	key := a.signingKey()
+	defer securemem.Wipe(key)
+	if key != nil {
+		signManifest(key, m)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fd := runtime.SaveDialogOptions{
+		Title:           "Save shard manifest",
+		DefaultFilename: "orcrux-" + m.SetID + ".manifest.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Manifests", Pattern: "*.json"},
+		},
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, fd)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // User cancelled the dialog
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// OpenManifest lets the user pick a manifest file and returns it once its
+// digest, and its signature if a dealer key is pinned, have been verified.
+// Only the signature shows that the manifest was not edited.
+//
+// Returns:
+//   - A JSON response with the manifest, null data if the dialog was
+//     cancelled, or an error if the file is not a valid manifest or its
+//     signature is rejected
+func (a *App) OpenManifest() string {
+	fd := runtime.OpenDialogOptions{
+		Title: "Select a shard manifest",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Manifests", Pattern: "*.json"},
+		},
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, fd)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	if path == "" {
+		return newResponse(nil, nil)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	m, err := a.loadManifest(data)
+	return newResponse(m, err)
+}
+
+// CheckManifest checks shards against a manifest before reconstruction.
+//
+// Parameters:
+//   - manifest: The manifest JSON, as returned by OpenManifest
+//   - shards: The shards entered so far
+//
+// Returns:
+//   - A JSON response with the status of each shard and whether the set is
+//     ready to recompose, or an error if the manifest is invalid
+func (a *App) CheckManifest(manifest string, shards []string) string {
+	m, err := a.loadManifest([]byte(manifest))
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	return newResponse(m.Check(shards), nil)
+}
+
+// loadManifest parses a manifest and checks its signature with the App settings.
+func (a *App) loadManifest(data []byte) (*Manifest, error) {
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	pub, strict := a.signatureSettings()
+	if err := checkManifestSignature(pub, strict, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
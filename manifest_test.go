@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"orcrux/shamir"
+	"strings"
+	"testing"
+)
+
+func splitShards(t *testing.T, secret string, n, threshold int, output string) []string {
+	t.Helper()
+	out, err := shamir.Split([]byte(secret), n, threshold, output)
+	if err != nil {
+		t.Fatalf("shamir.Split() error = %v", err)
+	}
+	return strings.Split(strings.TrimSpace(out), "\n")
+}
+
+func TestNewManifest(t *testing.T) {
+	shards := splitShards(t, "correct horse battery staple", 3, 2, "base64")
+	m, err := newManifest(testSetID, shards, []string{"Alice", "", "Carol"}, 2)
+	if err != nil {
+		t.Fatalf("newManifest() error = %v", err)
+	}
+	if m.Shares != 3 || m.Threshold != 2 || m.SetID != testSetID || m.CreatedAt.IsZero() {
+		t.Errorf("newManifest() = %+v", m)
+	}
+	if got := m.Shards[1].Custodian; got != "Custodian 2" {
+		t.Errorf("default custodian = %q, want %q", got, "Custodian 2")
+	}
+
+	data, _ := json.Marshal(m)
+	for _, shard := range shards {
+		if strings.Contains(string(data), strings.SplitN(shard, ":", 3)[2]) {
+			t.Error("manifest contains shard data")
+		}
+	}
+
+	if _, err := newManifest(testSetID, shards, []string{"Alice"}, 2); err == nil {
+		t.Error("newManifest() should reject a wrong number of labels")
+	}
+	if _, err := newManifest(testSetID, []string{shards[0], shards[0]}, nil, 2); !errors.Is(err, shamir.ErrDuplicateShard) {
+		t.Errorf("newManifest() with duplicate shards error = %v, want ErrDuplicateShard", err)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	m, err := newManifest(testSetID, splitShards(t, "secret", 3, 2, "hex"), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(m)
+
+	loaded, err := parseManifest(data)
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+	if loaded.SetID != m.SetID || !loaded.CreatedAt.Equal(m.CreatedAt) {
+		t.Errorf("parseManifest() = %+v, want %+v", loaded, m)
+	}
+
+	tampered := strings.Replace(string(data), "Custodian 1", "Mallory", 1)
+	if _, err := parseManifest([]byte(tampered)); !errors.Is(err, ErrManifestDigest) {
+		t.Errorf("parseManifest() of an edited manifest error = %v, want ErrManifestDigest", err)
+	}
+	if _, err := parseManifest([]byte("{")); err == nil {
+		t.Error("parseManifest() should reject invalid JSON")
+	}
+}
+
+func TestManifestCheck(t *testing.T) {
+	shards := splitShards(t, "secret", 3, 2, "hex")
+	m, err := newManifest(testSetID, shards, []string{"Alice", "Bob", "Carol"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same shard re-encoded as base64 still matches
+	share, _ := shamir.ParseShare(shards[1])
+	rewritten := share.Format(shamir.Base64)
+
+	check := m.Check([]string{shards[0], "", rewritten})
+	if !check.Ready || check.Matched != 2 {
+		t.Errorf("Check() = %+v, want ready with 2 matches", check)
+	}
+
+	// So does an untagged hex shard of the original format
+	if check := m.Check([]string{strings.Replace(shards[2], ":hex:", ":", 1)}); check.Shards[0].Status != shardOK {
+		t.Errorf("Check() of an untagged shard = %+v, want it matched", check.Shards[0])
+	}
+	if len(check.Shards) != 2 || check.Shards[1].Index != 2 || check.Shards[1].Custodian != "Bob" {
+		t.Errorf("Check() shards = %+v", check.Shards)
+	}
+
+	other := splitShards(t, "other", 4, 2, "hex")
+	check = m.Check([]string{shards[0], shards[1], other[2], other[3], shards[0], "garbage"})
+	want := []string{shardOK, shardOK, shardMismatch, shardUnknown, shardDuplicate, shardInvalid}
+	for i, c := range check.Shards {
+		if c.Status != want[i] {
+			t.Errorf("shard %d status = %q, want %q", i, c.Status, want[i])
+		}
+	}
+	if check.Ready {
+		t.Error("Check() should not be ready with rejected shards")
+	}
+
+	if check := m.Check(shards[:1]); check.Ready {
+		t.Error("Check() should not be ready below the threshold")
+	}
+}
+
+func TestCheckManifestResponse(t *testing.T) {
+	app := NewApp()
+	shards := splitShards(t, "secret", 2, 2, "hex")
+	m, _ := newManifest(testSetID, shards, nil, 2)
+	data, _ := json.Marshal(m)
+
+	var response Response
+	json.Unmarshal([]byte(app.CheckManifest(string(data), shards)), &response)
+	if response.Error != nil || response.Data.(map[string]interface{})["ready"] != true {
+		t.Errorf("CheckManifest() = %+v", response)
+	}
+
+	bad := strings.Replace(string(data), `"threshold":2`, `"threshold":1`, 1)
+	json.Unmarshal([]byte(app.CheckManifest(bad, shards)), &response)
+	if response.Code != "manifest_digest" {
+		t.Errorf("CheckManifest() code = %q, want manifest_digest", response.Code)
+	}
+}
+
+// TestShardFingerprintHidesSecret checks that a fingerprint cannot be matched
+// by splitting candidate secrets: the same short secret gives new shards,
+// and so new fingerprints, every time it is split.
+func TestShardFingerprintHidesSecret(t *testing.T) {
+	fingerprint := func() string {
+		share, err := shamir.ParseShare(splitShards(t, "4821", 3, 2, "hex")[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return shardFingerprint(share)
+	}
+	if fingerprint() == fingerprint() {
+		t.Error("two splits of the same secret gave the same shard fingerprint")
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"orcrux/shamir"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// paperGroupSize is the number of shard characters per transcription group.
+	paperGroupSize = 4
+
+	// paperGroupsPerLine is the number of groups printed on each numbered line.
+	paperGroupsPerLine = 10
+
+	// paperQRSize is the printed width and height of each QR code, in millimetres.
+	paperQRSize = 60.0
+
+	// paperMargin is the margin on every side of the page, in millimetres.
+	paperMargin = 20.0
+)
+
+// paperBackup describes the contents of a paper backup document.
+//
+// Each shard is printed on its own page, or pages for a long shard, together
+// with the custodian label, the set ID and the reconstruction instructions.
+// Nothing from any other shard ever appears on those pages.
+type paperBackup struct {
+	SetID     string
+	Threshold int
+	Shards    []string
+	Labels    []string
+}
+
+// validate checks that the backup can be rendered.
+func (b paperBackup) validate() error {
+	if id, err := hex.DecodeString(b.SetID); err != nil || len(id) != setIDSize {
+		return errors.New("invalid set ID")
+	}
+	if len(b.Shards) < 2 {
+		return errors.New("at least 2 shards are required")
+	}
+	for i, shard := range b.Shards {
+		if strings.TrimSpace(shard) == "" {
+			return fmt.Errorf("shard %d is empty", i+1)
+		}
+	}
+	if b.Threshold < 2 || b.Threshold > len(b.Shards) {
+		return errors.New("shardsNeeded must be in [2, shards]")
+	}
+	if len(b.Labels) != 0 && len(b.Labels) != len(b.Shards) {
+		return fmt.Errorf("expected %d custodian labels, got %d", len(b.Shards), len(b.Labels))
+	}
+	return nil
+}
+
+// label returns the custodian label for shard i, falling back to a numbered default.
+func (b paperBackup) label(i int) string {
+	if i < len(b.Labels) && strings.TrimSpace(b.Labels[i]) != "" {
+		return strings.TrimSpace(b.Labels[i])
+	}
+	return fmt.Sprintf("Custodian %d", i+1)
+}
+
+// setIDSize is the length of a set ID in bytes.
+const setIDSize = 8
+
+// newSetID returns a random identifier used to tell shard sets apart.
+func newSetID() (string, error) {
+	id := make([]byte, setIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// groupShardText splits a shard into numbered lines of space-separated
+// character groups, which are easier to read and copy by hand.
+func groupShardText(shard string) []string {
+	return numberLines(groupCharacters(shard))
+}
+
+// groupCharacters cuts text into lines of space-separated character groups.
+func groupCharacters(text string) []string {
+	var groups []string
+	for i := 0; i < len(text); i += paperGroupSize {
+		groups = append(groups, text[i:min(i+paperGroupSize, len(text))])
+	}
+
+	var lines []string
+	for i := 0; i < len(groups); i += paperGroupsPerLine {
+		end := min(i+paperGroupsPerLine, len(groups))
+		lines = append(lines, strings.Join(groups[i:end], " "))
+	}
+	return lines
+}
+
+// numberLines prefixes each line with its two-digit line number.
+func numberLines(lines []string) []string {
+	numbered := make([]string, len(lines))
+	for i, line := range lines {
+		numbered[i] = fmt.Sprintf("%02d  %s", i+1, line)
+	}
+	return numbered
+}
+
+// isGroupedShard reports whether a shard uses the grouped encoding, whose
+// spaces and "/" separators are part of the share data.
+func isGroupedShard(shard string) bool {
+	fields := strings.SplitN(strings.TrimSpace(shard), ":", 3)
+	if len(fields) != 3 {
+		return false
+	}
+	name, _, _ := strings.Cut(fields[1], "+")
+	return strings.EqualFold(name, shamir.Grouped.Name())
+}
+
+// groupedShardText splits a grouped shard into numbered lines at its own "/"
+// separators, keeping them, so the lines typed one after the other decode as
+// printed. A signature follows on lines of character groups.
+func groupedShardText(shard string) []string {
+	share, sig, signed := strings.Cut(strings.TrimSpace(shard), shardSignatureSep)
+	parts := strings.Split(share, "/")
+	lines := make([]string, 0, len(parts))
+	for i, part := range parts {
+		line := strings.TrimSpace(part)
+		if i < len(parts)-1 {
+			line += " /"
+		}
+		lines = append(lines, line)
+	}
+	if signed {
+		lines = append(lines, groupCharacters(shardSignatureSep+strings.TrimSpace(sig))...)
+	}
+	return numberLines(lines)
+}
+
+// shardChecksum returns a short, grouped SHA-256 fingerprint of a shard that
+// a custodian can compare after transcribing it.
+func shardChecksum(shard string) string {
+	sum := sha256.Sum256([]byte(shard))
+	digest := hex.EncodeToString(sum[:8])
+	groups := make([]string, 0, len(digest)/paperGroupSize)
+	for i := 0; i < len(digest); i += paperGroupSize {
+		groups = append(groups, digest[i:i+paperGroupSize])
+	}
+	return strings.Join(groups, " ")
+}
+
+// renderPaperBackup writes the paper backup as a PDF document. Each shard
+// starts on a new page and continues on further pages if it does not fit.
+func renderPaperBackup(w io.Writer, b paperBackup) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Orcrux paper backup", true)
+	pdf.SetCreator("orcrux", true)
+	pdf.SetMargins(paperMargin, paperMargin, paperMargin)
+	pdf.SetAutoPageBreak(true, paperMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	_, pageHeight := pdf.GetPageSize()
+
+	// A long shard runs over several pages; each one after the first names
+	// the custodian so a loose page can still be told apart
+	shardPage, continued := 0, ""
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > shardPage {
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.CellFormat(0, 5, continued, "", 1, "L", false, 0, "")
+			pdf.Ln(2)
+		}
+	})
+
+	for i, raw := range b.Shards {
+		shard := strings.TrimSpace(raw)
+		shardPage = pdf.PageNo() + 1
+		continued = tr(fmt.Sprintf("Custodian: %s, shard %d of %d (continued)", b.label(i), i+1, len(b.Shards)))
+		pdf.AddPage()
+
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(0, 10, "Orcrux paper backup", "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 6, tr("Custodian: "+b.label(i)), "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, 6, "Set ID: "+b.SetID, "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("Shard %d of %d", i+1, len(b.Shards)), "", 1, "L", false, 0, "")
+		pdf.Ln(3)
+		lines, transcribe := groupShardText(shard), "When transcribing, type the shard without spaces or line numbers."
+		if isGroupedShard(shard) {
+			lines = groupedShardText(shard)
+			transcribe = "When transcribing, type the lines one after the other on a single line, without the line numbers. " +
+				"Keep the spaces and the / separators of the shard; type a signature after the # without spaces."
+		}
+		pdf.MultiCell(0, 5, fmt.Sprintf(
+			"Any %d of the %d shards in set %s are needed to recover the secret. "+
+				"Keep this page private and never store it together with another shard. %s",
+			b.Threshold, len(b.Shards), b.SetID, transcribe), "", "L", false)
+		pdf.Ln(4)
+
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 6, "Shard", "", 1, "L", false, 0, "")
+		pdf.SetFont("Courier", "", 11)
+		for _, line := range lines {
+			pdf.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+		}
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 6, "Checksum (SHA-256): "+shardChecksum(shard), "", 1, "L", false, 0, "")
+		pdf.Ln(4)
+
+		payloads := qrPayloads(shard, qrChunkSize)
+		y := pdf.GetY()
+		for j, payload := range payloads {
+			img, err := renderQRPNG(payload)
+			if err != nil {
+				return err
+			}
+			name := fmt.Sprintf("qr-%d-%d", i, j)
+			pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(img))
+
+			if j%2 == 0 {
+				if j > 0 {
+					y += paperQRSize + 6
+				}
+				// Images do not trigger the automatic page break
+				if y+paperQRSize > pageHeight-paperMargin {
+					pdf.AddPage()
+					y = pdf.GetY()
+				}
+			}
+			x := paperMargin + float64(j%2)*(paperQRSize+10)
+			pdf.ImageOptions(name, x, y, paperQRSize, paperQRSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		}
+		if len(payloads) > 1 {
+			pdf.SetY(y + paperQRSize + 2)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(0, 5, fmt.Sprintf("Scan all %d QR codes to recover this shard.", len(payloads)), "", 1, "L", false, 0, "")
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// ExportPaperBackup generates a printable PDF with one page per shard and saves it to disk.
+//
+// Every page shows the custodian label, the set ID, the threshold
+// instructions, the shard text in grouped monospace characters, its QR code
+// and a checksum line. A shard too long for one page continues on pages
+// headed with its custodian. A page never contains anything from another
+// shard, so the pages can be printed and handed to custodians individually.
+//
+// Parameters:
+//   - shards: The shards to print, as returned by Split
+//   - labels: One custodian label per shard, or empty for numbered defaults
+//   - shardsNeeded: The threshold used when the shards were created
+//   - setID: The set ID of the split, as returned by NewSetID
+//
+// Returns:
+//   - An error if validation fails, the PDF cannot be generated or the file
+//     cannot be written. Cancelling the dialog is not an error.
+func (a *App) ExportPaperBackup(shards []string, labels []string, shardsNeeded int, setID string) error {
+	backup := paperBackup{SetID: setID, Threshold: shardsNeeded, Shards: shards, Labels: labels}
+	if err := backup.validate(); err != nil {
+		return err
+	}
+
+	fd := runtime.SaveDialogOptions{
+		Title:           "Save paper backup",
+		DefaultFilename: "orcrux-" + setID + ".pdf",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PDF documents", Pattern: "*.pdf"},
+		},
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, fd)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // User cancelled the dialog
+	}
+
+	var buf bytes.Buffer
+	if err := renderPaperBackup(&buf, backup); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// NewSetID returns a new set ID for a split. The frontend asks for one after
+// each split and passes it to ExportPaperBackup and ExportManifest, so the
+// paper backup and the manifest of the same shards carry the same ID.
+//
+// Returns:
+//   - A JSON Response whose data is the set ID, 16 hex characters
+func (a *App) NewSetID() string {
+	setID, err := newSetID()
+	return newResponse(setID, err)
+}
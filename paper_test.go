@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"orcrux/shamir"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testSetID is the set ID of the shards in tests.
+const testSetID = "0123456789abcdef"
+
+// pdfPageStreams returns the decompressed content streams of a PDF produced by fpdf.
+func pdfPageStreams(t *testing.T, data []byte) []string {
+	t.Helper()
+	re := regexp.MustCompile(`(?s)/Filter /FlateDecode /Length \d+>>\nstream\n(.*?)\nendstream`)
+	var streams []string
+	for _, m := range re.FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue // image data or fonts
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			continue
+		}
+		if bytes.Contains(content, []byte("BT ")) {
+			streams = append(streams, string(content))
+		}
+	}
+	return streams
+}
+
+func TestRenderPaperBackup(t *testing.T) {
+	shards := []string{
+		"01:1111aaaa2222bbbb",
+		"02:3333cccc4444dddd",
+		"03:5555eeee6666ffff",
+	}
+	backup := paperBackup{SetID: testSetID, Threshold: 2, Shards: shards, Labels: []string{"Alice", "Bob", ""}}
+
+	var buf bytes.Buffer
+	if err := renderPaperBackup(&buf, backup); err != nil {
+		t.Fatalf("renderPaperBackup() error = %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatal("renderPaperBackup() did not produce a PDF document")
+	}
+
+	pages := pdfPageStreams(t, buf.Bytes())
+	if len(pages) != len(shards) {
+		t.Fatalf("got %d pages, want %d", len(pages), len(shards))
+	}
+
+	labels := []string{"Alice", "Bob", "Custodian 3"}
+	for i, page := range pages {
+		if !strings.Contains(page, labels[i]) {
+			t.Errorf("page %d is missing custodian label %q", i+1, labels[i])
+		}
+		if !strings.Contains(page, backup.SetID) {
+			t.Errorf("page %d is missing the set ID", i+1)
+		}
+		if !strings.Contains(page, shardChecksum(shards[i])) {
+			t.Errorf("page %d is missing its checksum line", i+1)
+		}
+		for j, shard := range shards {
+			hasShard := strings.Contains(page, groupShardText(shard)[0])
+			if i == j && !hasShard {
+				t.Errorf("page %d is missing its own shard", i+1)
+			}
+			if i != j && hasShard {
+				t.Errorf("page %d leaks shard %d", i+1, j+1)
+			}
+		}
+	}
+}
+
+func TestRenderPaperBackupLongShard(t *testing.T) {
+	long := "01:" + strings.Repeat("ab", 4000)
+	backup := paperBackup{SetID: testSetID, Threshold: 2, Shards: []string{long, "02:3333cccc4444dddd"}, Labels: []string{"Alice", "Bob"}}
+
+	var buf bytes.Buffer
+	if err := renderPaperBackup(&buf, backup); err != nil {
+		t.Fatalf("renderPaperBackup() error = %v", err)
+	}
+	// The grouped text fills five pages. The nine QR codes take five rows,
+	// at most three to a page, followed by Bob's page.
+	pages := pdfPageStreams(t, buf.Bytes())
+	if len(pages) != 8 {
+		t.Fatalf("got %d pages, want 8", len(pages))
+	}
+	for i, page := range pages[1:7] {
+		if !strings.Contains(page, "Custodian: Alice, shard 1 of 2") {
+			t.Errorf("page %d does not name its custodian", i+2)
+		}
+	}
+
+	// Image placements are "w 0 0 h x y cm" in points, y from the bottom
+	image := regexp.MustCompile(`[\d.]+ 0 0 [\d.]+ [\d.]+ (-?[\d.]+) cm /I`)
+	bottom := paperMargin * 72 / 25.4
+	images := 0
+	for i, page := range pages {
+		for _, m := range image.FindAllStringSubmatch(page, -1) {
+			images++
+			if y, _ := strconv.ParseFloat(m[1], 64); y < bottom-0.01 {
+				t.Errorf("page %d has a QR code %.1f pt from the bottom, inside the margin", i+1, y)
+			}
+		}
+	}
+	if want := len(qrPayloads(long, qrChunkSize)) + 1; images != want {
+		t.Errorf("got %d QR codes, want %d", images, want)
+	}
+}
+
+func TestRenderPaperBackupValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		backup paperBackup
+	}{
+		{name: "single shard", backup: paperBackup{SetID: testSetID, Threshold: 2, Shards: []string{"01:aa"}}},
+		{name: "empty shard", backup: paperBackup{SetID: testSetID, Threshold: 2, Shards: []string{"01:aa", " "}}},
+		{name: "threshold too small", backup: paperBackup{SetID: testSetID, Threshold: 1, Shards: []string{"01:aa", "02:bb"}}},
+		{name: "threshold larger than shards", backup: paperBackup{SetID: testSetID, Threshold: 3, Shards: []string{"01:aa", "02:bb"}}},
+		{name: "label count mismatch", backup: paperBackup{SetID: testSetID, Threshold: 2, Shards: []string{"01:aa", "02:bb"}, Labels: []string{"Alice"}}},
+		{name: "missing set ID", backup: paperBackup{Threshold: 2, Shards: []string{"01:aa", "02:bb"}}},
+		{name: "invalid set ID", backup: paperBackup{SetID: "../../etc/passwd", Threshold: 2, Shards: []string{"01:aa", "02:bb"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := renderPaperBackup(io.Discard, tt.backup); err == nil {
+				t.Error("renderPaperBackup() should have returned an error")
+			}
+		})
+	}
+}
+
+func TestGroupShardText(t *testing.T) {
+	lines := groupShardText("01:" + strings.Repeat("a", 45))
+	if len(lines) != 2 {
+		t.Fatalf("groupShardText() returned %d lines, want 2", len(lines))
+	}
+	if lines[0] != "01  01:a aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa" {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[1] != "02  aaaa aaaa" {
+		t.Errorf("unexpected second line: %q", lines[1])
+	}
+}
+
+func TestRenderPaperBackupGroupedShard(t *testing.T) {
+	key, _ := newDealerKey(t)
+	signed, err := signShards(key, strings.Join(splitShards(t, "correct horse battery staple", 2, 2, "grouped"), "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := strings.Split(signed, "\n")
+
+	var buf bytes.Buffer
+	if err := renderPaperBackup(&buf, paperBackup{SetID: testSetID, Threshold: 2, Shards: shards}); err != nil {
+		t.Fatalf("renderPaperBackup() error = %v", err)
+	}
+
+	// Transcribe each page as the instructions say: the numbered lines one
+	// after the other, then the signature without spaces
+	line := regexp.MustCompile(`\(\d\d  ([^)]*)\)Tj`)
+	var transcribed []string
+	for i, page := range pdfPageStreams(t, buf.Bytes()) {
+		if !strings.Contains(page, "Keep the spaces and the / separators") {
+			t.Errorf("page %d does not say to keep the separators", i+1)
+		}
+		var share, sig []string
+		for _, m := range line.FindAllStringSubmatch(page, -1) {
+			if strings.HasPrefix(m[1], shardSignatureSep) || sig != nil {
+				sig = append(sig, strings.ReplaceAll(m[1], " ", ""))
+				continue
+			}
+			share = append(share, m[1])
+		}
+		transcribed = append(transcribed, strings.Join(share, " ")+strings.Join(sig, ""))
+	}
+
+	verified, err := checkShardSignatures(key.Public().(ed25519.PublicKey), true, transcribed)
+	if err != nil {
+		t.Fatalf("checkShardSignatures() error = %v, transcribed %q", err, transcribed)
+	}
+	got, err := shamir.Recompose(verified)
+	if err != nil || string(got) != "correct horse battery staple" {
+		t.Errorf("Recompose() = %q, %v", got, err)
+	}
+}
+
+func TestGroupedShardText(t *testing.T) {
+	lines := groupedShardText("01:grouped:cpzzw rhufg cp4nh y4dxk m1 / xrj 4w")
+	want := []string{"01  01:grouped:cpzzw rhufg cp4nh y4dxk m1 /", "02  xrj 4w"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("groupedShardText() = %q, want %q", lines, want)
+	}
+	if !isGroupedShard("01:grouped+pad:xrj 4w") || isGroupedShard("01:hex:abcd") || isGroupedShard("01:abcd") {
+		t.Error("isGroupedShard() misdetects the encoding")
+	}
+}
+
+func TestShardChecksum(t *testing.T) {
+	sum := shardChecksum("01:abcd")
+	if len(strings.ReplaceAll(sum, " ", "")) != 16 {
+		t.Errorf("shardChecksum() = %q, want 16 hex digits", sum)
+	}
+	if sum == shardChecksum("01:abce") {
+		t.Error("shardChecksum() should differ for different shards")
+	}
+}
+
+func TestNewSetID(t *testing.T) {
+	a, err := newSetID()
+	if err != nil {
+		t.Fatalf("newSetID() error = %v", err)
+	}
+	b, _ := newSetID()
+	if len(a) != 16 || a == b {
+		t.Errorf("newSetID() returned %q and %q, want distinct 16-digit IDs", a, b)
+	}
+
+	var response Response
+	if err := json.Unmarshal([]byte(NewApp().NewSetID()), &response); err != nil || response.Error != nil {
+		t.Fatalf("NewSetID() = %+v, %v", response, err)
+	}
+	if id, ok := response.Data.(string); !ok || (paperBackup{SetID: id, Threshold: 2, Shards: []string{"01:aa", "02:bb"}}).validate() != nil {
+		t.Errorf("NewSetID() data = %v, want a valid set ID", response.Data)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// pgpMessageType is the armor block type of an OpenPGP message.
+const pgpMessageType = "PGP MESSAGE"
+
+// readPGPRecipient reads an armored keyring and returns the single key that
+// shards will be encrypted to. Keyrings holding several usable keys are
+// refused, so a shard is never readable by more than one custodian.
+func readPGPRecipient(keyring io.Reader) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(keyring)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public keyring: %w", err)
+	}
+
+	var usable []*openpgp.Entity
+	for _, e := range entities {
+		if _, ok := e.EncryptionKey(time.Now()); ok {
+			usable = append(usable, e)
+		}
+	}
+	if len(usable) != 1 {
+		return nil, fmt.Errorf("keyring must contain exactly one public key that can encrypt, found %d", len(usable))
+	}
+	return usable[0], nil
+}
+
+// encryptShardPGP wraps a shard in an ASCII-armored OpenPGP message
+// encrypted to recipient, which custodians can open with `gpg --decrypt`.
+func encryptShardPGP(shard string, recipient *openpgp.Entity) (string, error) {
+	var buf bytes.Buffer
+	aw, err := armor.Encode(&buf, pgpMessageType, nil)
+	if err != nil {
+		return "", err
+	}
+	w, err := openpgp.Encrypt(aw, []*openpgp.Entity{recipient}, nil, &openpgp.FileHints{FileName: "_CONSOLE"}, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, shard+"\n"); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := aw.Close(); err != nil {
+		return "", err
+	}
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// isPGPEncrypted reports whether data is an OpenPGP message, armored or
+// binary, rather than the plain text of a shard already decrypted by gpg.
+func isPGPEncrypted(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("-----BEGIN "+pgpMessageType+"-----")) {
+		return true
+	}
+	// Binary messages start with a packet tag, which always has the top bit set
+	return len(trimmed) > 0 && trimmed[0]&0x80 != 0
+}
+
+// decryptShardPGP decrypts an OpenPGP message holding a shard with the
+// private key read from keyring, unlocking it with passphrase if needed.
+func decryptShardPGP(message []byte, keyring io.Reader, passphrase string) (string, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(keyring)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key file: %w", err)
+	}
+	for _, e := range entities {
+		if e.PrivateKey == nil {
+			return "", errors.New("key file does not contain a private key")
+		}
+		if passphrase == "" {
+			continue
+		}
+		if err := e.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return "", errors.New("wrong passphrase for the private key")
+		}
+	}
+
+	var body io.Reader = bytes.NewReader(message)
+	if bytes.HasPrefix(bytes.TrimSpace(message), []byte("-----BEGIN")) {
+		block, err := armor.Decode(bytes.NewReader(bytes.TrimSpace(message)))
+		if err != nil {
+			return "", fmt.Errorf("invalid PGP message: %w", err)
+		}
+		if block.Type != pgpMessageType {
+			return "", fmt.Errorf("expected a PGP message, got %q", block.Type)
+		}
+		body = block.Body
+	}
+
+	md, err := openpgp.ReadMessage(body, entities, nil, nil)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrKeyIncorrect) {
+			return "", errors.New("this shard was not encrypted to the selected private key, or the key needs its passphrase")
+		}
+		return "", fmt.Errorf("failed to decrypt shard: %w", err)
+	}
+	plain, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt shard: %w", err)
+	}
+	return strings.TrimSpace(string(plain)), nil
+}
+
+// ExportShardPGP encrypts a shard to a custodian's OpenPGP key and saves it.
+//
+// This function presents a native file picker to select an armored public
+// keyring (as produced by `gpg --armor --export`), then a save dialog for the
+// encrypted shard. The keyring must hold exactly one key that can encrypt.
+// The custodian decrypts the file with `gpg --decrypt`.
+//
+// Parameters:
+//   - shard: The shard to encrypt, as returned by Split
+//
+// Returns:
+//   - An error if the shard is empty, the keyring is unusable, or the file
+//     cannot be written. Cancelling a dialog is not an error.
+func (a *App) ExportShardPGP(shard string) error {
+	shard = strings.TrimSpace(shard)
+	if shard == "" {
+		return errors.New("shard is required")
+	}
+
+	keyPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select the custodian's public key",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Armored keys", Pattern: "*.asc;*.pub;*.txt"},
+		},
+	})
+	if err != nil || keyPath == "" {
+		return err
+	}
+	keyFile, err := os.Open(keyPath)
+	if err != nil {
+		return err
+	}
+	defer keyFile.Close()
+
+	recipient, err := readPGPRecipient(keyFile)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptShardPGP(shard, recipient)
+	if err != nil {
+		return err
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save encrypted shard",
+		DefaultFilename: "shard.asc",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PGP messages", Pattern: "*.asc"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // User cancelled the dialog
+	}
+	return os.WriteFile(path, []byte(encrypted), 0644)
+}
+
+// ImportShardPGP opens a file dialog to select a shard received as an OpenPGP message.
+//
+// A file already decrypted with gpg is returned as is. A still-encrypted
+// message, armored or binary, is decrypted after a second file picker
+// selects the custodian's armored private key (as produced by
+// `gpg --armor --export-secret-keys`).
+//
+// Parameters:
+//   - passphrase: The passphrase protecting the private key, or "" if none
+//
+// Returns:
+//   - The decrypted shard string
+//   - An empty string if no file was selected (user cancelled a dialog)
+//   - An error if the message or key cannot be read, or decryption fails
+func (a *App) ImportShardPGP(passphrase string) (string, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select the encrypted shard",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PGP messages", Pattern: "*.asc;*.gpg;*.pgp;*.txt"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	message, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !isPGPEncrypted(message) {
+		return strings.TrimSpace(string(message)), nil
+	}
+
+	keyPath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select your private key"})
+	if err != nil || keyPath == "" {
+		return "", err
+	}
+	keyFile, err := os.Open(keyPath)
+	if err != nil {
+		return "", err
+	}
+	defer keyFile.Close()
+
+	return decryptShardPGP(message, keyFile, passphrase)
+}
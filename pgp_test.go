@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// newTestPGPKey generates a key pair and returns its armored public and
+// private keyrings. The private key is protected when passphrase is set.
+func newTestPGPKey(t *testing.T, name, passphrase string) (public, private string) {
+	t.Helper()
+	e := newTestPGPEntity(t, name)
+	public = armorPGPPublicKeys(t, e)
+
+	if passphrase != "" {
+		if err := e.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var priv bytes.Buffer
+	w, err := armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return public, priv.String()
+}
+
+func newTestPGPEntity(t *testing.T, name string) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// armorPGPPublicKeys exports public keys as one armored keyring, like
+// `gpg --armor --export` does for several keys.
+func armorPGPPublicKeys(t *testing.T, entities ...*openpgp.Entity) string {
+	t.Helper()
+	var pub bytes.Buffer
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entities {
+		if err := e.Serialize(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	return pub.String()
+}
+
+func TestPGPShardRoundTrip(t *testing.T) {
+	public, private := newTestPGPKey(t, "alice", "")
+	shard := "01:deadbeef"
+
+	recipient, err := readPGPRecipient(strings.NewReader(public))
+	if err != nil {
+		t.Fatalf("readPGPRecipient() error = %v", err)
+	}
+	encrypted, err := encryptShardPGP(shard, recipient)
+	if err != nil {
+		t.Fatalf("encryptShardPGP() error = %v", err)
+	}
+	if !strings.HasPrefix(encrypted, "-----BEGIN PGP MESSAGE-----") {
+		t.Fatalf("encryptShardPGP() is not an armored message:\n%s", encrypted)
+	}
+	if !isPGPEncrypted([]byte(encrypted)) {
+		t.Error("isPGPEncrypted() = false for an armored message")
+	}
+	if strings.Contains(encrypted, "deadbeef") {
+		t.Error("encrypted shard leaks plaintext")
+	}
+
+	got, err := decryptShardPGP([]byte(encrypted), strings.NewReader(private), "")
+	if err != nil {
+		t.Fatalf("decryptShardPGP() error = %v", err)
+	}
+	if got != shard {
+		t.Errorf("decryptShardPGP() = %q, want %q", got, shard)
+	}
+}
+
+func TestDecryptShardPGPPassphrase(t *testing.T) {
+	public, private := newTestPGPKey(t, "bob", "hunter2")
+
+	recipient, err := readPGPRecipient(strings.NewReader(public))
+	if err != nil {
+		t.Fatalf("readPGPRecipient() error = %v", err)
+	}
+	encrypted, err := encryptShardPGP("02:cafe", recipient)
+	if err != nil {
+		t.Fatalf("encryptShardPGP() error = %v", err)
+	}
+
+	if _, err := decryptShardPGP([]byte(encrypted), strings.NewReader(private), ""); err == nil {
+		t.Error("decryptShardPGP() should fail without the key passphrase")
+	}
+	if _, err := decryptShardPGP([]byte(encrypted), strings.NewReader(private), "wrong"); err == nil {
+		t.Error("decryptShardPGP() should fail with a wrong key passphrase")
+	}
+	got, err := decryptShardPGP([]byte(encrypted), strings.NewReader(private), "hunter2")
+	if err != nil {
+		t.Fatalf("decryptShardPGP() error = %v", err)
+	}
+	if got != "02:cafe" {
+		t.Errorf("decryptShardPGP() = %q, want %q", got, "02:cafe")
+	}
+}
+
+func TestDecryptShardPGPWrongKey(t *testing.T) {
+	public, _ := newTestPGPKey(t, "alice", "")
+	_, otherPrivate := newTestPGPKey(t, "mallory", "")
+
+	recipient, err := readPGPRecipient(strings.NewReader(public))
+	if err != nil {
+		t.Fatalf("readPGPRecipient() error = %v", err)
+	}
+	encrypted, err := encryptShardPGP("01:ab", recipient)
+	if err != nil {
+		t.Fatalf("encryptShardPGP() error = %v", err)
+	}
+	if _, err := decryptShardPGP([]byte(encrypted), strings.NewReader(otherPrivate), ""); err == nil {
+		t.Error("decryptShardPGP() should fail with another custodian's key")
+	}
+	if _, err := decryptShardPGP([]byte(encrypted), strings.NewReader(public), ""); err == nil {
+		t.Error("decryptShardPGP() should fail with a public key")
+	}
+}
+
+func TestReadPGPRecipientErrors(t *testing.T) {
+	several := armorPGPPublicKeys(t, newTestPGPEntity(t, "alice"), newTestPGPEntity(t, "bob"))
+
+	for name, keyring := range map[string]string{
+		"not a keyring": "hello",
+		"several keys":  several,
+	} {
+		if _, err := readPGPRecipient(strings.NewReader(keyring)); err == nil {
+			t.Errorf("readPGPRecipient(%s) should have returned an error", name)
+		}
+	}
+}
+
+func TestIsPGPEncrypted(t *testing.T) {
+	if isPGPEncrypted([]byte("01:abcdef\n")) {
+		t.Error("isPGPEncrypted() = true for a decrypted shard")
+	}
+	if !isPGPEncrypted([]byte{0xc1, 0x5e, 0x03}) {
+		t.Error("isPGPEncrypted() = false for a binary message")
+	}
+}
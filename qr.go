@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// qrChunkSize is the largest shard payload carried by a single QR code.
+	// Longer shards are split across several codes so each one stays easy to
+	// scan from paper at error-correction level M.
+	qrChunkSize = 1000
+
+	// qrSequencePrefix marks a payload that is one part of a multi-code shard.
+	qrSequencePrefix = "orcrux-qr:"
+
+	// qrModuleSize is the number of pixels used per QR module in PNG output.
+	qrModuleSize = 8
+
+	// qrQuietZone is the number of blank modules around each QR code.
+	qrQuietZone = 4
+)
+
+// qrPayloads splits a shard into the payloads to render as QR codes.
+//
+// A shard that fits in a single code is returned unchanged. Longer shards are
+// cut into chunks, each prefixed with a sequence marker of the form
+// "orcrux-qr:<index>/<total>:<tag>:", where tag is the first 8 hex digits of
+// the SHA-256 of the whole shard. The tag lets joinQRPayloads reject parts
+// that belong to different shards and verify the reassembled result.
+func qrPayloads(shard string, chunkSize int) []string {
+	shard = strings.TrimSpace(shard)
+	if len(shard) <= chunkSize {
+		return []string{shard}
+	}
+
+	tag := qrTag(shard)
+	total := (len(shard) + chunkSize - 1) / chunkSize
+	payloads := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := min((i+1)*chunkSize, len(shard))
+		payloads = append(payloads, fmt.Sprintf("%s%d/%d:%s:%s", qrSequencePrefix, i+1, total, tag, shard[i*chunkSize:end]))
+	}
+	return payloads
+}
+
+// qrTag returns the short digest used to tie sequenced QR payloads together.
+func qrTag(shard string) string {
+	sum := sha256.Sum256([]byte(shard))
+	return hex.EncodeToString(sum[:4])
+}
+
+// qrPart is one decoded part of a sequenced QR shard.
+type qrPart struct {
+	index int
+	total int
+	tag   string
+	data  string
+}
+
+// parseQRPayload parses a sequenced payload. ok is false for plain shards.
+func parseQRPayload(payload string) (part qrPart, ok bool, err error) {
+	if !strings.HasPrefix(payload, qrSequencePrefix) {
+		return qrPart{}, false, nil
+	}
+	fields := strings.SplitN(strings.TrimPrefix(payload, qrSequencePrefix), ":", 3)
+	if len(fields) != 3 {
+		return qrPart{}, true, fmt.Errorf("invalid QR sequence marker: %q", payload)
+	}
+	seq := strings.SplitN(fields[0], "/", 2)
+	if len(seq) != 2 {
+		return qrPart{}, true, fmt.Errorf("invalid QR sequence marker: %q", fields[0])
+	}
+	index, err := strconv.Atoi(seq[0])
+	if err != nil {
+		return qrPart{}, true, fmt.Errorf("invalid QR sequence index: %q", seq[0])
+	}
+	total, err := strconv.Atoi(seq[1])
+	if err != nil || total < 1 || index < 1 || index > total {
+		return qrPart{}, true, fmt.Errorf("invalid QR sequence marker: %q", fields[0])
+	}
+	return qrPart{index: index, total: total, tag: fields[1], data: fields[2]}, true, nil
+}
+
+// joinQRPayloads reassembles a shard from the payloads decoded from its QR codes.
+//
+// Payloads may be given in any order. A single plain payload is returned as
+// is. Sequenced payloads must all carry the same tag and total, every part
+// must be present exactly once, and the reassembled shard must match the tag.
+func joinQRPayloads(payloads []string) (string, error) {
+	if len(payloads) == 0 {
+		return "", errors.New("no QR payloads provided")
+	}
+
+	parts := make([]qrPart, 0, len(payloads))
+	for _, payload := range payloads {
+		part, ok, err := parseQRPayload(strings.TrimSpace(payload))
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			if len(payloads) != 1 {
+				return "", errors.New("plain QR shard cannot be combined with other QR codes")
+			}
+			return strings.TrimSpace(payload), nil
+		}
+		parts = append(parts, part)
+	}
+
+	first := parts[0]
+	if len(parts) != first.total {
+		return "", fmt.Errorf("incomplete QR sequence: got %d of %d codes", len(parts), first.total)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].index < parts[j].index })
+
+	var sb strings.Builder
+	for i, part := range parts {
+		if part.tag != first.tag || part.total != first.total {
+			return "", errors.New("QR codes belong to different shards")
+		}
+		if part.index != i+1 {
+			return "", fmt.Errorf("duplicate or missing QR code in sequence: expected part %d, got %d", i+1, part.index)
+		}
+		sb.WriteString(part.data)
+	}
+
+	shard := sb.String()
+	if qrTag(shard) != first.tag {
+		return "", errors.New("reassembled QR shard does not match its checksum")
+	}
+	return shard, nil
+}
+
+// encodeQRMatrix encodes a payload as a QR code with one pixel per module.
+func encodeQRMatrix(payload string) (*gozxing.BitMatrix, error) {
+	hints := map[gozxing.EncodeHintType]interface{}{
+		gozxing.EncodeHintType_ERROR_CORRECTION: decoder.ErrorCorrectionLevel_M,
+		gozxing.EncodeHintType_MARGIN:           qrQuietZone,
+	}
+	return qrcode.NewQRCodeWriter().Encode(payload, gozxing.BarcodeFormat_QR_CODE, 0, 0, hints)
+}
+
+// renderQRPNG renders a payload as a black-on-white PNG image.
+func renderQRPNG(payload string) ([]byte, error) {
+	matrix, err := encodeQRMatrix(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	img := image.NewGray(image.Rect(0, 0, width*qrModuleSize, height*qrModuleSize))
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			c := color.Gray{Y: 0xff}
+			if matrix.Get(x/qrModuleSize, y/qrModuleSize) {
+				c = color.Gray{Y: 0x00}
+			}
+			img.SetGray(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRSVG renders a payload as a scalable SVG document.
+func renderQRSVG(payload string) ([]byte, error) {
+	matrix, err := encodeQRMatrix(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, width, height)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if matrix.Get(x, y) {
+				fmt.Fprintf(&sb, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+	return []byte(sb.String()), nil
+}
+
+// decodeQRImage decodes the payload of the QR code found in a PNG or JPEG image.
+func decodeQRImage(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		return "", fmt.Errorf("no QR code found: %w", err)
+	}
+	return result.GetText(), nil
+}
+
+// qrFileName returns the file name of part index (1-based) out of total for a
+// base path chosen in the save dialog.
+func qrFileName(base string, index, total int, ext string) string {
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if total == 1 {
+		return base + ext
+	}
+	return fmt.Sprintf("%s-%d-of-%d%s", base, index, total, ext)
+}
+
+// ExportShardQR renders a shard as one or more QR codes and saves them to disk.
+//
+// This function presents a native save dialog to choose the base file name.
+// Shards longer than a single QR code can hold are split into a numbered
+// sequence of codes ("name-1-of-3.png", ...), each carrying a sequence marker
+// so ImportShardQR can put them back together in any order. The
+// "orcrux-qr:i/n:" prefix is a marker of this app, not QR Structured Append,
+// so other scanners read each code on its own and do not join the chunks.
+// The files are readable only by the current user.
+//
+// Parameters:
+//   - shard: The shard to render, as returned by Split
+//   - format: The image format, "png" or "svg"
+//
+// Returns:
+//   - An error if the shard is empty, the format is unknown, rendering fails
+//     or the files cannot be written. Cancelling the dialog is not an error.
+func (a *App) ExportShardQR(shard string, format string) error {
+	shard = strings.TrimSpace(shard)
+	if shard == "" {
+		return errors.New("shard is required")
+	}
+
+	var render func(string) ([]byte, error)
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "png":
+		render = renderQRPNG
+	case "svg":
+		render = renderQRSVG
+	default:
+		return fmt.Errorf("format must be 'png' or 'svg', got: %q", format)
+	}
+	ext := "." + format
+
+	fd := runtime.SaveDialogOptions{
+		Title:           "Save shard QR code",
+		DefaultFilename: "shard" + ext,
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(format) + " images", Pattern: "*" + ext},
+		},
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, fd)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // User cancelled the dialog
+	}
+
+	payloads := qrPayloads(shard, qrChunkSize)
+	for i, payload := range payloads {
+		content, err := render(payload)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(qrFileName(path, i+1, len(payloads), ext), content, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportShardQR opens a file dialog to select QR code images and decodes them into a shard.
+//
+// All images belonging to one shard must be selected together. Their sequence
+// markers are used to reassemble the shard, so the selection order does not
+// matter.
+//
+// Returns:
+//   - The decoded shard string
+//   - An empty string if no file was selected (user cancelled the dialog)
+//   - An error if an image cannot be read or decoded, or the sequence is incomplete
+func (a *App) ImportShardQR() (string, error) {
+	fd := runtime.OpenDialogOptions{
+		Title: "Select shard QR codes",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Images", Pattern: "*.png;*.jpg;*.jpeg"},
+		},
+	}
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, fd)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	payloads := make([]string, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		payload, err := decodeQRImage(content)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return joinQRPayloads(payloads)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQRPayloads(t *testing.T) {
+	t.Run("short shard fits in one code", func(t *testing.T) {
+		payloads := qrPayloads("01:abcdef", qrChunkSize)
+		if len(payloads) != 1 || payloads[0] != "01:abcdef" {
+			t.Errorf("qrPayloads() = %v, want single plain payload", payloads)
+		}
+	})
+
+	t.Run("long shard is sequenced", func(t *testing.T) {
+		shard := "01:" + strings.Repeat("ab", 25)
+		payloads := qrPayloads(shard, 20)
+		if len(payloads) != 3 {
+			t.Fatalf("qrPayloads() returned %d payloads, want 3", len(payloads))
+		}
+		for i, payload := range payloads {
+			if !strings.HasPrefix(payload, qrSequencePrefix) {
+				t.Errorf("payload %d has no sequence marker: %q", i, payload)
+			}
+		}
+	})
+}
+
+func TestJoinQRPayloads(t *testing.T) {
+	shard := "02:" + strings.Repeat("0123456789abcdef", 10)
+	payloads := qrPayloads(shard, 30)
+
+	t.Run("in order", func(t *testing.T) {
+		got, err := joinQRPayloads(payloads)
+		if err != nil {
+			t.Fatalf("joinQRPayloads() error = %v", err)
+		}
+		if got != shard {
+			t.Errorf("joinQRPayloads() = %q, want %q", got, shard)
+		}
+	})
+
+	t.Run("reversed order", func(t *testing.T) {
+		reversed := make([]string, len(payloads))
+		for i, p := range payloads {
+			reversed[len(payloads)-1-i] = p
+		}
+		got, err := joinQRPayloads(reversed)
+		if err != nil {
+			t.Fatalf("joinQRPayloads() error = %v", err)
+		}
+		if got != shard {
+			t.Errorf("joinQRPayloads() = %q, want %q", got, shard)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		payloads []string
+	}{
+		{name: "empty", payloads: nil},
+		{name: "missing part", payloads: payloads[1:]},
+		{name: "duplicate part", payloads: append([]string{payloads[0]}, payloads[:len(payloads)-1]...)},
+		{name: "parts from different shards", payloads: append(append([]string{}, payloads[:len(payloads)-1]...), qrPayloads("03:"+strings.Repeat("ff", 80), 30)[len(payloads)-1])},
+		{name: "plain mixed with sequence", payloads: append([]string{"01:abcd"}, payloads...)},
+		{name: "malformed marker", payloads: []string{qrSequencePrefix + "x/2:abcd:data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := joinQRPayloads(tt.payloads); err == nil {
+				t.Error("joinQRPayloads() should have returned an error")
+			}
+		})
+	}
+}
+
+func TestQRPNGRoundTrip(t *testing.T) {
+	shard := "01:48656c6c6f2c20576f726c6421"
+	img, err := renderQRPNG(shard)
+	if err != nil {
+		t.Fatalf("renderQRPNG() error = %v", err)
+	}
+	if !bytes.HasPrefix(img, []byte("\x89PNG")) {
+		t.Fatal("renderQRPNG() did not produce a PNG image")
+	}
+
+	got, err := decodeQRImage(img)
+	if err != nil {
+		t.Fatalf("decodeQRImage() error = %v", err)
+	}
+	if got != shard {
+		t.Errorf("decodeQRImage() = %q, want %q", got, shard)
+	}
+}
+
+func TestQRSequenceRoundTrip(t *testing.T) {
+	shard := "05:" + strings.Repeat("c2VjcmV0", 300)
+	payloads := qrPayloads(shard, qrChunkSize)
+	if len(payloads) < 2 {
+		t.Fatalf("expected a multi-code sequence, got %d payload(s)", len(payloads))
+	}
+
+	decoded := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		img, err := renderQRPNG(payload)
+		if err != nil {
+			t.Fatalf("renderQRPNG() error = %v", err)
+		}
+		text, err := decodeQRImage(img)
+		if err != nil {
+			t.Fatalf("decodeQRImage() error = %v", err)
+		}
+		decoded = append(decoded, text)
+	}
+
+	got, err := joinQRPayloads(decoded)
+	if err != nil {
+		t.Fatalf("joinQRPayloads() error = %v", err)
+	}
+	if got != shard {
+		t.Error("round-tripped shard does not match the original")
+	}
+}
+
+func TestRenderQRSVG(t *testing.T) {
+	svg, err := renderQRSVG("01:abcdef")
+	if err != nil {
+		t.Fatalf("renderQRSVG() error = %v", err)
+	}
+	s := string(svg)
+	if !strings.HasPrefix(s, "<svg") || !strings.HasSuffix(s, "</svg>") {
+		t.Errorf("renderQRSVG() produced an invalid document: %.60s...", s)
+	}
+	if !strings.Contains(s, "h1v1h-1z") {
+		t.Error("renderQRSVG() produced no dark modules")
+	}
+}
+
+func TestDecodeQRImageInvalid(t *testing.T) {
+	if _, err := decodeQRImage([]byte("not an image")); err == nil {
+		t.Error("decodeQRImage() should fail on non-image data")
+	}
+}
+
+func TestQRFileName(t *testing.T) {
+	tests := []struct {
+		base         string
+		index, total int
+		want         string
+	}{
+		{"/tmp/shard.png", 1, 1, "/tmp/shard.png"},
+		{"/tmp/shard.png", 2, 3, "/tmp/shard-2-of-3.png"},
+		{"/tmp/shard", 1, 2, "/tmp/shard-1-of-2.png"},
+	}
+	for _, tt := range tests {
+		if got := qrFileName(tt.base, tt.index, tt.total, ".png"); got != tt.want {
+			t.Errorf("qrFileName(%q, %d, %d) = %q, want %q", tt.base, tt.index, tt.total, got, tt.want)
+		}
+	}
+}
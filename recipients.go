@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// armorColumns is the line length of the base64 body of an armored age file.
+const armorColumns = 64
+
+// parseRecipients parses one X25519 public key ("age1...") per custodian.
+// A blank entry is an error rather than skipped, so shard i always goes to
+// the custodian at index i.
+func parseRecipients(keys []string) ([]*age.X25519Recipient, error) {
+	recipients := make([]*age.X25519Recipient, 0, len(keys))
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("recipient public key at index %d is empty", i)
+		}
+		r, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient public key at index %d: %w", i, err)
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// encryptShard encrypts a shard to a single recipient and returns it as an
+// ASCII-armored age file.
+func encryptShard(shard string, recipient age.Recipient) (string, error) {
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, shard); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := aw.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// splitForRecipients splits a secret into one shard per recipient and
+// encrypts each shard to its custodian, in order. Shards are signed with the
+// dealer key before being encrypted, if key is not nil.
+func splitForRecipients(secret []byte, keys []string, t int, output string, key ed25519.PrivateKey) ([]string, error) {
+	recipients, err := parseRecipients(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := shamir.Split(secret, len(recipients), t, output)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		if out, err = signShards(key, out); err != nil {
+			return nil, err
+		}
+	}
+
+	shards := strings.Split(strings.TrimSpace(out), "\n")
+	encrypted := make([]string, len(shards))
+	for i, shard := range shards {
+		if encrypted[i], err = encryptShard(shard, recipients[i]); err != nil {
+			return nil, err
+		}
+	}
+	return encrypted, nil
+}
+
+// isAgeEncrypted reports whether s looks like an age file, armored or binary.
+func isAgeEncrypted(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, armor.Header) || strings.HasPrefix(s, "age-encryption.org/")
+}
+
+// normalizeArmor restores the line structure of an armored age file whose
+// line breaks were lost, for example when pasted into a single-line input.
+func normalizeArmor(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "\n") || !strings.HasPrefix(s, armor.Header) || !strings.HasSuffix(s, armor.Footer) {
+		return s
+	}
+
+	body := strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimPrefix(s, armor.Header), armor.Footer)), "")
+	var sb strings.Builder
+	sb.WriteString(armor.Header + "\n")
+	for len(body) > armorColumns {
+		sb.WriteString(body[:armorColumns] + "\n")
+		body = body[armorColumns:]
+	}
+	sb.WriteString(body + "\n" + armor.Footer + "\n")
+	return sb.String()
+}
+
+// decryptShard decrypts an age-encrypted shard with the identities read from
+// an age identity file.
+func decryptShard(encrypted string, identityFile io.Reader) (string, error) {
+	identities, err := age.ParseIdentities(identityFile)
+	if err != nil {
+		return "", fmt.Errorf("invalid identity file: %w", err)
+	}
+
+	encrypted = normalizeArmor(encrypted)
+	var src io.Reader = strings.NewReader(encrypted)
+	if strings.HasPrefix(encrypted, armor.Header) {
+		src = armor.NewReader(src)
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return "", errors.New("this shard was not encrypted to the selected private key")
+		}
+		return "", fmt.Errorf("failed to decrypt shard: %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt shard: %w", err)
+	}
+	return strings.TrimSpace(string(plain)), nil
+}
+
+// SplitForRecipients splits a secret into one shard per custodian and
+// encrypts each shard to that custodian's X25519 public key.
+//
+// The number of shards is the number of recipients. Shard i is encrypted
+// with X25519 and ChaCha20-Poly1305 in the age format to recipients[i], so
+// it can be decrypted with DecryptShard or with the age command line tool.
+// If a dealer key is loaded, shards are signed before being encrypted.
+//
+// Parameters:
+//   - secret: The secret to split (cannot be empty)
+//   - recipients: One age public key ("age1...") per custodian (2-255)
+//   - shardsNeeded: Number of shards required for reconstruction
+//   - output: Encoding of the shard data inside each envelope
+//
+// Returns:
+//   - A JSON Response whose data is the list of ASCII-armored encrypted shards
+func (a *App) SplitForRecipients(secret string, recipients []string, shardsNeeded int, output string) string {
+	key := a.signingKey()
+	defer securemem.Wipe(key)
+	shards, err := splitForRecipients([]byte(secret), recipients, shardsNeeded, output, key)
+	return newResponse(shards, err)
+}
+
+// DecryptShard decrypts an incoming encrypted shard so it can be recomposed.
+//
+// This function presents a native file picker to select the custodian's age
+// identity (private key) file, then decrypts the shard with it.
+//
+// Returns:
+//   - A JSON Response whose data is the decrypted shard, or null if the user
+//     cancelled the dialog
+func (a *App) DecryptShard(encrypted string) string {
+	if !isAgeEncrypted(encrypted) {
+		return newResponse(nil, errors.New("shard is not encrypted"))
+	}
+
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select your private key file"})
+	if err != nil || path == "" {
+		return newResponse(nil, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	defer f.Close()
+
+	shard, err := decryptShard(encrypted, f)
+	return newResponse(shard, err)
+}
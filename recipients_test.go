@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"orcrux/shamir"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func newTestIdentities(t *testing.T, n int) ([]*age.X25519Identity, []string) {
+	t.Helper()
+	identities := make([]*age.X25519Identity, n)
+	keys := make([]string, n)
+	for i := range identities {
+		id, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		identities[i] = id
+		keys[i] = id.Recipient().String()
+	}
+	return identities, keys
+}
+
+func TestSplitForRecipientsRoundTrip(t *testing.T) {
+	identities, keys := newTestIdentities(t, 3)
+	secret := "root key material"
+
+	encrypted, err := splitForRecipients([]byte(secret), keys, 2, "hex", nil)
+	if err != nil {
+		t.Fatalf("splitForRecipients() error = %v", err)
+	}
+	if len(encrypted) != 3 {
+		t.Fatalf("splitForRecipients() returned %d shards, want 3", len(encrypted))
+	}
+
+	shards := make([]string, 0, 2)
+	for _, i := range []int{0, 2} {
+		if !isAgeEncrypted(encrypted[i]) {
+			t.Fatalf("shard %d is not an age file", i)
+		}
+		if strings.Contains(encrypted[i], ":") {
+			t.Errorf("shard %d leaks plaintext share data", i)
+		}
+		shard, err := decryptShard(encrypted[i], strings.NewReader(identities[i].String()))
+		if err != nil {
+			t.Fatalf("decryptShard(%d) error = %v", i, err)
+		}
+		shards = append(shards, shard)
+	}
+
+	got, err := shamir.Recompose(shards)
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if string(got) != secret {
+		t.Errorf("Recompose() = %q, want %q", got, secret)
+	}
+}
+
+func TestSplitForRecipientsSigned(t *testing.T) {
+	identities, keys := newTestIdentities(t, 3)
+	key, _ := newDealerKey(t)
+
+	encrypted, err := splitForRecipients([]byte("root key material"), keys, 2, "hex", key)
+	if err != nil {
+		t.Fatalf("splitForRecipients() error = %v", err)
+	}
+
+	shards := make([]string, 0, 2)
+	for _, i := range []int{1, 2} {
+		shard, err := decryptShard(encrypted[i], strings.NewReader(identities[i].String()))
+		if err != nil {
+			t.Fatalf("decryptShard(%d) error = %v", i, err)
+		}
+		shards = append(shards, shard)
+	}
+
+	// Strict mode rejects unsigned shards, so this fails unless every
+	// decrypted shard carries a valid signature
+	verified, err := checkShardSignatures(key.Public().(ed25519.PublicKey), true, shards)
+	if err != nil {
+		t.Fatalf("checkShardSignatures() error = %v", err)
+	}
+	got, err := shamir.Recompose(verified)
+	if err != nil || string(got) != "root key material" {
+		t.Errorf("Recompose() = %q, %v", got, err)
+	}
+}
+
+func TestDecryptShardWrongKey(t *testing.T) {
+	identities, keys := newTestIdentities(t, 2)
+
+	encrypted, err := splitForRecipients([]byte("secret"), keys, 2, "base64", nil)
+	if err != nil {
+		t.Fatalf("splitForRecipients() error = %v", err)
+	}
+	if _, err := decryptShard(encrypted[0], strings.NewReader(identities[1].String())); err == nil {
+		t.Error("decryptShard() should fail with another custodian's key")
+	}
+	if _, err := decryptShard(encrypted[0], strings.NewReader("not a key")); err == nil {
+		t.Error("decryptShard() should fail with an invalid identity file")
+	}
+}
+
+func TestSplitForRecipientsErrors(t *testing.T) {
+	_, keys := newTestIdentities(t, 2)
+
+	tests := []struct {
+		name string
+		keys []string
+		t    int
+	}{
+		{name: "invalid public key", keys: []string{keys[0], "age1notakey"}, t: 2},
+		{name: "blank public key", keys: []string{keys[0], " ", keys[1]}, t: 2},
+		{name: "single recipient", keys: keys[:1], t: 2},
+		{name: "threshold above recipients", keys: keys, t: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := splitForRecipients([]byte("secret"), tt.keys, tt.t, "hex", nil); err == nil {
+				t.Error("splitForRecipients() should have returned an error")
+			}
+		})
+	}
+}
+
+func TestParseRecipientsBlankKey(t *testing.T) {
+	_, keys := newTestIdentities(t, 2)
+	_, err := parseRecipients([]string{keys[0], "", keys[1]})
+	if err == nil || !strings.Contains(err.Error(), "index 1") {
+		t.Errorf("parseRecipients() error = %v, want the index of the blank key", err)
+	}
+}
+
+func TestDecryptShardSingleLineArmor(t *testing.T) {
+	identities, keys := newTestIdentities(t, 2)
+
+	encrypted, err := splitForRecipients([]byte("secret"), keys, 2, "hex", nil)
+	if err != nil {
+		t.Fatalf("splitForRecipients() error = %v", err)
+	}
+
+	// Text inputs drop line breaks when an armored shard is pasted into them
+	flattened := strings.ReplaceAll(encrypted[1], "\n", "")
+	if _, err := decryptShard(flattened, strings.NewReader(identities[1].String())); err != nil {
+		t.Errorf("decryptShard() error = %v for a flattened armored shard", err)
+	}
+}
+
+func TestIsAgeEncrypted(t *testing.T) {
+	if isAgeEncrypted("01:abcdef") {
+		t.Error("isAgeEncrypted() = true for a plain shard")
+	}
+	if !isAgeEncrypted("  -----BEGIN AGE ENCRYPTED FILE-----\n...") {
+		t.Error("isAgeEncrypted() = false for an armored age file")
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"slices"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// defaultSessionWipeAfter is how long a recovered secret stays in a
+// reconstruction session when no timeout is given.
+const defaultSessionWipeAfter = 5 * time.Minute
+
+// eventSessionWiped is emitted to the frontend when a session is wiped.
+const eventSessionWiped = "reconstruction:wiped"
+
+var (
+	// ErrNoSession is returned when no reconstruction session is open.
+	ErrNoSession = errors.New("no reconstruction session in progress")
+
+	// ErrSessionComplete is returned when adding a shard to a session whose
+	// secret was already reconstructed.
+	ErrSessionComplete = errors.New("the secret was already reconstructed")
+)
+
+// sessionShard is a shard collected by a reconstruction session.
+type sessionShard struct {
+	text      []byte // Shard without its signature
+	x         byte
+	length    int
+	custodian string
+}
+
+// reconstruction collects shards one at a time and recomposes the secret
+// once the threshold is reached. The secret is wiped after wipeAfter.
+type reconstruction struct {
+	threshold int
+	manifest  *Manifest
+	wipeAfter time.Duration
+
+	shards    []sessionShard
+	transform []string // Transforms of the first shard, which the others must match
+	secret    []byte
+	expiresAt time.Time
+	timer     *time.Timer
+	wiped     bool
+}
+
+// ReconstructionState is the progress of a reconstruction session.
+type ReconstructionState struct {
+	Collected  int              `json:"collected"`
+	Threshold  int              `json:"threshold"`
+	Custodians []string         `json:"custodians"` // Custodians whose shard was collected, if a manifest is used
+	Complete   bool             `json:"complete"`
+	Secret     *RecoveredSecret `json:"secret,omitempty"`
+	ExpiresAt  *time.Time       `json:"expiresAt,omitempty"` // When the secret will be wiped
+	Wiped      bool             `json:"wiped"`
+}
+
+// newReconstruction starts a session. With a manifest, the threshold is
+// taken from it and every shard must be listed in it.
+func newReconstruction(threshold int, m *Manifest, wipeAfter time.Duration) (*reconstruction, error) {
+	if m != nil {
+		if threshold != 0 && threshold != m.Threshold {
+			return nil, fmt.Errorf("threshold %d differs from the manifest threshold %d", threshold, m.Threshold)
+		}
+		threshold = m.Threshold
+	}
+	if threshold < 2 || threshold > 255 {
+		return nil, &shamir.ParamError{Name: "shardsNeeded", Value: threshold, Min: 2, Max: 255, Err: shamir.ErrInvalidThreshold}
+	}
+	if wipeAfter <= 0 {
+		wipeAfter = defaultSessionWipeAfter
+	}
+	return &reconstruction{threshold: threshold, manifest: m, wipeAfter: wipeAfter}, nil
+}
+
+// add validates a shard against the shards already collected and keeps it.
+// Once the threshold is reached the secret is recomposed, and onWipe is
+// scheduled to run after the session timeout.
+func (r *reconstruction) add(text string, onWipe func()) error {
+	if r.wiped {
+		return ErrNoSession
+	}
+	if r.secret != nil {
+		return ErrSessionComplete
+	}
+
+	share, err := shamir.ParseLegacyShare(text)
+	if err != nil {
+		return err
+	}
+	s := sessionShard{text: []byte(text), x: share.X, length: len(share.Data)}
+	securemem.Wipe(share.Data)
+	if r.manifest != nil {
+		check := r.manifest.Check([]string{text}).Shards[0]
+		if check.Status != shardOK {
+			return fmt.Errorf("shard is %s in the manifest of set %s", check.Status, r.manifest.SetID)
+		}
+		s.custodian = check.Custodian
+	}
+	if len(r.shards) == 0 {
+		r.transform = share.Transforms
+	} else {
+		if slices.ContainsFunc(r.shards, func(c sessionShard) bool { return c.x == s.x }) {
+			return fmt.Errorf("%w: a shard with x-coordinate %02x was already collected", shamir.ErrDuplicateShard, s.x)
+		}
+		if s.length != r.shards[0].length {
+			return shamir.ErrInconsistentLength
+		}
+		if !slices.Equal(share.Transforms, r.transform) {
+			return shamir.ErrMixedTransforms
+		}
+	}
+	securemem.Lock(s.text)
+	r.shards = append(r.shards, s)
+
+	if len(r.shards) < r.threshold {
+		return nil
+	}
+	texts := make([]string, len(r.shards))
+	for i, c := range r.shards {
+		texts[i] = string(c.text)
+	}
+	secret, err := shamir.Recompose(texts)
+	if err != nil {
+		// Keep the shards collected so far; the last one may be the culprit
+		securemem.WipeAndUnlock(s.text)
+		r.shards = r.shards[:len(r.shards)-1]
+		return err
+	}
+	// The secret is held until the timeout; keep it out of swap
+	securemem.Lock(secret)
+	r.secret = secret
+	r.expiresAt = time.Now().Add(r.wipeAfter)
+	r.timer = time.AfterFunc(r.wipeAfter, onWipe)
+	return nil
+}
+
+// wipe erases the secret and the shards and stops the timer.
+func (r *reconstruction) wipe() {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	securemem.WipeAndUnlock(r.secret)
+	r.secret = nil
+	for _, s := range r.shards {
+		securemem.WipeAndUnlock(s.text)
+	}
+	r.shards = nil
+	r.wiped = true
+}
+
+// state returns the progress of the session.
+func (r *reconstruction) state() ReconstructionState {
+	st := ReconstructionState{
+		Collected:  len(r.shards),
+		Threshold:  r.threshold,
+		Custodians: []string{},
+		Complete:   r.secret != nil,
+		Wiped:      r.wiped,
+	}
+	for _, s := range r.shards {
+		if s.custodian != "" {
+			st.Custodians = append(st.Custodians, s.custodian)
+		}
+	}
+	if r.secret != nil {
+		st.Secret = &RecoveredSecret{
+			Data:        base64.StdEncoding.EncodeToString(r.secret),
+			ContentType: http.DetectContentType(r.secret),
+			Size:        len(r.secret),
+		}
+		expiresAt := r.expiresAt
+		st.ExpiresAt = &expiresAt
+	}
+	return st
+}
+
+// wipeReconstruction wipes r if it is still the current session. It is the
+// timer callback of the session.
+func (a *App) wipeReconstruction(r *reconstruction) {
+	a.mu.Lock()
+	current := a.reconstruction == r
+	if current {
+		r.wipe()
+	}
+	a.mu.Unlock()
+	if current && a.ctx != nil {
+		runtime.EventsEmit(a.ctx, eventSessionWiped)
+	}
+}
+
+// StartReconstruction opens a reconstruction session, replacing and wiping
+// any previous one.
+//
+// Shards are then added one at a time with AddSessionShard as custodians
+// arrive. The secret is recomposed as soon as the threshold is reached, and
+// wiped from the session after the timeout.
+//
+// Parameters:
+//   - shardsNeeded: The threshold of the set, or 0 to take it from the manifest
+//   - manifest: A manifest JSON as returned by OpenManifest, or empty. When
+//     given, only shards listed in it are accepted
+//   - timeoutSeconds: How long the recovered secret is kept, or 0 for 5 minutes
+//
+// Returns:
+//   - A JSON response with the ReconstructionState, or an error if the
+//     threshold or the manifest is invalid
+func (a *App) StartReconstruction(shardsNeeded int, manifest string, timeoutSeconds int) string {
+	var m *Manifest
+	if manifest != "" {
+		var err error
+		if m, err = a.loadManifest([]byte(manifest)); err != nil {
+			return newResponse(nil, err)
+		}
+	}
+	r, err := newReconstruction(shardsNeeded, m, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.reconstruction != nil {
+		a.reconstruction.wipe()
+	}
+	a.reconstruction = r
+	return newResponse(r.state(), nil)
+}
+
+// AddSessionShard validates a shard and adds it to the reconstruction
+// session. Its signature is checked against the pinned dealer key, and it
+// must be consistent with the shards already collected.
+//
+// Parameters:
+//   - shard: The shard brought by a custodian
+//
+// Returns:
+//   - A JSON response with the ReconstructionState, including the secret
+//     once the threshold is reached, or an error if the shard is rejected
+func (a *App) AddSessionShard(shard string) string {
+	shards, err := a.verifiedShards([]string{shard})
+	if err != nil {
+		return newResponse(nil, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := a.reconstruction
+	if r == nil {
+		return newResponse(nil, ErrNoSession)
+	}
+	if err := r.add(shards[0], func() { a.wipeReconstruction(r) }); err != nil {
+		return newResponse(nil, err)
+	}
+	return newResponse(r.state(), nil)
+}
+
+// Reconstruction returns the progress of the current session.
+//
+// Returns:
+//   - A JSON response with the ReconstructionState, or null data if no
+//     session was started
+func (a *App) Reconstruction() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.reconstruction == nil {
+		return newResponse(nil, nil)
+	}
+	return newResponse(a.reconstruction.state(), nil)
+}
+
+// EndReconstruction wipes the secret and the shards of the current session
+// immediately.
+//
+// Returns:
+//   - A JSON response with the final ReconstructionState, or an error if no
+//     session was started
+func (a *App) EndReconstruction() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.reconstruction == nil {
+		return newResponse(nil, ErrNoSession)
+	}
+	a.reconstruction.wipe()
+	return newResponse(a.reconstruction.state(), nil)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"testing"
+	"time"
+)
+
+func TestReconstruction(t *testing.T) {
+	shards := splitShards(t, "launch codes", 4, 3, "base64+pad")
+	r, err := newReconstruction(3, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noWipe := func() { t.Error("session wiped too early") }
+
+	if err := r.add(shards[0], noWipe); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	if err := r.add(shards[0], noWipe); !errors.Is(err, shamir.ErrDuplicateShard) {
+		t.Errorf("add() of the same shard error = %v, want ErrDuplicateShard", err)
+	}
+	other := splitShards(t, "a different and much longer secret", 2, 2, "base64")
+	if err := r.add(other[1], noWipe); !errors.Is(err, shamir.ErrInconsistentLength) && !errors.Is(err, shamir.ErrMixedTransforms) {
+		t.Errorf("add() of a shard from another set error = %v", err)
+	}
+	if err := r.add("garbage", noWipe); err == nil {
+		t.Error("add() should reject an invalid shard")
+	}
+	if err := r.add(shards[2], noWipe); err != nil {
+		t.Fatal(err)
+	}
+	if st := r.state(); st.Collected != 2 || st.Threshold != 3 || st.Complete {
+		t.Errorf("state() = %+v, want 2 of 3", st)
+	}
+
+	if err := r.add(shards[3], noWipe); err != nil {
+		t.Fatalf("add() reaching the threshold error = %v", err)
+	}
+	st := r.state()
+	if !st.Complete || st.Secret == nil || st.Secret.Data != base64.StdEncoding.EncodeToString([]byte("launch codes")) || st.ExpiresAt == nil {
+		t.Errorf("state() after threshold = %+v", st)
+	}
+	if err := r.add(shards[1], noWipe); !errors.Is(err, ErrSessionComplete) {
+		t.Errorf("add() after completion error = %v, want ErrSessionComplete", err)
+	}
+
+	// Keep the buffers the session held, to check they were zeroed in place
+	secret := r.secret
+	held := [][]byte{secret}
+	for _, s := range r.shards {
+		held = append(held, s.text)
+	}
+	r.wipe()
+	if st := r.state(); st.Secret != nil || st.Collected != 0 || !st.Wiped {
+		t.Errorf("state() after wipe = %+v", st)
+	}
+	for i, b := range held {
+		if len(b) == 0 || !securemem.IsZero(b) {
+			t.Errorf("buffer %d was not cleared by wipe(): %q", i, b)
+		}
+	}
+}
+
+func TestReconstructionLegacyShards(t *testing.T) {
+	// Untagged shards of "test" from the original format
+	r, err := newReconstruction(2, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.wipe()
+	for _, shard := range []string{"01:9ab59f9a", "03:5d0e5c5d"} {
+		if err := r.add(shard, func() {}); err != nil {
+			t.Fatalf("add(%q) error = %v", shard, err)
+		}
+	}
+	if st := r.state(); !st.Complete || st.Secret == nil || st.Secret.Data != base64.StdEncoding.EncodeToString([]byte("test")) {
+		t.Errorf("state() = %+v, want the secret recomposed", st)
+	}
+}
+
+func TestReconstructionManifest(t *testing.T) {
+	shards := splitShards(t, "secret", 3, 2, "hex")
+	m, _ := newManifest(testSetID, shards, []string{"Alice", "Bob", "Carol"}, 2)
+
+	if _, err := newReconstruction(3, m, 0); err == nil {
+		t.Error("newReconstruction() should reject a threshold differing from the manifest")
+	}
+	r, err := newReconstruction(0, m, 0)
+	if err != nil || r.threshold != 2 || r.wipeAfter != defaultSessionWipeAfter {
+		t.Fatalf("newReconstruction() = %+v, %v", r, err)
+	}
+	stranger := splitShards(t, "SECRET", 3, 2, "hex")
+	if err := r.add(stranger[0], func() {}); err == nil {
+		t.Error("add() should reject a shard missing from the manifest")
+	}
+	r.add(shards[2], func() {})
+	if st := r.state(); len(st.Custodians) != 1 || st.Custodians[0] != "Carol" {
+		t.Errorf("state() custodians = %v", st.Custodians)
+	}
+	if _, err := newReconstruction(0, nil, 0); err == nil {
+		t.Error("newReconstruction() should require a threshold without a manifest")
+	}
+}
+
+func TestAppReconstructionWipesAfterTimeout(t *testing.T) {
+	app := NewApp()
+	var response Response
+	json.Unmarshal([]byte(app.AddSessionShard("01:hex:00")), &response)
+	if response.Code != "no_session" {
+		t.Errorf("AddSessionShard() without a session code = %q", response.Code)
+	}
+
+	json.Unmarshal([]byte(app.StartReconstruction(2, "", 1)), &response)
+	if response.Error != nil {
+		t.Fatalf("StartReconstruction() error = %s", *response.Error)
+	}
+	// A much shorter timeout than the seconds the App method accepts
+	app.reconstruction.wipeAfter = 10 * time.Millisecond
+
+	shards := splitShards(t, "secret", 2, 2, "hex")
+	app.AddSessionShard(shards[0])
+	var state struct {
+		Data ReconstructionState `json:"data"`
+	}
+	json.Unmarshal([]byte(app.AddSessionShard(shards[1])), &state)
+	if !state.Data.Complete || state.Data.Secret == nil {
+		t.Fatalf("AddSessionShard() reaching the threshold = %+v", state.Data)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		state.Data = ReconstructionState{}
+		json.Unmarshal([]byte(app.Reconstruction()), &state)
+		if state.Data.Wiped {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the session was not wiped after its timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if state.Data.Secret != nil {
+		t.Error("the secret survived the wipe")
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// sealedMagic is the first line of every passphrase-sealed shard file.
+	sealedMagic = "ORCRUX SEALED SHARD v1"
+
+	// sealedExt is the file extension used for sealed shard files.
+	sealedExt = ".sealed"
+
+	// kdfArgon2id names the only key derivation function currently supported.
+	kdfArgon2id = "argon2id"
+
+	// sealSaltSize is the length of the random salt fed to the KDF.
+	sealSaltSize = 16
+
+	// Bounds applied to KDF parameters read from a file, so a crafted file
+	// cannot make unlocking exhaust memory or CPU. They leave room above
+	// defaultSealParams for stronger settings, no more.
+	maxSealTime    = 10
+	maxSealMemory  = 1024 * 1024 // KiB, i.e. 1 GiB
+	maxSealThreads = 64
+)
+
+// errSealed is returned by UploadFile when the selected file is sealed and
+// must be unlocked with UnlockShard.
+var errSealed = errors.New("passphrase required")
+
+// errWrongPassphrase is returned when a sealed shard cannot be opened.
+var errWrongPassphrase = errors.New("wrong passphrase, or the sealed file was modified")
+
+// sealParams are the Argon2id parameters used to derive the sealing key.
+// They are stored in the sealed file, so they can be raised over time
+// without breaking older files.
+type sealParams struct {
+	Time    uint32 // Number of passes over the memory
+	Memory  uint32 // Memory size in KiB
+	Threads uint8  // Degree of parallelism
+}
+
+// defaultSealParams follows the second recommended option of RFC 9106.
+var defaultSealParams = sealParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// validate checks that the parameters are usable and within safe bounds.
+func (p sealParams) validate() error {
+	if p.Time < 1 || p.Time > maxSealTime {
+		return fmt.Errorf("argon2id time must be in [1, %d]", maxSealTime)
+	}
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxSealMemory {
+		return fmt.Errorf("argon2id memory must be in [%d, %d] KiB", 8*uint32(p.Threads), maxSealMemory)
+	}
+	if p.Threads < 1 || p.Threads > maxSealThreads {
+		return fmt.Errorf("argon2id threads must be in [1, %d]", maxSealThreads)
+	}
+	return nil
+}
+
+// deriveSealKey derives the ChaCha20-Poly1305 key from a passphrase.
+func deriveSealKey(passphrase string, salt []byte, p sealParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+// sealHeader renders the plain-text header of a sealed file. The header is
+// authenticated as additional data, so its parameters cannot be altered.
+func sealHeader(p sealParams, salt, nonce []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, sealedMagic)
+	fmt.Fprintf(&buf, "kdf: %s\n", kdfArgon2id)
+	fmt.Fprintf(&buf, "time: %d\n", p.Time)
+	fmt.Fprintf(&buf, "memory: %d\n", p.Memory)
+	fmt.Fprintf(&buf, "threads: %d\n", p.Threads)
+	fmt.Fprintf(&buf, "salt: %s\n", base64.StdEncoding.EncodeToString(salt))
+	fmt.Fprintf(&buf, "nonce: %s\n", base64.StdEncoding.EncodeToString(nonce))
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// sealShard encrypts a shard file with a key derived from passphrase.
+//
+// The result is a text file: a header holding the KDF parameters, salt and
+// nonce, a blank line, then the base64 XChaCha20-Poly1305 ciphertext.
+func sealShard(plain []byte, passphrase string, p sealParams) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, sealSaltSize)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(deriveSealKey(passphrase, salt, p))
+	if err != nil {
+		return nil, err
+	}
+	header := sealHeader(p, salt, nonce)
+	ciphertext := aead.Seal(nil, nonce, plain, header)
+
+	out := append(header, base64.StdEncoding.EncodeToString(ciphertext)...)
+	return append(out, '\n'), nil
+}
+
+// isSealed reports whether data is a passphrase-sealed shard file.
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(sealedMagic+"\n"))
+}
+
+// parseSealed splits a sealed file into its parameters, salt, nonce and
+// ciphertext.
+func parseSealed(data []byte) (p sealParams, salt, nonce, ciphertext []byte, err error) {
+	r := bufio.NewReader(bytes.NewReader(bytes.TrimLeft(data, " \t\r\n")))
+	magic, _ := r.ReadString('\n')
+	if strings.TrimRight(magic, "\r\n") != sealedMagic {
+		return p, nil, nil, nil, errors.New("not a sealed shard file")
+	}
+
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return p, nil, nil, nil, errors.New("sealed shard file header is truncated")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return p, nil, nil, nil, fmt.Errorf("malformed sealed shard header line %q", line)
+		}
+		fields[key] = value
+	}
+
+	if fields["kdf"] != kdfArgon2id {
+		return p, nil, nil, nil, fmt.Errorf("unsupported key derivation function %q", fields["kdf"])
+	}
+	parseUint := func(key string, bits int) uint64 {
+		v, parseErr := strconv.ParseUint(fields[key], 10, bits)
+		if parseErr != nil && err == nil {
+			err = fmt.Errorf("invalid %s in sealed shard header", key)
+		}
+		return v
+	}
+	p.Time = uint32(parseUint("time", 32))
+	p.Memory = uint32(parseUint("memory", 32))
+	p.Threads = uint8(parseUint("threads", 8))
+	if err != nil {
+		return p, nil, nil, nil, err
+	}
+	if err := p.validate(); err != nil {
+		return p, nil, nil, nil, err
+	}
+
+	if salt, err = base64.StdEncoding.DecodeString(fields["salt"]); err != nil || len(salt) < 8 {
+		return p, nil, nil, nil, errors.New("invalid salt in sealed shard header")
+	}
+	if nonce, err = base64.StdEncoding.DecodeString(fields["nonce"]); err != nil || len(nonce) != chacha20poly1305.NonceSizeX {
+		return p, nil, nil, nil, errors.New("invalid nonce in sealed shard header")
+	}
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(r); err != nil {
+		return p, nil, nil, nil, err
+	}
+	if ciphertext, err = base64.StdEncoding.DecodeString(strings.TrimSpace(body.String())); err != nil {
+		return p, nil, nil, nil, errors.New("sealed shard data is not valid base64")
+	}
+	return p, salt, nonce, ciphertext, nil
+}
+
+// openSealed decrypts a sealed shard file. A wrong passphrase and a tampered
+// file are indistinguishable and both return errWrongPassphrase.
+func openSealed(data []byte, passphrase string) ([]byte, error) {
+	p, salt, nonce, ciphertext, err := parseSealed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(deriveSealKey(passphrase, salt, p))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, nonce, ciphertext, sealHeader(p, salt, nonce))
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plain, nil
+}
+
+// SealShards protects shard text with a custodian-chosen passphrase.
+//
+// The key is derived with Argon2id using the default parameters, which are
+// recorded in the output so they can be tuned later. The sealed text can be
+// written with SaveFileDialog and read back with UploadFile and UnlockShard.
+//
+// Parameters:
+//   - content: The shard text to protect (cannot be empty)
+//   - passphrase: The custodian's passphrase (cannot be empty)
+//
+// Returns:
+//   - A JSON Response whose data is the sealed file contents
+func (a *App) SealShards(content string, passphrase string) string {
+	if content == "" {
+		return newResponse(nil, errors.New("content is required"))
+	}
+	sealed, err := sealShard([]byte(content), passphrase, defaultSealParams)
+	return newResponse(string(sealed), err)
+}
+
+// UnlockShard opens the sealed shard file most recently selected with
+// UploadFile.
+//
+// When UploadFile reports that a passphrase is required, the frontend asks
+// the custodian for it and calls UnlockShard. A wrong passphrase leaves the
+// file pending so the custodian can try again.
+//
+// Parameters:
+//   - passphrase: The passphrase the file was sealed with
+//
+// Returns:
+//   - The unlocked file contents
+//   - An error if no sealed file is pending or the passphrase is wrong
+func (a *App) UnlockShard(passphrase string) (string, error) {
+	// Deriving the key takes a while; do it without holding a.mu so the
+	// rest of the app stays responsive
+	a.mu.Lock()
+	sealed := a.pendingSealed
+	a.mu.Unlock()
+
+	if sealed == nil {
+		return "", errors.New("no sealed shard file is waiting to be unlocked")
+	}
+	plain, err := openSealed(sealed, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	// Another file may have been selected in the meantime; keep it pending
+	if bytes.Equal(a.pendingSealed, sealed) {
+		a.pendingSealed = nil
+	}
+	a.mu.Unlock()
+	return string(plain), nil
+}
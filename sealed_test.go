@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testSealParams keeps key derivation cheap in tests.
+var testSealParams = sealParams{Time: 1, Memory: 64, Threads: 1}
+
+func TestSealAndOpenShard(t *testing.T) {
+	shards := []byte("01:abcdef\n02:123456\n")
+
+	sealed, err := sealShard(shards, "correct horse", testSealParams)
+	if err != nil {
+		t.Fatalf("sealShard() error = %v", err)
+	}
+	if !isSealed(sealed) {
+		t.Fatal("isSealed() = false for a sealed file")
+	}
+	if bytes.Contains(sealed, []byte("abcdef")) {
+		t.Error("sealed file leaks shard data")
+	}
+	if !bytes.Contains(sealed, []byte("memory: 64\n")) {
+		t.Error("sealed file does not record its KDF parameters")
+	}
+
+	got, err := openSealed(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("openSealed() error = %v", err)
+	}
+	if !bytes.Equal(got, shards) {
+		t.Errorf("openSealed() = %q, want %q", got, shards)
+	}
+
+	if _, err := openSealed(sealed, "wrong horse"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("openSealed() with a wrong passphrase error = %v, want %v", err, errWrongPassphrase)
+	}
+}
+
+func TestOpenSealedTampered(t *testing.T) {
+	sealed, err := sealShard([]byte("01:abcdef"), "pass", testSealParams)
+	if err != nil {
+		t.Fatalf("sealShard() error = %v", err)
+	}
+
+	// Lowering the work factor must invalidate the authentication tag
+	tampered := bytes.Replace(sealed, []byte("threads: 1\n"), []byte("threads: 2\n"), 1)
+	if _, err := openSealed(tampered, "pass"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("openSealed() with tampered parameters error = %v, want %v", err, errWrongPassphrase)
+	}
+}
+
+func TestParseSealedErrors(t *testing.T) {
+	header := sealedMagic + "\nkdf: argon2id\ntime: 1\nthreads: 1\nsalt: AAAAAAAAAAAAAAAAAAAAAA==\nnonce: AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\n"
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "not sealed", input: "01:abcdef\n"},
+		{name: "truncated", input: sealedMagic + "\nkdf: argon2id\n"},
+		{name: "unknown kdf", input: sealedMagic + "\nkdf: md5\n\n"},
+		{name: "excessive memory", input: header + "memory: 999999999\n\nAAAA\n"},
+		{name: "memory over 1 GiB", input: header + "memory: 1048577\n\nAAAA\n"},
+		{name: "too many passes", input: strings.Replace(header, "time: 1", "time: 11", 1) + "memory: 64\n\nAAAA\n"},
+		{name: "zero time", input: strings.Replace(header, "time: 1", "time: 0", 1) + "memory: 64\n\nAAAA\n"},
+		{name: "bad ciphertext", input: header + "memory: 64\n\n!!!\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := openSealed([]byte(tt.input), "pass"); err == nil {
+				t.Error("openSealed() should have returned an error")
+			}
+		})
+	}
+}
+
+func TestSealShardErrors(t *testing.T) {
+	if _, err := sealShard([]byte("01:ab"), "", testSealParams); err == nil {
+		t.Error("sealShard() should reject an empty passphrase")
+	}
+	if _, err := sealShard([]byte("01:ab"), "pass", sealParams{}); err == nil {
+		t.Error("sealShard() should reject zero parameters")
+	}
+}
+
+func TestUnlockShard(t *testing.T) {
+	app := NewApp()
+
+	if _, err := app.UnlockShard("pass"); err == nil {
+		t.Error("UnlockShard() should fail when nothing is pending")
+	}
+
+	sealed, err := sealShard([]byte("01:abcdef"), "pass", testSealParams)
+	if err != nil {
+		t.Fatalf("sealShard() error = %v", err)
+	}
+	app.pendingSealed = sealed
+
+	if _, err := app.UnlockShard("nope"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("UnlockShard() error = %v, want %v", err, errWrongPassphrase)
+	}
+	got, err := app.UnlockShard("pass")
+	if err != nil {
+		t.Fatalf("UnlockShard() error = %v after a retry", err)
+	}
+	if got != "01:abcdef" {
+		t.Errorf("UnlockShard() = %q, want %q", got, "01:abcdef")
+	}
+	if app.pendingSealed != nil {
+		t.Error("UnlockShard() left the file pending after unlocking it")
+	}
+}
@@ -0,0 +1,21 @@
+//go:build linux
+
+package securemem
+
+import "syscall"
+
+// prSetDumpable is PR_SET_DUMPABLE from <linux/prctl.h>.
+const prSetDumpable = 4
+
+// disableCoreDumps sets RLIMIT_CORE to zero and marks the process as not
+// dumpable, which also stops other processes of the same user from reading
+// its memory through ptrace or /proc.
+func disableCoreDumps() error {
+	if err := syscall.Setrlimit(syscall.RLIMIT_CORE, &syscall.Rlimit{}); err != nil {
+		return err
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetDumpable, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
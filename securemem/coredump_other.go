@@ -0,0 +1,6 @@
+//go:build !unix
+
+package securemem
+
+// disableCoreDumps does nothing: this platform has no core dump limit.
+func disableCoreDumps() error { return nil }
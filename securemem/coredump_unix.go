@@ -0,0 +1,10 @@
+//go:build unix && !linux
+
+package securemem
+
+import "syscall"
+
+// disableCoreDumps sets RLIMIT_CORE to zero.
+func disableCoreDumps() error {
+	return syscall.Setrlimit(syscall.RLIMIT_CORE, &syscall.Rlimit{})
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package securemem
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestDisableCoreDumps(t *testing.T) {
+	if err := DisableCoreDumps(); err != nil {
+		t.Fatalf("DisableCoreDumps() error = %v", err)
+	}
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CORE, &limit); err != nil {
+		t.Fatal(err)
+	}
+	if limit.Cur != 0 || limit.Max != 0 {
+		t.Errorf("RLIMIT_CORE = %+v, want 0", limit)
+	}
+}
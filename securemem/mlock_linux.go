@@ -0,0 +1,9 @@
+//go:build linux
+
+package securemem
+
+import "syscall"
+
+func lock(b []byte) error { return syscall.Mlock(b) }
+
+func unlock(b []byte) error { return syscall.Munlock(b) }
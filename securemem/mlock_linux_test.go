@@ -0,0 +1,62 @@
+//go:build linux
+
+package securemem
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// lockedKiB returns the memory locked by the process, from /proc.
+func lockedKiB(t *testing.T) int {
+	t.Helper()
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		t.Skipf("cannot read the process status: %v", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "VmLck:"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "kB")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+	}
+	t.Skip("the process status has no VmLck line")
+	return 0
+}
+
+func TestUnlockKeepsSharedPageLocked(t *testing.T) {
+	before := lockedKiB(t)
+	buf := make([]byte, 64)
+	a, b := buf[:32], buf[32:]
+	for _, x := range [][]byte{a, b} {
+		if err := Lock(x); err != nil {
+			if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.ENOMEM) {
+				t.Skipf("cannot lock memory here: %v", err)
+			}
+			t.Fatalf("Lock() error = %v", err)
+		}
+	}
+	locked := lockedKiB(t)
+	if locked <= before {
+		t.Fatalf("VmLck = %d kB after Lock(), was %d kB", locked, before)
+	}
+
+	WipeAndUnlock(a)
+	if got := lockedKiB(t); got != locked {
+		t.Errorf("VmLck = %d kB after unlocking a buffer sharing its page, want %d kB", got, locked)
+	}
+	WipeAndUnlock(b)
+	if got := lockedKiB(t); got != before {
+		t.Errorf("VmLck = %d kB after unlocking every buffer, want %d kB", got, before)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package securemem
+
+// Memory locking is only implemented on Linux.
+
+func lock(b []byte) error { return nil }
+
+func unlock(b []byte) error { return nil }
@@ -0,0 +1,145 @@
+// Package securemem limits how long secrets stay in memory and where they
+// can end up.
+//
+// Go strings are immutable and copied freely, so secrets are kept in []byte
+// buffers that are zeroed with Wipe as soon as they are no longer needed.
+// Long-lived buffers can be locked into RAM with Lock, so they are never
+// written to swap, and DisableCoreDumps keeps the process memory out of
+// core files. Locking and core dump control are best effort: they are
+// no-ops on platforms without them, and Lock fails where the memory lock
+// limit does not allow it.
+package securemem
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Wipe overwrites every buffer with zeros.
+func Wipe(bufs ...[]byte) {
+	for _, b := range bufs {
+		clear(b)
+	}
+	// Keep the buffers, and so the stores above, alive until here
+	runtime.KeepAlive(bufs)
+}
+
+// IsZero reports whether b holds only zeros.
+func IsZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Locks are taken per page, and several buffers can share a page, so locked
+// pages are counted: a page is only unlocked once no locked buffer uses it.
+var (
+	lockMu     sync.Mutex
+	lockedBufs = make(map[span]int)    // Lock count of each buffer
+	lockedPage = make(map[uintptr]int) // Number of locked buffers on each page
+)
+
+// span identifies a buffer by its address and length.
+type span struct {
+	addr uintptr
+	len  int
+}
+
+// pageSpan is the part of a buffer that lies in one page.
+type pageSpan struct {
+	page uintptr
+	b    []byte
+}
+
+// pages splits b at page boundaries.
+func pages(b []byte) []pageSpan {
+	size := uintptr(os.Getpagesize())
+	var spans []pageSpan
+	for len(b) > 0 {
+		addr := uintptr(unsafe.Pointer(&b[0]))
+		page := addr &^ (size - 1)
+		n := min(int(page+size-addr), len(b))
+		spans = append(spans, pageSpan{page: page, b: b[:n]})
+		b = b[n:]
+	}
+	return spans
+}
+
+// Lock locks the pages holding b into RAM, so they are never swapped out.
+// Empty buffers are ignored.
+func Lock(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	spans := pages(b)
+	for i, s := range spans {
+		if lockedPage[s.page] > 0 {
+			continue
+		}
+		if err := lock(s.b); err != nil {
+			// Release the pages locked so far by this call
+			for _, s := range spans[:i] {
+				if lockedPage[s.page] == 0 {
+					unlock(s.b)
+				}
+			}
+			return err
+		}
+	}
+	for _, s := range spans {
+		lockedPage[s.page]++
+	}
+	lockedBufs[span{uintptr(unsafe.Pointer(&b[0])), len(b)}]++
+	return nil
+}
+
+// Unlock undoes Lock. Pages still holding another locked buffer stay
+// locked, and a buffer that was not locked is ignored. Buffers should be
+// wiped before they are unlocked.
+func Unlock(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	key := span{uintptr(unsafe.Pointer(&b[0])), len(b)}
+	if lockedBufs[key] == 0 {
+		return nil
+	}
+	if lockedBufs[key]--; lockedBufs[key] == 0 {
+		delete(lockedBufs, key)
+	}
+
+	var err error
+	for _, s := range pages(b) {
+		if lockedPage[s.page]--; lockedPage[s.page] > 0 {
+			continue
+		}
+		delete(lockedPage, s.page)
+		if e := unlock(s.b); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// WipeAndUnlock zeroes b and releases the lock taken by Lock.
+func WipeAndUnlock(b []byte) {
+	Wipe(b)
+	Unlock(b)
+}
+
+// DisableCoreDumps prevents the process from writing core dumps, which
+// would contain any secret in memory at the time of a crash.
+func DisableCoreDumps() error {
+	return disableCoreDumps()
+}
@@ -0,0 +1,87 @@
+package securemem
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWipe(t *testing.T) {
+	a, b := []byte("secret"), []byte{1, 2, 3}
+	Wipe(a, b, nil)
+	if !IsZero(a) || !IsZero(b) {
+		t.Errorf("Wipe() left %v and %v", a, b)
+	}
+	if len(a) != 6 {
+		t.Error("Wipe() changed the buffer length")
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	if !IsZero(nil) || !IsZero(make([]byte, 8)) || IsZero([]byte{0, 0, 1}) {
+		t.Error("IsZero() gave a wrong result")
+	}
+}
+
+func TestLock(t *testing.T) {
+	b := bytes.Repeat([]byte{0xaa}, 4096)
+	if err := Lock(b); err != nil {
+		// Unprivileged processes may have no memory lock allowance
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.ENOMEM) {
+			t.Skipf("cannot lock memory here: %v", err)
+		}
+		t.Fatalf("Lock() error = %v", err)
+	}
+	WipeAndUnlock(b)
+	if !IsZero(b) {
+		t.Error("WipeAndUnlock() did not wipe the buffer")
+	}
+	if err := Lock(nil); err != nil {
+		t.Errorf("Lock(nil) error = %v", err)
+	}
+}
+
+func TestLockSharedPage(t *testing.T) {
+	// Two buffers in the same page, as small heap objects often are
+	buf := make([]byte, 64)
+	a, b := buf[:32], buf[32:]
+	page := pages(a)[0].page
+	for _, x := range [][]byte{a, b} {
+		if err := Lock(x); err != nil {
+			if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.ENOMEM) {
+				t.Skipf("cannot lock memory here: %v", err)
+			}
+			t.Fatalf("Lock() error = %v", err)
+		}
+	}
+
+	WipeAndUnlock(a)
+	if lockedPage[page] != 1 {
+		t.Errorf("page lock count after unlocking one buffer = %d, want 1", lockedPage[page])
+	}
+	// Unlocking again, or a buffer that was never locked, changes nothing
+	Unlock(a)
+	Unlock(buf[8:16])
+	if lockedPage[page] != 1 {
+		t.Errorf("page lock count after spurious unlocks = %d, want 1", lockedPage[page])
+	}
+
+	WipeAndUnlock(b)
+	if _, ok := lockedPage[page]; ok || len(lockedBufs) != 0 {
+		t.Errorf("locks left after unlocking every buffer: %v, %v", lockedPage, lockedBufs)
+	}
+}
+
+func TestPages(t *testing.T) {
+	size := os.Getpagesize()
+	buf := make([]byte, 3*size)
+	spans := pages(buf[size/2 : size/2+size+1])
+	if len(spans) != 2 || len(spans[0].b)+len(spans[1].b) != size+1 {
+		t.Fatalf("pages() split a page-sized buffer into %d spans", len(spans))
+	}
+	if spans[1].page-spans[0].page != uintptr(size) {
+		t.Error("pages() returned spans that are not one page apart")
+	}
+}
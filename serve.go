@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Defaults of the serve command.
+const (
+	defaultServeAddr    = "127.0.0.1:7420"
+	defaultMaxBodyBytes = 1 << 20
+)
+
+// serveConfig holds the options of the serve command.
+type serveConfig struct {
+	Addr         string // Loopback TCP address to listen on
+	Socket       string // Unix socket path, used instead of Addr when set
+	TokenFile    string // File the bearer token is written to
+	MaxBodyBytes int64  // Largest accepted request body
+}
+
+// splitRequest is the body of POST /split.
+type splitRequest struct {
+	Secret       string `json:"secret"`       // Text secret
+	SecretBase64 string `json:"secretBase64"` // Binary secret, used instead of Secret when set
+	Shards       int    `json:"shards"`
+	ShardsNeeded int    `json:"shardsNeeded"`
+	Output       string `json:"output"` // Share encoding, "base64" when empty
+}
+
+// combineRequest is the body of POST /combine and POST /verify.
+type combineRequest struct {
+	Shards []string `json:"shards"`
+
+	// Secret is compared with the reconstruction by /verify, if set
+	Secret *string `json:"secret,omitempty"`
+}
+
+// verifyResult is the data of a successful POST /verify.
+type verifyResult struct {
+	Valid  bool  `json:"valid"`           // The shards combine without error
+	Shares int   `json:"shares"`          // Number of non-empty shards given
+	Size   int   `json:"size"`            // Length of the reconstructed secret
+	Match  *bool `json:"match,omitempty"` // Whether it equals the expected secret
+}
+
+// runServe implements "orcrux serve": it exposes split, combine and verify
+// as JSON endpoints on a loopback address or a Unix socket until interrupted.
+//
+// Parameters:
+//   - args: Command line arguments following "serve"
+//
+// Returns:
+//   - An error if the arguments are invalid, the token cannot be written or
+//     the server fails
+func runServe(args []string) error {
+	cfg, err := parseServeFlags(args)
+	if err != nil {
+		return err
+	}
+
+	token, err := writeServeToken(cfg.TokenFile)
+	if err != nil {
+		return err
+	}
+
+	ln, err := listenServe(cfg)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           newServeHandler(token, cfg.MaxBodyBytes),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "orcrux: serving on %s, bearer token in %s\n", ln.Addr(), cfg.TokenFile)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// parseServeFlags parses the serve command line.
+func parseServeFlags(args []string) (serveConfig, error) {
+	cfg := serveConfig{}
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.StringVar(&cfg.Addr, "addr", defaultServeAddr, "loopback address to listen on")
+	fs.StringVar(&cfg.Socket, "socket", "", "listen on this Unix socket instead of TCP")
+	fs.StringVar(&cfg.TokenFile, "token-file", "", "file to write the bearer token to (default in the user config directory)")
+	fs.Int64Var(&cfg.MaxBodyBytes, "max-body", defaultMaxBodyBytes, "largest accepted request body, in bytes")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		return cfg, errors.New("max-body must be positive")
+	}
+
+	if cfg.TokenFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return cfg, fmt.Errorf("cannot locate the config directory, set -token-file: %w", err)
+		}
+		cfg.TokenFile = filepath.Join(dir, "orcrux", "serve.token")
+	}
+	return cfg, nil
+}
+
+// listenServe opens the Unix socket or loopback TCP listener of cfg. TCP
+// addresses that are not loopback are refused, as the API hands out secrets.
+func listenServe(cfg serveConfig) (net.Listener, error) {
+	if cfg.Socket != "" {
+		// A socket left behind by a previous run would make Listen fail
+		if info, err := os.Lstat(cfg.Socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(cfg.Socket)
+		}
+		ln, err := net.Listen("unix", cfg.Socket)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(cfg.Socket, 0600); err != nil {
+			ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	}
+
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("refusing to listen on %s: only loopback addresses are allowed", cfg.Addr)
+	}
+	return net.Listen("tcp", cfg.Addr)
+}
+
+// writeServeToken generates a new bearer token and writes it to path, readable
+// only by the current user.
+func writeServeToken(path string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// newServeHandler returns the HTTP API. Every request must carry the bearer
+// token, use POST and have a body of at most maxBody bytes.
+func newServeHandler(token string, maxBody int64) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/split", handleServeSplit)
+	mux.HandleFunc("/combine", handleServeCombine)
+	mux.HandleFunc("/verify", handleServeVerify)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeServeResponse(w, http.StatusUnauthorized, nil, errors.New("missing or invalid bearer token"))
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeServeResponse(w, http.StatusMethodNotAllowed, nil, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func handleServeSplit(w http.ResponseWriter, r *http.Request) {
+	var req splitRequest
+	if !decodeServeRequest(w, r, &req) {
+		return
+	}
+
+	secret := []byte(req.Secret)
+	if req.SecretBase64 != "" {
+		var err error
+		if secret, err = base64.StdEncoding.DecodeString(req.SecretBase64); err != nil {
+			writeServeResponse(w, http.StatusBadRequest, nil, fmt.Errorf("secretBase64 is not valid base64: %w", err))
+			return
+		}
+	}
+	defer securemem.Wipe(secret)
+	if req.Output == "" {
+		req.Output = "base64"
+	}
+
+	out, err := shamir.Split(secret, req.Shards, req.ShardsNeeded, req.Output)
+	if err != nil {
+		writeServeResponse(w, http.StatusBadRequest, nil, err)
+		return
+	}
+	writeServeResponse(w, http.StatusOK, strings.Split(strings.TrimSpace(out), "\n"), nil)
+}
+
+func handleServeCombine(w http.ResponseWriter, r *http.Request) {
+	var req combineRequest
+	if !decodeServeRequest(w, r, &req) {
+		return
+	}
+
+	out, err := recomposeServeShards(req.Shards)
+	if err != nil {
+		writeServeResponse(w, http.StatusBadRequest, nil, err)
+		return
+	}
+	defer securemem.Wipe(out)
+	writeServeResponse(w, http.StatusOK, RecoveredSecret{
+		Data:        base64.StdEncoding.EncodeToString(out),
+		ContentType: http.DetectContentType(out),
+		Size:        len(out),
+	}, nil)
+}
+
+// handleServeVerify checks that shards combine, and optionally that they
+// combine to an expected secret, without returning the secret.
+func handleServeVerify(w http.ResponseWriter, r *http.Request) {
+	var req combineRequest
+	if !decodeServeRequest(w, r, &req) {
+		return
+	}
+
+	out, err := recomposeServeShards(req.Shards)
+	if err != nil {
+		writeServeResponse(w, http.StatusBadRequest, nil, err)
+		return
+	}
+	defer securemem.Wipe(out)
+
+	result := verifyResult{Valid: true, Size: len(out)}
+	for _, s := range req.Shards {
+		if strings.TrimSpace(s) != "" {
+			result.Shares++
+		}
+	}
+	if req.Secret != nil {
+		match := subtle.ConstantTimeCompare(out, []byte(*req.Secret)) == 1
+		result.Match = &match
+	}
+	writeServeResponse(w, http.StatusOK, result, nil)
+}
+
+// recomposeServeShards recomposes shards, which may carry dealer
+// signatures. The API has no pinned dealer key, so signatures are stripped
+// without being checked.
+func recomposeServeShards(shards []string) ([]byte, error) {
+	stripped, err := checkShardSignatures(nil, false, shards)
+	if err != nil {
+		return nil, err
+	}
+	return shamir.Recompose(stripped)
+}
+
+// decodeServeRequest decodes a JSON body into v, answering the request
+// itself and returning false if that fails.
+func decodeServeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeServeResponse(w, http.StatusRequestEntityTooLarge, nil, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+			return false
+		}
+		writeServeResponse(w, http.StatusBadRequest, nil, fmt.Errorf("invalid JSON body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeServeResponse writes data or err in the Response envelope used by the
+// desktop app.
+func writeServeResponse(w http.ResponseWriter, status int, data interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, newResponse(data, err))
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testServeToken = "test-token"
+
+// serveCall posts body to path on srv and decodes the Response envelope.
+func serveCall(t *testing.T, srv *httptest.Server, path, token, body string) (int, Response) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("POST %s error = %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("POST %s returned invalid JSON: %v", path, err)
+	}
+	return resp.StatusCode, response
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(newServeHandler(testServeToken, 4096))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServeSplitCombine(t *testing.T) {
+	srv := newTestServer(t)
+
+	status, split := serveCall(t, srv, "/split", testServeToken, `{"secret":"hunter2","shards":3,"shardsNeeded":2,"output":"hex"}`)
+	if status != http.StatusOK || split.Error != nil {
+		t.Fatalf("/split status = %d, error = %v", status, split.Error)
+	}
+	shards, ok := split.Data.([]interface{})
+	if !ok || len(shards) != 3 {
+		t.Fatalf("/split data = %v, want 3 shards", split.Data)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"shards": shards[1:]})
+	status, combined := serveCall(t, srv, "/combine", testServeToken, string(body))
+	if status != http.StatusOK || combined.Error != nil {
+		t.Fatalf("/combine status = %d, error = %v", status, combined.Error)
+	}
+	data := combined.Data.(map[string]interface{})
+	if data["data"] != base64.StdEncoding.EncodeToString([]byte("hunter2")) || data["size"] != float64(7) {
+		t.Errorf("/combine data = %v", data)
+	}
+}
+
+func TestServeSplitBinary(t *testing.T) {
+	srv := newTestServer(t)
+	secret := base64.StdEncoding.EncodeToString([]byte{0, 1, 2, 0xff})
+
+	status, split := serveCall(t, srv, "/split", testServeToken, `{"secretBase64":"`+secret+`","shards":2,"shardsNeeded":2}`)
+	if status != http.StatusOK {
+		t.Fatalf("/split status = %d, error = %v", status, split.Error)
+	}
+	if first := split.Data.([]interface{})[0].(string); !strings.HasPrefix(first, "01:base64:") {
+		t.Errorf("/split shard = %q, want base64 by default", first)
+	}
+}
+
+func TestServeVerify(t *testing.T) {
+	srv := newTestServer(t)
+	_, split := serveCall(t, srv, "/split", testServeToken, `{"secret":"hunter2","shards":3,"shardsNeeded":2,"output":"base64+pad"}`)
+	shards := split.Data.([]interface{})
+
+	body, _ := json.Marshal(map[string]interface{}{"shards": shards[:2], "secret": "hunter2"})
+	status, verified := serveCall(t, srv, "/verify", testServeToken, string(body))
+	if status != http.StatusOK {
+		t.Fatalf("/verify status = %d, error = %v", status, verified.Error)
+	}
+	result := verified.Data.(map[string]interface{})
+	if result["valid"] != true || result["shares"] != float64(2) || result["match"] != true {
+		t.Errorf("/verify data = %v", result)
+	}
+	if _, leaked := result["data"]; leaked {
+		t.Error("/verify must not return the secret")
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"shards": shards[:2], "secret": "hunter3"})
+	_, verified = serveCall(t, srv, "/verify", testServeToken, string(body))
+	if verified.Data.(map[string]interface{})["match"] != false {
+		t.Errorf("/verify with the wrong secret = %v, want match false", verified.Data)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"shards": []interface{}{shards[0], "02:base64+pad:!!"}})
+	status, verified = serveCall(t, srv, "/verify", testServeToken, string(body))
+	if status != http.StatusBadRequest || verified.Code != "invalid_share" || verified.Index == nil || *verified.Index != 1 {
+		t.Errorf("/verify with a bad shard status = %d, code = %q, index = %v", status, verified.Code, verified.Index)
+	}
+}
+
+func TestServeSignedShards(t *testing.T) {
+	srv := newTestServer(t)
+	key, _ := newDealerKey(t)
+	signed, err := signShards(key, strings.Join(splitShards(t, "hunter2", 3, 2, "hex"), "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := strings.Split(signed, "\n")
+
+	body, _ := json.Marshal(map[string]interface{}{"shards": shards[:2]})
+	status, combined := serveCall(t, srv, "/combine", testServeToken, string(body))
+	if status != http.StatusOK || combined.Error != nil {
+		t.Fatalf("/combine of signed shards status = %d, error = %v", status, combined.Error)
+	}
+	if data := combined.Data.(map[string]interface{}); data["data"] != base64.StdEncoding.EncodeToString([]byte("hunter2")) {
+		t.Errorf("/combine of signed shards data = %v", data)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"shards": shards[1:], "secret": "hunter2"})
+	status, verified := serveCall(t, srv, "/verify", testServeToken, string(body))
+	if status != http.StatusOK || verified.Data.(map[string]interface{})["match"] != true {
+		t.Errorf("/verify of signed shards status = %d, data = %v, error = %v", status, verified.Data, verified.Error)
+	}
+}
+
+func TestServeRejects(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   string
+		want   int
+	}{
+		{name: "no token", method: http.MethodPost, body: `{}`, want: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodPost, token: "guess", body: `{}`, want: http.StatusUnauthorized},
+		{name: "GET", method: http.MethodGet, token: testServeToken, want: http.StatusMethodNotAllowed},
+		{name: "too large", method: http.MethodPost, token: testServeToken, body: `{"secret":"` + strings.Repeat("a", 5000) + `"}`, want: http.StatusRequestEntityTooLarge},
+		{name: "bad JSON", method: http.MethodPost, token: testServeToken, body: `{"secret":`, want: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, token: testServeToken, body: `{"secrets":"x"}`, want: http.StatusBadRequest},
+		{name: "bad parameters", method: http.MethodPost, token: testServeToken, body: `{"secret":"x","shards":1,"shardsNeeded":2}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+"/split", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			var response Response
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("invalid JSON response: %v", err)
+			}
+			if resp.StatusCode != tt.want || response.Error == nil {
+				t.Errorf("status = %d, error = %v, want %d with an error", resp.StatusCode, response.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteServeToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "serve.token")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := writeServeToken(path)
+	if err != nil {
+		t.Fatalf("writeServeToken() error = %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("token has %d characters, want 64", len(token))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(path)
+	if strings.TrimSpace(string(data)) != token {
+		t.Error("token file does not hold the token")
+	}
+}
+
+func TestParseServeFlags(t *testing.T) {
+	cfg, err := parseServeFlags([]string{"-token-file", "/tmp/t"})
+	if err != nil {
+		t.Fatalf("parseServeFlags() error = %v", err)
+	}
+	if cfg.Addr != defaultServeAddr || cfg.MaxBodyBytes != defaultMaxBodyBytes {
+		t.Errorf("parseServeFlags() = %+v, want defaults", cfg)
+	}
+
+	for _, args := range [][]string{{"extra"}, {"-max-body", "0"}, {"-nope"}} {
+		if _, err := parseServeFlags(args); err == nil {
+			t.Errorf("parseServeFlags(%v) should have failed", args)
+		}
+	}
+}
+
+func TestListenServe(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "192.0.2.1:0", ":0"} {
+		if ln, err := listenServe(serveConfig{Addr: addr}); err == nil {
+			ln.Close()
+			t.Errorf("listenServe(%q) should refuse a non-loopback address", addr)
+		}
+	}
+
+	ln, err := listenServe(serveConfig{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("listenServe(loopback) error = %v", err)
+	}
+	ln.Close()
+}
+
+func TestListenServeUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "orx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "s")
+
+	ln, err := listenServe(serveConfig{Socket: socket})
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(newServeHandler(testServeToken, 4096))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	req, _ := http.NewRequest(http.MethodPost, "http://orcrux/split", strings.NewReader(`{"secret":"s","shards":2,"shardsNeeded":2}`))
+	req.Header.Set("Authorization", "Bearer "+testServeToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request over the socket error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status over the socket = %d, want 200", resp.StatusCode)
+	}
+}
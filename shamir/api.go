@@ -0,0 +1,245 @@
+package shamir
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"orcrux/securemem"
+	"strings"
+)
+
+// Share is one point of the sharing polynomials: an x-coordinate and the
+// value of each per-byte polynomial at that point.
+type Share struct {
+	X          byte     // Non-zero x-coordinate, unique within a set of shares
+	Data       []byte   // One y-value per byte of the transformed secret
+	Transforms []string // Stages applied to the secret before splitting, such as "deflate" or "pad"
+}
+
+// SplitOptions configures SplitShares.
+type SplitOptions struct {
+	Shares    int // Total number of shares to generate (2-255)
+	Threshold int // Number of shares required to reconstruct (2-Shares)
+
+	// Padding hides the length of the secret by padding it to a multiple of
+	// this many bytes (MinPadding-MaxPadding), or to the next power of two
+	// with PadPowerOfTwo. Zero disables padding.
+	Padding int
+
+	// Compress compresses the secret with DEFLATE before padding and
+	// splitting, which shortens the shares of large text secrets.
+	Compress bool
+}
+
+// Encoder turns share data into text. Name is written into the share as its
+// encoding tag, so it must not contain ':' or '+'.
+type Encoder interface {
+	Name() string
+	Encode(data []byte) string
+}
+
+// Decoder turns share text back into data. Name must match the tag written
+// by the corresponding Encoder.
+type Decoder interface {
+	Name() string
+	Decode(text string) ([]byte, error)
+}
+
+// Encoding is an Encoder and its matching Decoder.
+type Encoding interface {
+	Encoder
+	Decoder
+}
+
+type hexEncoding struct{}
+
+func (hexEncoding) Name() string { return "hex" }
+
+func (hexEncoding) Encode(data []byte) string { return hex.EncodeToString(data) }
+
+func (hexEncoding) Decode(text string) ([]byte, error) { return hex.DecodeString(text) }
+
+type base64Encoding struct{}
+
+func (base64Encoding) Name() string { return "base64" }
+
+func (base64Encoding) Encode(data []byte) string { return base64.StdEncoding.EncodeToString(data) }
+
+func (base64Encoding) Decode(text string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(text)
+}
+
+// Built-in encodings.
+var (
+	// Hex encodes share data as lowercase hexadecimal.
+	Hex Encoding = hexEncoding{}
+
+	// Base64 encodes share data as standard padded base64.
+	Base64 Encoding = base64Encoding{}
+)
+
+// legacyDecoders are the encodings untagged "xx:data" shares may use, in
+// the order they are tried.
+var legacyDecoders = []Decoder{Hex, Base64}
+
+// SplitShares splits a secret into opts.Shares shares, any opts.Threshold of
+// which reconstruct it with Combine.
+//
+// This is the typed counterpart of Split: shares are returned as values
+// rather than newline-joined text, and can be encoded with any Encoder using
+// Share.Format.
+func SplitShares(secret []byte, opts SplitOptions) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if err := validateShardCounts(opts.Shares, opts.Threshold); err != nil {
+		return nil, err
+	}
+	if err := validatePadding(opts.Padding); err != nil {
+		return nil, err
+	}
+
+	// The transformed copies of the secret are ours, and are wiped once the
+	// shares are computed. The caller's secret is left untouched.
+	var transforms []string
+	if opts.Compress {
+		compressed, err := deflate(secret)
+		if err != nil {
+			return nil, err
+		}
+		defer securemem.Wipe(compressed)
+		secret = compressed
+		transforms = append(transforms, "deflate")
+	}
+	if opts.Padding != 0 {
+		padded, err := pad(secret, opts.Padding)
+		if err != nil {
+			return nil, err
+		}
+		defer securemem.Wipe(padded)
+		secret = padded
+		transforms = append(transforms, "pad")
+	}
+
+	coeffs, err := randomCoefficients(len(secret), opts.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer securemem.Wipe(coeffs)
+
+	k := opts.Threshold - 1
+	shares := make([]Share, opts.Shares)
+	for i, x := range generateXCoordinates(opts.Shares) {
+		data := make([]byte, len(secret))
+		for b := range secret {
+			data[b] = evaluatePolynomial(secret[b], coeffs[b*k:(b+1)*k], x)
+		}
+		shares[i] = Share{X: x, Data: data, Transforms: transforms}
+	}
+	return shares, nil
+}
+
+// Combine reconstructs a secret from shares produced by SplitShares.
+//
+// Identical shares are merged, and transforms such as padding are undone.
+// Two different shares with the same x-coordinate, a zero x-coordinate,
+// shares of different lengths or with different transforms are reported as
+// a *ShareParseError holding the index of the offending share.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShards
+	}
+	decoded := make([]decodedShare, len(shares))
+	for i, s := range shares {
+		if s.X == 0 {
+			return nil, &ShareParseError{Index: i, Reason: "x-coordinate must not be 00"}
+		}
+		if len(s.Data) == 0 {
+			return nil, &ShareParseError{Index: i, Reason: "share contains no data"}
+		}
+		if err := checkTransforms(s.Transforms); err != nil {
+			return nil, &ShareParseError{Index: i, Reason: err.Error(), Err: err}
+		}
+		decoded[i] = decodedShare{index: i, x: s.X, y: s.Data, transforms: s.Transforms}
+	}
+	return combineDecoded(decoded)
+}
+
+// combineDecoded reconstructs and un-transforms a secret from decoded shares.
+func combineDecoded(shares []decodedShare) ([]byte, error) {
+	unique, err := dedupeShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	transforms, err := commonTransforms(unique)
+	if err != nil {
+		return nil, err
+	}
+	return undo(interpolateShares(unique), transforms)
+}
+
+// Format renders the share as "xx:name:data" using enc, with any transforms
+// appended to the name as in "xx:name+pad:data".
+func (s Share) Format(enc Encoder) string {
+	tag := strings.Join(append([]string{enc.Name()}, s.Transforms...), "+")
+	return fmt.Sprintf("%02x:%s:%s", s.X, tag, enc.Encode(s.Data))
+}
+
+// ParseShare parses a share in the "xx:name:data" form written by Format,
+// using the decoder whose name matches the tag. The registered encodings are
+// used when no decoders are given.
+func ParseShare(text string, decoders ...Decoder) (Share, error) {
+	if len(decoders) == 0 {
+		decoders = registeredDecoders()
+	}
+	x, tag, data, err := splitShareText(0, strings.TrimSpace(text))
+	if err != nil {
+		return Share{}, err
+	}
+	if tag == "" {
+		return Share{}, &ShareParseError{Index: 0, Reason: "share has no encoding tag"}
+	}
+	name, transforms := splitTag(tag)
+	if err := checkTransforms(transforms); err != nil {
+		return Share{}, &ShareParseError{Index: 0, Reason: err.Error(), Err: err}
+	}
+	y, err := decodeWith(decoders, name, data)
+	if err != nil {
+		return Share{}, &ShareParseError{Index: 0, Reason: err.Error(), Err: err}
+	}
+	return Share{X: x, Data: y, Transforms: transforms}, nil
+}
+
+// ParseLegacyShare parses a share like ParseShare, and also accepts the
+// untagged "xx:data" form of the original shard format. Untagged data is
+// read as hex when it is valid hex and as base64 otherwise, the choice
+// Recompose makes for shares valid in both.
+func ParseLegacyShare(text string) (Share, error) {
+	x, tag, data, err := splitShareText(0, strings.TrimSpace(text))
+	if err != nil {
+		return Share{}, err
+	}
+	if tag != "" {
+		return ParseShare(text)
+	}
+	for _, d := range legacyDecoders {
+		if y, err := d.Decode(data); err == nil && len(y) > 0 {
+			return Share{X: x, Data: y}, nil
+		}
+	}
+	return Share{}, &ShareParseError{Index: 0, Reason: "data is neither hex nor base64"}
+}
+
+// decodeWith decodes data with the decoder named tag, ignoring case.
+func decodeWith(decoders []Decoder, tag, data string) ([]byte, error) {
+	for _, d := range decoders {
+		if strings.EqualFold(d.Name(), tag) {
+			y, err := d.Decode(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s data: %w", tag, err)
+			}
+			return y, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown encoding %q", tag)
+}
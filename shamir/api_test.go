@@ -0,0 +1,128 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// upperHex is a custom encoding used to check that encoders are pluggable.
+type upperHex struct{}
+
+func (upperHex) Name() string { return "HEXUP" }
+
+func (upperHex) Encode(data []byte) string { return strings.ToUpper(Hex.Encode(data)) }
+
+func (upperHex) Decode(text string) ([]byte, error) { return Hex.Decode(strings.ToLower(text)) }
+
+func TestSplitSharesCombine(t *testing.T) {
+	secret := []byte("typed api secret")
+	shares, err := SplitShares(secret, SplitOptions{Shares: 5, Threshold: 3})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("SplitShares() returned %d shares, want 5", len(shares))
+	}
+
+	got, err := Combine([]Share{shares[4], shares[1], shares[2]})
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Combine() = %q, want %q", got, secret)
+	}
+}
+
+func TestSplitWrapsSplitShares(t *testing.T) {
+	secret := []byte("same shares")
+	shares, err := SplitShares(secret, SplitOptions{Shares: 3, Threshold: 2})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	text, err := Split(secret, 3, 2, "base64")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	// Coefficients are random, so the two splits only agree on their layout
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		got, err := ParseShare(line)
+		if err != nil {
+			t.Fatalf("ParseShare(%q) error = %v", line, err)
+		}
+		if got.X != shares[i].X || len(got.Data) != len(shares[i].Data) || !strings.HasPrefix(line, shares[i].Format(Base64)[:10]) {
+			t.Errorf("Split() line %d = %q, want the layout of %q", i, line, shares[i].Format(Base64))
+		}
+	}
+	if got, err := Recompose(lines[1:]); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("Recompose() = %q, %v, want %q", got, err, secret)
+	}
+}
+
+func TestShareFormatParse(t *testing.T) {
+	share := Share{X: 0x0a, Data: []byte{0xde, 0xad, 0xbe, 0xef}}
+
+	for _, enc := range []Encoding{Hex, Base64} {
+		got, err := ParseShare(share.Format(enc))
+		if err != nil {
+			t.Fatalf("ParseShare(%s) error = %v", enc.Name(), err)
+		}
+		if got.X != share.X || !bytes.Equal(got.Data, share.Data) {
+			t.Errorf("ParseShare(%s) = %+v, want %+v", enc.Name(), got, share)
+		}
+	}
+
+	custom := share.Format(upperHex{})
+	if custom != "0a:HEXUP:DEADBEEF" {
+		t.Errorf("Format(upperHex) = %q", custom)
+	}
+	if _, err := ParseShare(custom); err == nil {
+		t.Error("ParseShare() should not know a custom encoding by default")
+	}
+	got, err := ParseShare(custom, upperHex{})
+	if err != nil {
+		t.Fatalf("ParseShare(custom) error = %v", err)
+	}
+	if !bytes.Equal(got.Data, share.Data) {
+		t.Errorf("ParseShare(custom) = %x, want %x", got.Data, share.Data)
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	a := Share{X: 1, Data: []byte{1, 2}}
+	b := Share{X: 2, Data: []byte{3, 4}}
+
+	tests := []struct {
+		name   string
+		shares []Share
+		want   error
+	}{
+		{name: "no shares", shares: nil, want: ErrNoShards},
+		{name: "one distinct share", shares: []Share{a, a}, want: ErrTooFewShards},
+		{name: "conflicting duplicate", shares: []Share{a, b, {X: 1, Data: []byte{9, 9}}}, want: ErrDuplicateShard},
+		{name: "inconsistent length", shares: []Share{a, {X: 2, Data: []byte{3}}}, want: ErrInconsistentLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Combine(tt.shares); !errors.Is(err, tt.want) {
+				t.Errorf("Combine() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	var spe *ShareParseError
+	if _, err := Combine([]Share{a, {X: 0, Data: []byte{1, 1}}}); !errors.As(err, &spe) || spe.Index != 1 {
+		t.Errorf("Combine() error = %v, want a ShareParseError at index 1", err)
+	}
+}
+
+func TestSplitSharesErrors(t *testing.T) {
+	if _, err := SplitShares(nil, SplitOptions{Shares: 3, Threshold: 2}); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("SplitShares() error = %v, want %v", err, ErrEmptySecret)
+	}
+	if _, err := SplitShares([]byte("s"), SplitOptions{Shares: 3}); !errors.Is(err, ErrInvalidThreshold) {
+		t.Errorf("SplitShares() error = %v, want %v", err, ErrInvalidThreshold)
+	}
+}
@@ -0,0 +1,294 @@
+package shamir
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// registry holds the share encodings known by name, in registration order.
+var registry = struct {
+	sync.RWMutex
+	byName map[string]Encoding
+	names  []string
+}{byName: map[string]Encoding{}}
+
+func init() {
+	for _, enc := range []Encoding{Hex, Base64, Base32, Base58, ZBase32, Bech32, Grouped} {
+		if err := Register(enc); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register makes an encoding available to Split, Recompose and ParseShare
+// under its name. Names are case-insensitive and must be unique, non-empty
+// and free of ':', '+' and whitespace.
+func Register(enc Encoding) error {
+	name := strings.ToLower(enc.Name())
+	if name == "" || strings.ContainsAny(name, ":+ \t\r\n") {
+		return fmt.Errorf("invalid encoding name %q", enc.Name())
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.byName[name]; ok {
+		return fmt.Errorf("encoding %q is already registered", name)
+	}
+	registry.byName[name] = enc
+	registry.names = append(registry.names, name)
+	return nil
+}
+
+// Lookup returns the registered encoding with the given name.
+func Lookup(name string) (Encoding, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	enc, ok := registry.byName[strings.ToLower(strings.TrimSpace(name))]
+	return enc, ok
+}
+
+// Encodings returns the names of all registered encodings, in registration order.
+func Encodings() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]string(nil), registry.names...)
+}
+
+// registeredDecoders returns every registered encoding as a Decoder.
+func registeredDecoders() []Decoder {
+	registry.RLock()
+	defer registry.RUnlock()
+	decoders := make([]Decoder, len(registry.names))
+	for i, name := range registry.names {
+		decoders[i] = registry.byName[name]
+	}
+	return decoders
+}
+
+// stdEncoding adapts an encoding/base32 style codec to Encoding.
+type stdEncoding struct {
+	name string
+	enc  *base32.Encoding
+}
+
+func (e stdEncoding) Name() string { return e.name }
+
+func (e stdEncoding) Encode(data []byte) string { return e.enc.EncodeToString(data) }
+
+func (e stdEncoding) Decode(text string) ([]byte, error) { return e.enc.DecodeString(text) }
+
+// zbase32Alphabet is the human-oriented alphabet of z-base-32, which avoids
+// easily confused characters and favours the easier ones.
+const zbase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+var (
+	// Base32 encodes share data as RFC 4648 base32 with padding.
+	Base32 Encoding = stdEncoding{name: "base32", enc: base32.StdEncoding}
+
+	// ZBase32 encodes share data as unpadded z-base-32, designed to be read
+	// aloud and typed by people.
+	ZBase32 Encoding = stdEncoding{name: "zbase32", enc: base32.NewEncoding(zbase32Alphabet).WithPadding(base32.NoPadding)}
+
+	// Base58 encodes share data with the Bitcoin base58 alphabet.
+	Base58 Encoding = base58Encoding{}
+
+	// Bech32 encodes share data as a BIP 173 Bech32 string with the human
+	// readable part "orx", whose checksum detects typing mistakes.
+	Bech32 Encoding = bech32Encoding{hrp: "orx"}
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+type base58Encoding struct{}
+
+func (base58Encoding) Name() string { return "base58" }
+
+// Encode converts data to base58. Leading zero bytes become leading '1's.
+// The conversion is quadratic in the input length, which is fine for typed
+// shares but slow for very large secrets.
+func (base58Encoding) Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	// Big-endian base-58 digits, built by repeated multiply-and-add
+	digits := make([]byte, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+func (base58Encoding) Decode(text string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(text) && text[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	// Little-endian bytes, built by repeated multiply-and-add
+	var bytes []byte
+	for i := zeros; i < len(text); i++ {
+		carry := strings.IndexByte(base58Alphabet, text[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+// bech32Charset is the BIP 173 data alphabet.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+type bech32Encoding struct {
+	hrp string
+}
+
+func (bech32Encoding) Name() string { return "bech32" }
+
+// Encode converts data to Bech32. The BIP 173 limit of 90 characters is not
+// enforced, as shares are as long as the secret.
+func (e bech32Encoding) Encode(data []byte) string {
+	values := convertBits(data, 8, 5, true)
+	checksum := bech32Checksum(e.hrp, values)
+
+	var sb strings.Builder
+	sb.WriteString(e.hrp + "1")
+	for _, v := range append(values, checksum...) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String()
+}
+
+func (e bech32Encoding) Decode(text string) ([]byte, error) {
+	if strings.ToLower(text) != text && strings.ToUpper(text) != text {
+		return nil, errors.New("bech32 string mixes upper and lower case")
+	}
+	text = strings.ToLower(text)
+
+	sep := strings.LastIndexByte(text, '1')
+	if sep < 1 || len(text)-sep-1 < 6 {
+		return nil, errors.New("bech32 string is too short")
+	}
+	if hrp := text[:sep]; hrp != e.hrp {
+		return nil, fmt.Errorf("bech32 prefix is %q, expected %q", hrp, e.hrp)
+	}
+
+	values := make([]byte, 0, len(text)-sep-1)
+	for i := sep + 1; i < len(text); i++ {
+		v := strings.IndexByte(bech32Charset, text[i])
+		if v < 0 {
+			return nil, fmt.Errorf("illegal bech32 character %q", text[i])
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32ExpandHRP(e.hrp), values...)) != 1 {
+		return nil, errors.New("bech32 checksum mismatch, the share was mistyped")
+	}
+
+	data := convertBits(values[:len(values)-6], 5, 8, false)
+	if data == nil {
+		return nil, errors.New("bech32 data has invalid padding")
+	}
+	return data, nil
+}
+
+// bech32Polymod computes the BIP 173 checksum polynomial.
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32ExpandHRP expands the human readable part for checksum computation.
+func bech32ExpandHRP(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32Checksum returns the six checksum values for hrp and data.
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32ExpandHRP(hrp), data...)
+	mod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(mod>>(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups a sequence of from-bit values into to-bit values. It
+// returns nil if pad is false and the input does not end on a group boundary
+// with zero padding.
+func convertBits(data []byte, from, to uint, pad bool) []byte {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package shamir
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodingsRoundTrip(t *testing.T) {
+	inputs := [][]byte{{0}, {0, 0, 1}, []byte("Hello World!"), bytes.Repeat([]byte{0xff, 0x00, 0x7f}, 40)}
+	for _, name := range Encodings() {
+		enc, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("Lookup(%q) failed for a listed encoding", name)
+		}
+		for _, in := range inputs {
+			text := enc.Encode(in)
+			if strings.Contains(text, ":") {
+				t.Errorf("%s.Encode() contains ':': %q", name, text)
+			}
+			got, err := enc.Decode(text)
+			if err != nil {
+				t.Fatalf("%s.Decode(%q) error = %v", name, text, err)
+			}
+			if !bytes.Equal(got, in) {
+				t.Errorf("%s round trip = %x, want %x", name, got, in)
+			}
+		}
+	}
+}
+
+func TestBase58Vectors(t *testing.T) {
+	tests := map[string]string{
+		"Hello World!":     "2NEpo7TZRRrLZSi2U",
+		"\x00\x00\x01":     "112",
+		"":                 "",
+		"\x00\x00\x00\x00": "1111",
+	}
+	for in, want := range tests {
+		if got := Base58.Encode([]byte(in)); got != want {
+			t.Errorf("Base58.Encode(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := Base58.Decode("0OIl"); err == nil {
+		t.Error("Base58.Decode() should reject characters outside the alphabet")
+	}
+}
+
+func TestBech32Vectors(t *testing.T) {
+	// Valid strings from BIP 173
+	if _, err := (bech32Encoding{hrp: "a"}).Decode("A12UEL5L"); err != nil {
+		t.Errorf("Decode(A12UEL5L) error = %v", err)
+	}
+	valid := "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"
+	data, err := (bech32Encoding{hrp: "abcdef"}).Decode(valid)
+	if err != nil {
+		t.Fatalf("Decode(%s) error = %v", valid, err)
+	}
+	if got := (bech32Encoding{hrp: "abcdef"}).Encode(data); got != valid {
+		t.Errorf("Encode() = %q, want %q", got, valid)
+	}
+
+	share := Bech32.Encode([]byte("share data"))
+	if !strings.HasPrefix(share, "orx1") {
+		t.Errorf("Bech32.Encode() = %q, want the orx1 prefix", share)
+	}
+	typo := []byte(share)
+	typo[6] = bech32Charset[(strings.IndexByte(bech32Charset, typo[6])+1)%32]
+	for _, bad := range []string{string(typo), "OrX1" + share[4:], "bc1" + share[4:], "orx1qq"} {
+		if _, err := Bech32.Decode(bad); err == nil {
+			t.Errorf("Bech32.Decode(%q) should have returned an error", bad)
+		}
+	}
+}
+
+func TestZBase32Alphabet(t *testing.T) {
+	text := ZBase32.Encode([]byte("readable"))
+	for _, c := range text {
+		if !strings.ContainsRune(zbase32Alphabet, c) {
+			t.Errorf("ZBase32.Encode() produced %q outside the alphabet", c)
+		}
+	}
+	if strings.Contains(text, "=") {
+		t.Error("ZBase32.Encode() should not pad")
+	}
+}
+
+func TestSplitRecomposeAllEncodings(t *testing.T) {
+	secret := []byte("every registered encoding")
+	for _, name := range Encodings() {
+		out, err := Split(secret, 4, 3, name)
+		if err != nil {
+			t.Fatalf("Split(%s) error = %v", name, err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if !strings.HasPrefix(lines[0], "01:"+name+":") {
+			t.Errorf("Split(%s) share %q is not tagged", name, lines[0])
+		}
+		got, err := Recompose([]string{lines[3], lines[0], lines[1]})
+		if err != nil {
+			t.Fatalf("Recompose(%s) error = %v", name, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("Recompose(%s) = %q, want %q", name, got, secret)
+		}
+	}
+}
+
+type nameOnly struct {
+	hexEncoding
+	name string
+}
+
+func (n nameOnly) Name() string { return n.name }
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{"", "has:colon", "has+plus", "has space", "HEX"} {
+		if err := Register(nameOnly{name: name}); err == nil {
+			t.Errorf("Register(%q) should have returned an error", name)
+		}
+	}
+
+	if err := Register(nameOnly{name: "test-hex"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		delete(registry.byName, "test-hex")
+		registry.names = registry.names[:len(registry.names)-1]
+	})
+	if _, ok := Lookup("TEST-HEX"); !ok {
+		t.Error("Lookup() should be case-insensitive")
+	}
+	if _, err := ParseShare("01:test-hex:abcd"); err != nil {
+		t.Errorf("ParseShare() error = %v for a registered encoding", err)
+	}
+}
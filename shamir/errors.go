@@ -0,0 +1,82 @@
+package shamir
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by Split, Recompose and the streaming functions.
+// Callers should test for them with errors.Is, as they are usually wrapped in
+// a ParamError or a ShareParseError carrying more detail.
+var (
+	// ErrEmptySecret is returned when there is nothing to split.
+	ErrEmptySecret = errors.New("empty secret")
+
+	// ErrInvalidShardCount is returned when the number of shards is not in [2, 255].
+	ErrInvalidShardCount = errors.New("shards must be in [2, 255]")
+
+	// ErrInvalidThreshold is returned when shardsNeeded is not in [2, shards].
+	ErrInvalidThreshold = errors.New("shardsNeeded must be in [2, shards]")
+
+	// ErrInvalidOutput is returned for an output encoding that is not registered.
+	ErrInvalidOutput = errors.New("output must be a registered encoding")
+
+	// ErrNoShards is returned when Recompose is called without shards.
+	ErrNoShards = errors.New("no shards provided")
+
+	// ErrTooFewShards is returned when fewer than two usable shares are given.
+	ErrTooFewShards = errors.New("at least 2 shares are required for reconstruction")
+
+	// ErrMixedEncodings is returned when untagged shares use different encodings.
+	ErrMixedEncodings = errors.New("shares use different encodings")
+
+	// ErrDuplicateShard is returned when two different shares have the same
+	// x-coordinate. Identical shares given twice are merged instead.
+	ErrDuplicateShard = errors.New("duplicate shard")
+
+	// ErrInvalidPadding is returned for a padding bucket outside
+	// [MinPadding, MaxPadding].
+	ErrInvalidPadding = errors.New("padding must be in [32, 65536] bytes")
+
+	// ErrPaddingMismatch is returned when the length prefix of a padded
+	// secret does not match its contents after reconstruction.
+	ErrPaddingMismatch = errors.New("padded secret failed its length check")
+
+	// ErrDecompressedTooLarge is returned when a compressed secret expands
+	// beyond MaxDecompressedSize.
+	ErrDecompressedTooLarge = errors.New("decompressed secret exceeds the maximum size")
+
+	// ErrMixedTransforms is returned when shares were split with different
+	// transforms, such as padding.
+	ErrMixedTransforms = errors.New("shares use different transforms")
+
+	// ErrInconsistentLength is returned when shares do not all hold the same
+	// number of bytes.
+	ErrInconsistentLength = errors.New("shares have inconsistent lengths")
+)
+
+// ParamError reports a Split parameter outside its allowed range.
+type ParamError struct {
+	Name  string // Parameter name, "shards", "shardsNeeded" or "padding"
+	Value int    // Value that was given
+	Min   int    // Smallest allowed value
+	Max   int    // Largest allowed value
+	Err   error  // ErrInvalidShardCount, ErrInvalidThreshold or ErrInvalidPadding
+}
+
+func (e *ParamError) Error() string { return e.Err.Error() }
+
+func (e *ParamError) Unwrap() error { return e.Err }
+
+// ShareParseError reports a share that cannot be used for reconstruction.
+type ShareParseError struct {
+	Index  int    // Position of the share in the input
+	Reason string // Human-readable description of the problem
+	Err    error  // Underlying sentinel or decoding error, if any
+}
+
+func (e *ShareParseError) Error() string {
+	return fmt.Sprintf("invalid share at index %d: %s", e.Index, e.Reason)
+}
+
+func (e *ShareParseError) Unwrap() error { return e.Err }
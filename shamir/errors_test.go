@@ -0,0 +1,84 @@
+package shamir
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplitErrorsIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret []byte
+		n, t   int
+		output string
+		want   error
+	}{
+		{name: "empty secret", secret: nil, n: 3, t: 2, output: "hex", want: ErrEmptySecret},
+		{name: "too many shards", secret: []byte("s"), n: 256, t: 2, output: "hex", want: ErrInvalidShardCount},
+		{name: "threshold above shards", secret: []byte("s"), n: 3, t: 4, output: "hex", want: ErrInvalidThreshold},
+		{name: "unknown output", secret: []byte("s"), n: 3, t: 2, output: "rot13", want: ErrInvalidOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Split(tt.secret, tt.n, tt.t, tt.output)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Split() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamError(t *testing.T) {
+	_, err := Split([]byte("s"), 5, 6, "hex")
+
+	var pe *ParamError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Split() error = %T, want *ParamError", err)
+	}
+	if pe.Name != "shardsNeeded" || pe.Value != 6 || pe.Min != 2 || pe.Max != 5 {
+		t.Errorf("ParamError = %+v", pe)
+	}
+	if err.Error() != "shardsNeeded must be in [2, shards]" {
+		t.Errorf("Error() = %q, message changed", err.Error())
+	}
+}
+
+func TestRecomposeShareParseError(t *testing.T) {
+	tests := []struct {
+		name      string
+		shards    []string
+		wantIndex int
+		wantIs    error
+	}{
+		{name: "malformed share", shards: []string{"01:616263", "02:646566", "garbage"}, wantIndex: 2},
+		{name: "conflicting duplicate", shards: []string{"01:616263", "02:646566", "01:616264"}, wantIndex: 2, wantIs: ErrDuplicateShard},
+		{name: "mixed encodings", shards: []string{"01:616263", "02:YWJj"}, wantIndex: 1, wantIs: ErrMixedEncodings},
+		{name: "inconsistent length", shards: []string{"01:616263", "02:6465"}, wantIndex: 1, wantIs: ErrInconsistentLength},
+		{name: "zero x-coordinate", shards: []string{"01:616263", "00:646566"}, wantIndex: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Recompose(tt.shards)
+
+			var spe *ShareParseError
+			if !errors.As(err, &spe) {
+				t.Fatalf("Recompose() error = %v, want *ShareParseError", err)
+			}
+			if spe.Index != tt.wantIndex {
+				t.Errorf("ShareParseError.Index = %d, want %d", spe.Index, tt.wantIndex)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("Recompose() error = %v, want %v", err, tt.wantIs)
+			}
+		})
+	}
+}
+
+func TestRecomposeSentinels(t *testing.T) {
+	if _, err := Recompose(nil); !errors.Is(err, ErrNoShards) {
+		t.Errorf("Recompose(nil) error = %v, want %v", err, ErrNoShards)
+	}
+	if _, err := Recompose([]string{"01:616263"}); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("Recompose() with one shard error = %v, want %v", err, ErrTooFewShards)
+	}
+}
@@ -0,0 +1,132 @@
+package shamir
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout of the grouped encoding.
+const (
+	groupDataChars     = 4 // z-base-32 data characters per block
+	groupBlocksPerLine = 4
+	groupLineSep       = " / "
+)
+
+// Grouped encodes share data for reading aloud or copying by hand.
+//
+// The data is written in z-base-32 and cut into blocks of four characters,
+// each followed by a Luhn mod 32 check character. Lines of four blocks end
+// with a two character checksum over the line, and are separated by " / " so
+// that a share stays on a single line of text:
+//
+//	cpzzw rhufg cp4nh y4dxk m1 / qj3s4 kedn9 cf48n e3m1i hb / xrj 4w
+//
+// Decode reports the line and block of a transcription error as a
+// *TranscriptionError.
+var Grouped Encoding = groupedEncoding{}
+
+// TranscriptionError locates a mistake in a share written with Grouped.
+type TranscriptionError struct {
+	Line   int // 1-based line number
+	Block  int // 1-based block number within the line, 0 for the line checksum
+	Reason string
+}
+
+func (e *TranscriptionError) Error() string {
+	if e.Block == 0 {
+		return fmt.Sprintf("line %d checksum: %s", e.Line, e.Reason)
+	}
+	return fmt.Sprintf("line %d, block %d: %s", e.Line, e.Block, e.Reason)
+}
+
+// groupedLookalikes maps characters left out of z-base-32 to the character a
+// reader most likely meant.
+var groupedLookalikes = strings.NewReplacer("0", "o", "l", "1", "v", "u", "2", "z")
+
+type groupedEncoding struct{}
+
+func (groupedEncoding) Name() string { return "grouped" }
+
+func (groupedEncoding) Encode(data []byte) string {
+	text := ZBase32.Encode(data)
+
+	var blocks []string
+	for len(text) > 0 {
+		n := min(groupDataChars, len(text))
+		blocks = append(blocks, text[:n]+string(zbase32Alphabet[luhnMod32(text[:n])]))
+		text = text[n:]
+	}
+
+	var lines []string
+	for i := 0; i < len(blocks); i += groupBlocksPerLine {
+		line := blocks[i:min(i+groupBlocksPerLine, len(blocks))]
+		lines = append(lines, strings.Join(line, " ")+" "+lineChecksum(len(lines)+1, line))
+	}
+	return strings.Join(lines, groupLineSep)
+}
+
+// Decode checks every block and line before decoding, so the first error
+// points at the block that was mistyped rather than at the whole share.
+func (groupedEncoding) Decode(text string) ([]byte, error) {
+	text = groupedLookalikes.Replace(strings.ToLower(text))
+	lines := strings.Split(text, "/")
+
+	var data strings.Builder
+	for l, line := range lines {
+		lineNo := l + 1
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, &TranscriptionError{Line: lineNo, Reason: "expected blocks followed by a checksum"}
+		}
+		blocks, checksum := fields[:len(fields)-1], fields[len(fields)-1]
+		last := l == len(lines)-1
+		if !last && len(blocks) != groupBlocksPerLine {
+			return nil, &TranscriptionError{Line: lineNo, Block: len(blocks), Reason: fmt.Sprintf("expected %d blocks, got %d", groupBlocksPerLine, len(blocks))}
+		}
+
+		for b, block := range blocks {
+			blockNo := b + 1
+			if i := strings.IndexFunc(block, func(r rune) bool { return !strings.ContainsRune(zbase32Alphabet, r) }); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(block[i:])
+				return nil, &TranscriptionError{Line: lineNo, Block: blockNo, Reason: fmt.Sprintf("invalid character %q", r)}
+			}
+			final := last && b == len(blocks)-1
+			if (!final && len(block) != groupDataChars+1) || len(block) < 2 || len(block) > groupDataChars+1 {
+				return nil, &TranscriptionError{Line: lineNo, Block: blockNo, Reason: fmt.Sprintf("expected %d characters, got %d", groupDataChars+1, len(block))}
+			}
+			payload, check := block[:len(block)-1], block[len(block)-1]
+			if zbase32Alphabet[luhnMod32(payload)] != check {
+				return nil, &TranscriptionError{Line: lineNo, Block: blockNo, Reason: "check character mismatch"}
+			}
+			data.WriteString(payload)
+		}
+
+		if len(checksum) != 2 || checksum != lineChecksum(lineNo, blocks) {
+			return nil, &TranscriptionError{Line: lineNo, Reason: "mismatch, blocks are missing or out of order"}
+		}
+	}
+
+	return ZBase32.Decode(data.String())
+}
+
+// luhnMod32 returns the index of the Luhn mod N check character for s,
+// which catches any single substitution and most adjacent transpositions.
+// s must only contain z-base-32 characters.
+func luhnMod32(s string) int {
+	factor, sum := 2, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(zbase32Alphabet, s[i])
+		sum += addend/32 + addend%32
+		factor = 3 - factor
+	}
+	return (32 - sum%32) % 32
+}
+
+// lineChecksum returns two z-base-32 characters of a CRC-32 over the line
+// number and its blocks, catching missing, repeated or swapped blocks.
+func lineChecksum(line int, blocks []string) string {
+	sum := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%d:%s", line, strings.Join(blocks, " "))))
+	return string([]byte{zbase32Alphabet[sum>>5&31], zbase32Alphabet[sum&31]})
+}
@@ -0,0 +1,119 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGroupedLayout(t *testing.T) {
+	text := Grouped.Encode([]byte("correct horse battery"))
+	want := "cpzzw rhufg cp4nh y4dxk m1 / qj3s4 kedn9 cf48n e3m1i hb / xrj 4w"
+	if text != want {
+		t.Errorf("Grouped.Encode() = %q, want %q", text, want)
+	}
+}
+
+func TestGroupedDecodeForgiving(t *testing.T) {
+	secret := []byte("correct horse battery")
+	text := Grouped.Encode(secret)
+
+	variants := map[string]string{
+		"upper case":     strings.ToUpper(text),
+		"extra spaces":   strings.ReplaceAll(text, " ", "   "),
+		"tight slashes":  strings.ReplaceAll(text, " / ", "/"),
+		"lookalike zero": strings.ReplaceAll(text, "o", "0"),
+	}
+	for name, v := range variants {
+		t.Run(name, func(t *testing.T) {
+			got, err := Grouped.Decode(v)
+			if err != nil {
+				t.Fatalf("Decode(%q) error = %v", v, err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Errorf("Decode(%q) = %q, want %q", v, got, secret)
+			}
+		})
+	}
+}
+
+func TestGroupedLocatesErrors(t *testing.T) {
+	text := Grouped.Encode([]byte("correct horse battery"))
+	// cpzzw rhufg cp4nh y4dxk m1 / qj3s4 kedn9 cf48n e3m1i hb / xrj 4w
+
+	tests := []struct {
+		name      string
+		text      string
+		wantLine  int
+		wantBlock int
+	}{
+		{name: "substitution", text: strings.Replace(text, "kedn9", "kedm9", 1), wantLine: 2, wantBlock: 2},
+		{name: "transposition", text: strings.Replace(text, "rhufg", "hrufg", 1), wantLine: 1, wantBlock: 2},
+		{name: "dropped character", text: strings.Replace(text, "cf48n", "cf4n", 1), wantLine: 2, wantBlock: 3},
+		{name: "invalid character", text: strings.Replace(text, "y4dxk", "y4d!k", 1), wantLine: 1, wantBlock: 4},
+		{name: "swapped blocks", text: strings.Replace(text, "cpzzw rhufg", "rhufg cpzzw", 1), wantLine: 1},
+		{name: "missing block", text: strings.Replace(text, "e3m1i ", "", 1), wantLine: 2, wantBlock: 3},
+		{name: "swapped lines", text: "qj3s4 kedn9 cf48n e3m1i hb / cpzzw rhufg cp4nh y4dxk m1 / xrj 4w", wantLine: 1},
+		{name: "missing checksum", text: strings.Replace(text, " 4w", "", 1), wantLine: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Grouped.Decode(tt.text)
+
+			var te *TranscriptionError
+			if !errors.As(err, &te) {
+				t.Fatalf("Decode(%q) error = %v, want *TranscriptionError", tt.text, err)
+			}
+			if te.Line != tt.wantLine || te.Block != tt.wantBlock {
+				t.Errorf("error at line %d block %d, want line %d block %d (%v)", te.Line, te.Block, tt.wantLine, tt.wantBlock, err)
+			}
+		})
+	}
+}
+
+func TestGroupedRecomposeReportsShareAndBlock(t *testing.T) {
+	out, err := Split([]byte("correct horse battery staple"), 3, 2, "grouped")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	shards := strings.Split(out, "\n")
+	if !strings.HasPrefix(shards[0], "01:grouped:") {
+		t.Fatalf("shard = %q, want a grouped share", shards[0])
+	}
+
+	// Mistype the first character of the second line of the second share
+	line2 := strings.Index(shards[1], "/ ") + 2
+	c := shards[1][line2]
+	typo := zbase32Alphabet[(strings.IndexByte(zbase32Alphabet, c)+1)%32]
+	shards[1] = shards[1][:line2] + string(typo) + shards[1][line2+1:]
+
+	_, err = Recompose(shards)
+	var spe *ShareParseError
+	var te *TranscriptionError
+	if !errors.As(err, &spe) || !errors.As(err, &te) {
+		t.Fatalf("Recompose() error = %v, want a ShareParseError wrapping a TranscriptionError", err)
+	}
+	if spe.Index != 1 || te.Line != 2 || te.Block != 1 {
+		t.Errorf("error at share %d line %d block %d, want share 1 line 2 block 1", spe.Index, te.Line, te.Block)
+	}
+	if !strings.Contains(err.Error(), "line 2, block 1") {
+		t.Errorf("error message %q does not name the block", err.Error())
+	}
+}
+
+func TestLuhnMod32(t *testing.T) {
+	// Appending the check character must make the sum validate to zero
+	for _, s := range []string{"y", "ybnd", "9999", "cpzz"} {
+		full := s + string(zbase32Alphabet[luhnMod32(s)])
+		factor, sum := 1, 0
+		for i := len(full) - 1; i >= 0; i-- {
+			addend := factor * strings.IndexByte(zbase32Alphabet, full[i])
+			sum += addend/32 + addend%32
+			factor = 3 - factor
+		}
+		if sum%32 != 0 {
+			t.Errorf("%q does not validate, sum %d", full, sum)
+		}
+	}
+}
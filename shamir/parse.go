@@ -0,0 +1,187 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// decodedShare is a share whose data has been decoded.
+type decodedShare struct {
+	index      int // Position of the share in the input
+	x          byte
+	y          []byte
+	transforms []string
+}
+
+// pendingShare is a legacy "xx:data" share whose encoding is not yet known.
+type pendingShare struct {
+	index      int
+	x          byte
+	data       string
+	candidates map[string][]byte // Encoding name to decoded data
+}
+
+// splitShareText splits a share into its x-coordinate, encoding tag and
+// data. Legacy shares of the form "xx:data" have an empty tag.
+func splitShareText(index int, s string) (x byte, tag, data string, err error) {
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+		data = parts[1]
+	case 3:
+		tag, data = strings.ToLower(parts[1]), parts[2]
+	default:
+		return 0, "", "", &ShareParseError{Index: index, Reason: fmt.Sprintf("expected \"xx:encoding:data\", got %q", s)}
+	}
+
+	xHex := parts[0]
+	if len(xHex) != 2 {
+		return 0, "", "", &ShareParseError{Index: index, Reason: fmt.Sprintf("x-coordinate must be 2 hex digits, got %q", xHex)}
+	}
+	xBytes, err := hex.DecodeString(xHex)
+	if err != nil || xBytes[0] == 0 {
+		return 0, "", "", &ShareParseError{Index: index, Reason: fmt.Sprintf("invalid x-coordinate %q", xHex), Err: err}
+	}
+	if data == "" {
+		return 0, "", "", &ShareParseError{Index: index, Reason: "share contains no data"}
+	}
+	return xBytes[0], tag, data, nil
+}
+
+// parseShares decodes every share independently and returns one share per
+// distinct x-coordinate.
+//
+// Tagged shares ("xx:encoding:data") are decoded with their own encoding.
+// Legacy shares ("xx:data") are only accepted when a single encoding decodes
+// all of them to the length of the other shares. When several encodings fit,
+// they are read as hex, as the original shard format was. Identical shares
+// are merged, and two different shares with the same x-coordinate are an
+// error.
+func parseShares(shards []string) ([]decodedShare, error) {
+	if len(shards) == 0 {
+		return nil, ErrNoShards
+	}
+
+	var decoded []decodedShare
+	var pending []pendingShare
+	for i, s := range shards {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		x, tag, data, err := splitShareText(i, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag != "" {
+			name, transforms := splitTag(tag)
+			if err := checkTransforms(transforms); err != nil {
+				return nil, &ShareParseError{Index: i, Reason: err.Error(), Err: err}
+			}
+			y, err := decodeWith(registeredDecoders(), name, data)
+			if err != nil {
+				return nil, &ShareParseError{Index: i, Reason: err.Error(), Err: err}
+			}
+			decoded = append(decoded, decodedShare{index: i, x: x, y: y, transforms: transforms})
+			continue
+		}
+
+		p := pendingShare{index: i, x: x, data: data, candidates: map[string][]byte{}}
+		for _, d := range legacyDecoders {
+			if y, err := d.Decode(data); err == nil && len(y) > 0 {
+				p.candidates[d.Name()] = y
+			}
+		}
+		if len(p.candidates) == 0 {
+			return nil, &ShareParseError{Index: i, Reason: "data is neither hex nor base64"}
+		}
+		pending = append(pending, p)
+	}
+
+	legacy, err := resolveLegacyShares(pending, decoded)
+	if err != nil {
+		return nil, err
+	}
+	decoded = append(decoded, legacy...)
+
+	return dedupeShares(decoded)
+}
+
+// resolveLegacyShares picks an encoding that decodes every untagged share,
+// consistently with the length of the tagged shares if any. Data valid in
+// several encodings is read with the first of legacyDecoders, hex, as the
+// original shard format did.
+func resolveLegacyShares(pending []pendingShare, tagged []decodedShare) ([]decodedShare, error) {
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	length := -1
+	if len(tagged) > 0 {
+		length = len(tagged[0].y)
+	}
+
+	var remaining []string
+	for _, d := range legacyDecoders {
+		remaining = append(remaining, d.Name())
+	}
+	for _, p := range pending {
+		var kept []string
+		for _, enc := range remaining {
+			if y, ok := p.candidates[enc]; ok && (length < 0 || len(y) == length) {
+				kept = append(kept, enc)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, &ShareParseError{Index: p.index, Reason: "share is not in the same encoding as the other shares", Err: ErrMixedEncodings}
+		}
+		remaining = kept
+	}
+
+	shares := make([]decodedShare, len(pending))
+	for i, p := range pending {
+		shares[i] = decodedShare{index: p.index, x: p.x, y: p.candidates[remaining[0]]}
+	}
+	return shares, nil
+}
+
+// dedupeShares merges identical shares, rejects conflicting ones and checks
+// that all shares have the same length. Shares keep their input order.
+func dedupeShares(shares []decodedShare) ([]decodedShare, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShards
+	}
+
+	byIndex := append([]decodedShare(nil), shares...)
+	sort.Slice(byIndex, func(i, j int) bool { return byIndex[i].index < byIndex[j].index })
+
+	length := len(byIndex[0].y)
+	seen := make(map[byte]decodedShare, len(byIndex))
+	unique := make([]decodedShare, 0, len(byIndex))
+	for _, s := range byIndex {
+		if len(s.y) != length {
+			return nil, &ShareParseError{Index: s.index, Reason: fmt.Sprintf("inconsistent length: got %d, expected %d", len(s.y), length), Err: ErrInconsistentLength}
+		}
+		if prev, ok := seen[s.x]; ok {
+			if bytes.Equal(prev.y, s.y) {
+				continue
+			}
+			return nil, &ShareParseError{
+				Index:  s.index,
+				Reason: fmt.Sprintf("x-coordinate %02x conflicts with the different share at index %d", s.x, prev.index),
+				Err:    ErrDuplicateShard,
+			}
+		}
+		seen[s.x] = s
+		unique = append(unique, s)
+	}
+
+	if len(unique) < 2 {
+		return nil, ErrTooFewShards
+	}
+	return unique, nil
+}
@@ -0,0 +1,139 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSharesTagged(t *testing.T) {
+	// "12345678" is valid hex and valid base64; the tag decides
+	shares, err := parseShares([]string{"01:base64:12345678", "02:hex:a1b2c3d4e5f6"})
+	if err != nil {
+		t.Fatalf("parseShares() error = %v", err)
+	}
+	if len(shares) != 2 {
+		t.Fatalf("parseShares() returned %d shares, want 2", len(shares))
+	}
+	if want := []byte{0xd7, 0x6d, 0xf8, 0xe7, 0xae, 0xfc}; !bytes.Equal(shares[0].y, want) {
+		t.Errorf("base64 share decoded to %x, want %x", shares[0].y, want)
+	}
+}
+
+func TestParseSharesLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		shards  []string
+		wantErr error
+	}{
+		{name: "only valid as hex", shards: []string{"01:616263", "02:646566"}},
+		{name: "only valid as base64", shards: []string{"01:YWJj", "02:ZGVm"}},
+		{name: "resolved by a tagged share", shards: []string{"01:hex:61626364", "02:65666768"}},
+		{name: "valid as both", shards: []string{"01:61626364", "02:65666768"}},
+		{name: "mixed", shards: []string{"01:616263", "02:ZGVm"}, wantErr: ErrMixedEncodings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseShares(tt.shards)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("parseShares() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseShares() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSharesLegacyPrefersHex(t *testing.T) {
+	shares, err := parseShares([]string{"01:61626364", "02:65666768"})
+	if err != nil {
+		t.Fatalf("parseShares() error = %v", err)
+	}
+	if string(shares[0].y) != "abcd" {
+		t.Errorf("share valid as hex and base64 decoded to %x, want it read as hex", shares[0].y)
+	}
+}
+
+func TestParseLegacyShare(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "01:61626364", want: "abcd"},
+		{text: "02:YWJj", want: "abc"},
+		{text: "03:base64:YWJj", want: "abc"},
+	}
+	for _, tt := range tests {
+		share, err := ParseLegacyShare(tt.text)
+		if err != nil {
+			t.Fatalf("ParseLegacyShare(%q) error = %v", tt.text, err)
+		}
+		if string(share.Data) != tt.want {
+			t.Errorf("ParseLegacyShare(%q) data = %q, want %q", tt.text, share.Data, tt.want)
+		}
+	}
+	for _, text := range []string{"01:!!!", "01:rot13:abc", "abc"} {
+		if _, err := ParseLegacyShare(text); err == nil {
+			t.Errorf("ParseLegacyShare(%q) should have returned an error", text)
+		}
+	}
+}
+
+func TestRecomposeBaselineShares(t *testing.T) {
+	// Shards of "test" from the original format, which wrote untagged hex.
+	// Their data is also valid base64.
+	got, err := Recompose([]string{"01:9ab59f9a", "03:5d0e5c5d"})
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if string(got) != "test" {
+		t.Errorf("Recompose() = %q, want %q", got, "test")
+	}
+}
+
+func TestParseSharesDuplicates(t *testing.T) {
+	// The same share pasted twice, once with a different encoding, is merged
+	shares, err := parseShares([]string{"01:hex:616263", "02:hex:646566", "01:base64:YWJj"})
+	if err != nil {
+		t.Fatalf("parseShares() error = %v", err)
+	}
+	if len(shares) != 2 {
+		t.Errorf("parseShares() returned %d shares, want 2 after merging duplicates", len(shares))
+	}
+
+	if _, err := parseShares([]string{"01:hex:616263", "01:hex:616263"}); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("parseShares() error = %v, want %v for one distinct share", err, ErrTooFewShards)
+	}
+}
+
+func TestParseSharesErrors(t *testing.T) {
+	for _, shards := range [][]string{
+		{"01:rot13:abc", "02:hex:646566"},
+		{"01:hex:zz", "02:hex:646566"},
+		{"01:hex:", "02:hex:646566"},
+		{"01:a:b:c", "02:hex:646566"},
+	} {
+		if _, err := parseShares(shards); err == nil {
+			t.Errorf("parseShares(%q) should have returned an error", strings.Join(shards, ", "))
+		}
+	}
+}
+
+func TestRecomposeDuplicateShard(t *testing.T) {
+	secret := []byte("pasted twice")
+	out, err := Split(secret, 3, 2, "base64")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	got, err := Recompose([]string{lines[0], lines[0], lines[2]})
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Recompose() = %q, want %q", got, secret)
+	}
+}
@@ -1,28 +1,33 @@
 package shamir
 
 import (
-	"encoding/base64"
-	"encoding/hex"
-	"errors"
-	"fmt"
+	"crypto/rand"
+	"orcrux/securemem"
 	"strings"
 )
 
 // validateShamirParams validates the input parameters for Shamir's Secret Sharing
 func validateShamirParams(secret []byte, n, t int, output string) error {
 	if len(secret) == 0 {
-		return errors.New("empty secret")
+		return ErrEmptySecret
 	}
-	if n < 2 || n > 255 {
-		return errors.New("shards must be in [2, 255]")
+	if err := validateShardCounts(n, t); err != nil {
+		return err
 	}
-	if t < 2 || t > n {
-		return errors.New("shardsNeeded must be in [2, shards]")
+
+	if _, _, err := parseOutput(output); err != nil {
+		return err
 	}
+	return nil
+}
 
-	enc := strings.ToLower(strings.TrimSpace(output))
-	if enc != "hex" && enc != "base64" {
-		return fmt.Errorf("output must be 'hex' or 'base64', got: %q", output)
+// validateShardCounts validates the number of shards and the reconstruction threshold
+func validateShardCounts(n, t int) error {
+	if n < 2 || n > 255 {
+		return &ParamError{Name: "shards", Value: n, Min: 2, Max: 255, Err: ErrInvalidShardCount}
+	}
+	if t < 2 || t > n {
+		return &ParamError{Name: "shardsNeeded", Value: t, Min: 2, Max: n, Err: ErrInvalidThreshold}
 	}
 	return nil
 }
@@ -36,42 +41,37 @@ func generateXCoordinates(n int) []byte {
 	return xs
 }
 
-// evaluatePolynomial evaluates a polynomial of degree t-1 at point x using Horner's method
-func evaluatePolynomial(secretByte byte, x byte, t int) (byte, error) {
-	coeffs := make([]byte, t)
-	coeffs[0] = secretByte
-
-	// Use deterministic coefficients based on the secret byte and position
-	// This ensures reconstruction works while maintaining security
-	for i := 1; i < t; i++ {
-		// Generate deterministic coefficient: hash(secretByte + position + i)
-		coeffs[i] = deterministicCoeff(secretByte, byte(i), byte(t))
-	}
-
-	// Horner's method evaluation in GF(256)
-	y := coeffs[t-1]
-	for k := t - 2; k >= 0; k-- {
-		y = gfMul(y, x)
-		y ^= coeffs[k]
+// randomCoefficients reads from crypto/rand the t-1 higher coefficients of
+// the polynomial of each of n secret bytes, laid out byte after byte.
+//
+// The coefficients must be uniformly random and used for a single split:
+// fewer than t shares then reveal nothing about the secret, and splitting
+// the same secret twice gives unrelated shares.
+func randomCoefficients(n, t int) ([]byte, error) {
+	coeffs := make([]byte, n*(t-1))
+	if _, err := rand.Read(coeffs); err != nil {
+		return nil, err
 	}
-	return y, nil
+	return coeffs, nil
 }
 
-// deterministicCoeff generates a deterministic coefficient for Shamir's Secret Sharing
-// This ensures reconstruction works while maintaining security properties
-func deterministicCoeff(secretByte, pos, t byte) byte {
-	// Simple deterministic function: (secretByte + pos + t) * (pos + 1) mod 256
-	// This provides good distribution while being deterministic
-	result := byte((int(secretByte) + int(pos) + int(t)) * (int(pos) + 1))
-	return result
+// evaluatePolynomial evaluates at point x, using Horner's method in GF(256),
+// the polynomial whose constant term is secretByte and whose higher
+// coefficients are coeffs, lowest degree first.
+func evaluatePolynomial(secretByte byte, coeffs []byte, x byte) byte {
+	var y byte
+	for k := len(coeffs) - 1; k >= 0; k-- {
+		y = gfMul(y, x) ^ coeffs[k]
+	}
+	return gfMul(y, x) ^ secretByte
 }
 
 // encodeShare encodes a share's data in the specified format
 func encodeShare(data []byte, format string) string {
 	if format == "hex" {
-		return hex.EncodeToString(data)
+		return Hex.Encode(data)
 	}
-	return base64.StdEncoding.EncodeToString(data)
+	return Base64.Encode(data)
 }
 
 // Split implements Shamir's Secret Sharing algorithm to split a secret into n shards,
@@ -86,19 +86,26 @@ func encodeShare(data []byte, format string) string {
 //   - secret: The secret data to be split (cannot be empty)
 //   - n: Total number of shards to generate (must be between 2 and 255)
 //   - t: Minimum number of shards required for reconstruction (must be between 2 and n)
-//   - output: Output encoding format ("base64" or "hex")
+//   - output: Name of a registered encoding, such as "base64", "hex" or "bech32",
+//     optionally followed by "+deflate" to compress the secret, and "+pad" to
+//     pad it to the next power of two or "+padN" to pad it to a multiple of N
+//     bytes, hiding its length
 //
 // Returns:
-//   - A string containing n lines, each formatted as "xx:encoded_data" where:
+//   - A string containing n lines, each formatted as "xx:encoding:encoded_data" where:
 //   - xx is the hexadecimal x-coordinate (2 hex digits)
+//   - encoding is the output format and any transforms, such as "hex+pad",
+//     so shares decode without guessing
 //   - encoded_data is the y-coordinates encoded in the specified format
-//   - An error if validation fails or polynomial evaluation encounters issues
+//   - An error if validation fails or polynomial evaluation encounters issues.
+//     Invalid counts are reported as a *ParamError wrapping
+//     ErrInvalidShardCount or ErrInvalidThreshold.
 //
 // Example output format:
 //
-//	"01:base64_encoded_y_values"
-//	"02:base64_encoded_y_values"
-//	"03:base64_encoded_y_values"
+//	"01:base64:base64_encoded_y_values"
+//	"02:base64:base64_encoded_y_values"
+//	"03:base64:base64_encoded_y_values"
 //
 // Security: This implementation uses finite field arithmetic over GF(256) to ensure
 // that no information about the secret is leaked from individual shares.
@@ -107,25 +114,24 @@ func Split(secret []byte, n, t int, output string) (string, error) {
 		return "", err
 	}
 
-	xs := generateXCoordinates(n)
-	enc := strings.ToLower(strings.TrimSpace(output))
-
-	var sb strings.Builder
-	for i := 0; i < n; i++ {
-		x := xs[i]
-		yBytes := make([]byte, len(secret))
+	enc, opts, _ := parseOutput(output)
+	opts.Shares, opts.Threshold = n, t
+	return SplitWithOptions(secret, opts, enc)
+}
 
-		// Evaluate polynomial for each byte of the secret
-		for b := 0; b < len(secret); b++ {
-			y, err := evaluatePolynomial(secret[b], x, t)
-			if err != nil {
-				return "", err
-			}
-			yBytes[b] = y
-		}
+// SplitWithOptions splits a secret like Split, with the options of
+// SplitShares, and returns the shares encoded with enc, one per line.
+func SplitWithOptions(secret []byte, opts SplitOptions, enc Encoder) (string, error) {
+	shares, err := SplitShares(secret, opts)
+	if err != nil {
+		return "", err
+	}
 
-		data := encodeShare(yBytes, enc)
-		fmt.Fprintf(&sb, "%02x:%s\n", x, data)
+	var sb strings.Builder
+	for _, share := range shares {
+		sb.WriteString(share.Format(enc))
+		sb.WriteString("\n")
+		securemem.Wipe(share.Data)
 	}
 
 	return sb.String(), nil
@@ -176,118 +182,68 @@ func gfMul(a, b byte) byte {
 // Lagrange interpolation. This is the inverse operation of shamirSplit.
 //
 // The algorithm works by:
-//  1. Parse each share to extract x-coordinates and y-values
-//  2. Detect the encoding format (hex or base64) from the first share
+//  1. Parse and decode each share independently, using its encoding tag
+//  2. Merge identical shares and reject different shares with the same x-coordinate
 //  3. For each byte position, use Lagrange interpolation to reconstruct the original value
 //  4. Combine all reconstructed bytes to form the original secret
+//  5. Undo the transforms named in the tags, such as padding
 //
 // Parameters:
-//   - shards: A slice of strings, each in format "xx:<encoded_data>"
-//     where xx is the hex representation of the x-coordinate
+//   - shards: A slice of strings, each in format "xx:<encoding>:<encoded_data>"
+//     where xx is the hex representation of the x-coordinate. Legacy shares
+//     without the encoding tag are read as hex, or as base64 when they are
+//     not valid hex.
 //
 // Returns:
 //   - The reconstructed secret as bytes
-//   - An error if reconstruction fails (invalid shares, insufficient shares, etc.)
+//   - An error if reconstruction fails (invalid shares, insufficient shares, etc.).
+//     Problems with a particular share are reported as a *ShareParseError
+//     holding its index, wrapping ErrDuplicateShard, ErrMixedEncodings,
+//     ErrInconsistentLength or ErrMixedTransforms where applicable. Padding that fails its length check is ErrPaddingMismatch,
+//     and a secret decompressing beyond MaxDecompressedSize is
+//     ErrDecompressedTooLarge.
 //
 // Security properties:
 //   - Requires at least t shares to reconstruct the secret
 //   - Any subset of shares less than t reveals no information about the secret
 //   - The reconstruction is deterministic given the same shares
 func Recompose(shards []string) ([]byte, error) {
-	if len(shards) == 0 {
-		return nil, errors.New("no shards provided")
+	shares, err := parseShares(shards)
+	if err != nil {
+		return nil, err
 	}
-
-	// Parse the first share to detect encoding format
-	firstShare := strings.TrimSpace(shards[0])
-	parts := strings.Split(firstShare, ":")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid share format: %s", firstShare)
-	}
-
-	// Detect encoding format by trying to decode the first share
-	var encoding string
-	var decodedData []byte
-	var err error
-
-	// Try hex first
-	if decodedData, err = hex.DecodeString(parts[1]); err == nil {
-		encoding = "hex"
-	} else if decodedData, err = base64.StdEncoding.DecodeString(parts[1]); err == nil {
-		encoding = "base64"
-	} else {
-		return nil, fmt.Errorf("unable to detect encoding format from share: %s", firstShare)
-	}
-
-	secretLength := len(decodedData)
-	if secretLength == 0 {
-		return nil, errors.New("share contains no data")
-	}
-
-	// Parse all shares and extract x-coordinates and y-values
-	type Share struct {
-		x byte
-		y []byte
-	}
-
-	parsedShares := make([]Share, 0, len(shards))
-	for i, shardStr := range shards {
-		shardStr = strings.TrimSpace(shardStr)
-		if shardStr == "" {
-			continue
-		}
-
-		parts := strings.Split(shardStr, ":")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid share format at index %d: %s", i, shardStr)
-		}
-
-		// Parse x-coordinate
-		xHex := parts[0]
-		if len(xHex) != 2 {
-			return nil, fmt.Errorf("invalid x-coordinate format at index %d: %s", i, xHex)
-		}
-		xBytes, err := hex.DecodeString(xHex)
-		if err != nil || len(xBytes) != 1 {
-			return nil, fmt.Errorf("invalid x-coordinate at index %d: %s", i, xHex)
-		}
-		x := xBytes[0]
-
-		// Parse y-values
-		var yData []byte
-		if encoding == "hex" {
-			yData, err = hex.DecodeString(parts[1])
-		} else {
-			yData, err = base64.StdEncoding.DecodeString(parts[1])
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode share data at index %d: %v", i, err)
-		}
-		if len(yData) != secretLength {
-			return nil, fmt.Errorf("share at index %d has inconsistent length: got %d, expected %d", i, len(yData), secretLength)
-		}
-
-		parsedShares = append(parsedShares, Share{x: x, y: yData})
+	defer wipeDecodedShares(shares)
+	transforms, err := commonTransforms(shares)
+	if err != nil {
+		return nil, err
 	}
+	return undo(interpolateShares(shares), transforms)
+}
 
-	if len(parsedShares) < 2 {
-		return nil, errors.New("at least 2 shares are required for reconstruction")
+// wipeDecodedShares zeroes the y-values decoded from shard text, since
+// enough of them reveal the secret.
+func wipeDecodedShares(shares []decodedShare) {
+	for _, s := range shares {
+		securemem.Wipe(s.y)
 	}
+}
 
-	// Reconstruct the secret byte by byte using Lagrange interpolation
+// interpolateShares reconstructs the secret from distinct shares of equal
+// length, byte by byte using Lagrange interpolation.
+func interpolateShares(shares []decodedShare) []byte {
+	secretLength := len(shares[0].y)
 	reconstructed := make([]byte, secretLength)
 	for bytePos := 0; bytePos < secretLength; bytePos++ {
 		// Collect y-values for this byte position
-		points := make([]struct{ x, y byte }, len(parsedShares))
-		for i, share := range parsedShares {
+		points := make([]struct{ x, y byte }, len(shares))
+		for i, share := range shares {
 			points[i] = struct{ x, y byte }{share.x, share.y[bytePos]}
 		}
 
 		// Use Lagrange interpolation to reconstruct this byte
 		reconstructed[bytePos] = lagrangeInterpolate(points)
 	}
-
-	return reconstructed, nil
+	return reconstructed
 }
 
 // lagrangeInterpolate performs Lagrange interpolation to find f(0) given a set of points.
@@ -1,6 +1,7 @@
 package shamir
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"strings"
@@ -153,84 +154,45 @@ func TestGenerateXCoordinates(t *testing.T) {
 
 // TestEvaluatePolynomial tests the polynomial evaluation function
 func TestEvaluatePolynomial(t *testing.T) {
-	// Test with different degrees and values
-	tests := []struct {
-		name       string
-		secretByte byte
-		x          byte
-		t          int
-		wantErr    bool
-	}{
-		{
-			name:       "degree 2, x=1",
-			secretByte: 0x42,
-			x:          1,
-			t:          2,
-			wantErr:    false,
-		},
-		{
-			name:       "degree 3, x=2",
-			secretByte: 0x7F,
-			x:          2,
-			t:          3,
-			wantErr:    false,
-		},
-		{
-			name:       "degree 5, x=10",
-			secretByte: 0x00,
-			x:          10,
-			t:          5,
-			wantErr:    false,
-		},
+	// f(x) = 0x42 + 0x17*x + 0xa3*x^2, evaluated term by term
+	coeffs := []byte{0x17, 0xa3}
+	for _, x := range []byte{0, 1, 2, 10, 255} {
+		want := 0x42 ^ gfMul(0x17, x) ^ gfMul(0xa3, gfMul(x, x))
+		if got := evaluatePolynomial(0x42, coeffs, x); got != want {
+			t.Errorf("evaluatePolynomial(x=%d) = %#x, want %#x", x, got, want)
+		}
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := evaluatePolynomial(tt.secretByte, tt.x, tt.t)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("evaluatePolynomial() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !tt.wantErr {
-				// Verify the result is a valid byte
-				if result < 0 || result > 255 {
-					t.Errorf("evaluatePolynomial() result = %v, should be in [0, 255]", result)
-				}
-			}
-		})
+	if got := evaluatePolynomial(0x42, nil, 7); got != 0x42 {
+		t.Errorf("evaluatePolynomial() of a constant = %#x, want 0x42", got)
 	}
+}
 
-	// Test that different inputs produce different outputs (deterministic)
-	t.Run("deterministic check", func(t *testing.T) {
-		// Test that the same inputs always produce the same output
-		result1, err := evaluatePolynomial(0x42, 1, 3)
-		if err != nil {
-			t.Errorf("evaluatePolynomial() failed: %v", err)
-			return
-		}
-
-		result2, err := evaluatePolynomial(0x42, 1, 3)
-		if err != nil {
-			t.Errorf("evaluatePolynomial() failed: %v", err)
-			return
-		}
-
-		// Results should be identical since coefficients are now deterministic
-		if result1 != result2 {
-			t.Errorf("evaluatePolynomial() produced different results: %v vs %v", result1, result2)
-		}
-
-		// Test that different inputs produce different outputs
-		result3, err := evaluatePolynomial(0x43, 1, 3)
-		if err != nil {
-			t.Errorf("evaluatePolynomial() failed: %v", err)
-			return
+// TestSplitIsRandomized checks that the polynomial coefficients are random:
+// splitting the same secret twice gives different shares that both recompose.
+func TestSplitIsRandomized(t *testing.T) {
+	secret := []byte("4821")
+	first, err := Split(secret, 3, 2, "hex")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	second, err := Split(secret, 3, 2, "hex")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if first == second {
+		t.Fatal("Split() gave the same shares twice for the same secret")
+	}
+	for _, out := range []string{first, second} {
+		got, err := Recompose(strings.Split(strings.TrimSpace(out), "\n")[:2])
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Errorf("Recompose() = %q, %v, want %q", got, err, secret)
 		}
+	}
 
-		if result1 == result3 {
-			t.Errorf("evaluatePolynomial() produced identical results for different inputs: %v", result1)
-		}
-	})
+	coeffs, err := randomCoefficients(4, 3)
+	if err != nil || len(coeffs) != 8 {
+		t.Errorf("randomCoefficients(4, 3) = %d bytes, %v, want 8", len(coeffs), err)
+	}
 }
 
 // TestEncodeShare tests the share encoding function
@@ -513,10 +475,13 @@ func TestSplit(t *testing.T) {
 			// Validate each shard format
 			for i, shard := range shards {
 				parts := strings.Split(shard, ":")
-				if len(parts) != 2 {
+				if len(parts) != 3 {
 					t.Errorf("shard %d has invalid format: %s", i, shard)
 					continue
 				}
+				if parts[1] != strings.ToLower(strings.TrimSpace(tt.output)) {
+					t.Errorf("shard %d has encoding tag %q, want %q", i, parts[1], tt.output)
+				}
 
 				// Check x-coordinate
 				xHex := parts[0]
@@ -525,7 +490,7 @@ func TestSplit(t *testing.T) {
 				}
 
 				// Check encoded data
-				encodedData := parts[1]
+				encodedData := parts[2]
 				if len(encodedData) == 0 {
 					t.Errorf("shard %d has empty encoded data", i)
 				}
@@ -577,7 +542,7 @@ func TestSplitDeterministic(t *testing.T) {
 			parts1 := strings.Split(shard1, ":")
 			parts2 := strings.Split(shards2[j], ":")
 
-			if len(parts1) != 2 || len(parts2) != 2 {
+			if len(parts1) != 3 || len(parts2) != 3 {
 				t.Errorf("shard %d has invalid format", j)
 				continue
 			}
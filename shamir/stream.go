@@ -0,0 +1,172 @@
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"orcrux/securemem"
+)
+
+// streamChunkSize is the number of secret bytes processed per read in the
+// streaming functions.
+const streamChunkSize = 32 * 1024
+
+// SplitStream splits a secret read from r into len(ws) raw shares, where t
+// shares are required to reconstruct it.
+//
+// The secret is processed in fixed-size chunks, so arbitrarily large inputs
+// can be split without holding them in memory. Share i receives the raw
+// y-values for x-coordinate i+1; no encoding or framing is added, which makes
+// the output of SplitStream the binary equivalent of the data part of Split.
+//
+// Parameters:
+//   - r: The source of the secret (must yield at least one byte)
+//   - ws: One writer per share (between 2 and 255 writers)
+//   - t: Minimum number of shares required for reconstruction (between 2 and len(ws))
+//
+// Returns:
+//   - The number of secret bytes processed
+//   - An error if validation fails or reading or writing fails
+func SplitStream(r io.Reader, ws []io.Writer, t int) (int64, error) {
+	if err := validateShardCounts(len(ws), t); err != nil {
+		return 0, err
+	}
+
+	xs := generateXCoordinates(len(ws))
+	in := make([]byte, streamChunkSize)
+	out := make([]byte, streamChunkSize)
+	var total int64
+
+	for {
+		nr, readErr := io.ReadFull(r, in)
+		if nr > 0 {
+			// Fresh coefficients for every chunk, shared by all shares
+			coeffs, err := randomCoefficients(nr, t)
+			if err != nil {
+				return total, err
+			}
+			k := t - 1
+			for i, w := range ws {
+				for b := 0; b < nr; b++ {
+					out[b] = evaluatePolynomial(in[b], coeffs[b*k:(b+1)*k], xs[i])
+				}
+				if _, err := w.Write(out[:nr]); err != nil {
+					securemem.Wipe(coeffs)
+					return total, err
+				}
+			}
+			securemem.Wipe(coeffs)
+			total += int64(nr)
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return total, readErr
+		}
+	}
+
+	if total == 0 {
+		return 0, ErrEmptySecret
+	}
+	return total, nil
+}
+
+// CombineStream reconstructs a secret from raw shares produced by SplitStream
+// and writes it to w.
+//
+// All readers are consumed in lockstep, so they must have exactly the same
+// length. xs holds the x-coordinate of each reader, in the same order.
+//
+// Parameters:
+//   - w: The destination of the reconstructed secret
+//   - xs: The x-coordinate of each share (distinct and non-zero)
+//   - rs: One reader per share, at least 2
+//
+// Returns:
+//   - The number of secret bytes written
+//   - An error if the shares are inconsistent or reading or writing fails
+func CombineStream(w io.Writer, xs []byte, rs []io.Reader) (int64, error) {
+	if len(rs) < 2 {
+		return 0, ErrTooFewShards
+	}
+	if len(xs) != len(rs) {
+		return 0, fmt.Errorf("got %d x-coordinates for %d shares", len(xs), len(rs))
+	}
+	seen := make(map[byte]bool, len(xs))
+	for i, x := range xs {
+		if x == 0 {
+			return 0, &ShareParseError{Index: i, Reason: "x-coordinate must not be 00"}
+		}
+		if seen[x] {
+			return 0, &ShareParseError{Index: i, Reason: fmt.Sprintf("duplicate x-coordinate %02x", x), Err: ErrDuplicateShard}
+		}
+		seen[x] = true
+	}
+
+	bufs := make([][]byte, len(rs))
+	for i := range bufs {
+		bufs[i] = make([]byte, streamChunkSize)
+	}
+	out := make([]byte, streamChunkSize)
+	coeffs := lagrangeCoefficients(xs)
+	var total int64
+
+	for {
+		n := -1
+		done := false
+		for i, r := range rs {
+			nr, err := io.ReadFull(r, bufs[i])
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return total, err
+			}
+			if n == -1 {
+				n = nr
+				done = err != nil
+			} else if nr != n || (err != nil) != done {
+				return total, &ShareParseError{Index: i, Reason: "inconsistent length", Err: ErrInconsistentLength}
+			}
+		}
+
+		for b := 0; b < n; b++ {
+			var y byte
+			for i, c := range coeffs {
+				y ^= gfMul(bufs[i][b], c)
+			}
+			out[b] = y
+		}
+		if n > 0 {
+			if _, err := w.Write(out[:n]); err != nil {
+				return total, err
+			}
+			total += int64(n)
+		}
+
+		if done {
+			break
+		}
+	}
+
+	if total == 0 {
+		return 0, errors.New("share contains no data")
+	}
+	return total, nil
+}
+
+// lagrangeCoefficients returns the Lagrange basis polynomials evaluated at 0
+// for the given distinct x-coordinates. They only depend on the
+// x-coordinates, so they are computed once per stream rather than per byte.
+func lagrangeCoefficients(xs []byte) []byte {
+	coeffs := make([]byte, len(xs))
+	for i, xi := range xs {
+		c := byte(1)
+		for j, xj := range xs {
+			if i != j {
+				c = gfMul(c, gfDiv(xj, xi^xj))
+			}
+		}
+		coeffs[i] = c
+	}
+	return coeffs
+}
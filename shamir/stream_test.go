@@ -0,0 +1,140 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestSplitStreamRoundTrip tests that streamed shares reconstruct the original secret
+func TestSplitStreamRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		n      int
+		t      int
+		subset []int
+	}{
+		{name: "single byte", size: 1, n: 2, t: 2, subset: []int{0, 1}},
+		{name: "smaller than a chunk", size: 1000, n: 5, t: 3, subset: []int{0, 2, 4}},
+		{name: "exactly one chunk", size: streamChunkSize, n: 3, t: 2, subset: []int{1, 2}},
+		{name: "several chunks", size: 3*streamChunkSize + 17, n: 4, t: 3, subset: []int{3, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := make([]byte, tt.size)
+			for i := range secret {
+				secret[i] = byte(i * 7)
+			}
+
+			bufs := make([]*bytes.Buffer, tt.n)
+			ws := make([]io.Writer, tt.n)
+			for i := range bufs {
+				bufs[i] = &bytes.Buffer{}
+				ws[i] = bufs[i]
+			}
+
+			n, err := SplitStream(bytes.NewReader(secret), ws, tt.t)
+			if err != nil {
+				t.Fatalf("SplitStream() error = %v", err)
+			}
+			if n != int64(tt.size) {
+				t.Errorf("SplitStream() processed %d bytes, want %d", n, tt.size)
+			}
+
+			xs := make([]byte, 0, len(tt.subset))
+			rs := make([]io.Reader, 0, len(tt.subset))
+			for _, i := range tt.subset {
+				xs = append(xs, byte(i+1))
+				rs = append(rs, bytes.NewReader(bufs[i].Bytes()))
+			}
+
+			var out bytes.Buffer
+			if _, err := CombineStream(&out, xs, rs); err != nil {
+				t.Fatalf("CombineStream() error = %v", err)
+			}
+			if !bytes.Equal(out.Bytes(), secret) {
+				t.Error("CombineStream() did not reconstruct the original secret")
+			}
+		})
+	}
+}
+
+// TestSplitStreamMatchesSplit tests that streamed shares carry the same data
+// as the data part of Split, so they recompose once given their x-coordinate
+func TestSplitStreamMatchesSplit(t *testing.T) {
+	secret := []byte("stream and split agree")
+
+	bufs := []*bytes.Buffer{{}, {}, {}}
+	if _, err := SplitStream(bytes.NewReader(secret), []io.Writer{bufs[0], bufs[1], bufs[2]}, 2); err != nil {
+		t.Fatalf("SplitStream() error = %v", err)
+	}
+
+	shards := make([]string, len(bufs))
+	for i, buf := range bufs {
+		shards[i] = fmt.Sprintf("%02x:hex:%s", i+1, hex.EncodeToString(buf.Bytes()))
+	}
+	got, err := Recompose(shards[1:])
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Recompose() = %q, want %q", got, secret)
+	}
+}
+
+// TestSplitStreamErrors tests validation in SplitStream
+func TestSplitStreamErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret []byte
+		n      int
+		t      int
+	}{
+		{name: "empty secret", secret: nil, n: 3, t: 2},
+		{name: "too few shares", secret: []byte("x"), n: 1, t: 2},
+		{name: "threshold larger than shares", secret: []byte("x"), n: 3, t: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := make([]io.Writer, tt.n)
+			for i := range ws {
+				ws[i] = io.Discard
+			}
+			if _, err := SplitStream(bytes.NewReader(tt.secret), ws, tt.t); err == nil {
+				t.Error("SplitStream() should have returned an error")
+			}
+		})
+	}
+}
+
+// TestCombineStreamErrors tests validation in CombineStream
+func TestCombineStreamErrors(t *testing.T) {
+	reader := func(s string) io.Reader { return strings.NewReader(s) }
+
+	tests := []struct {
+		name string
+		xs   []byte
+		rs   []io.Reader
+	}{
+		{name: "single share", xs: []byte{1}, rs: []io.Reader{reader("ab")}},
+		{name: "coordinate count mismatch", xs: []byte{1}, rs: []io.Reader{reader("ab"), reader("cd")}},
+		{name: "zero x-coordinate", xs: []byte{0, 1}, rs: []io.Reader{reader("ab"), reader("cd")}},
+		{name: "duplicate x-coordinate", xs: []byte{1, 1}, rs: []io.Reader{reader("ab"), reader("cd")}},
+		{name: "inconsistent lengths", xs: []byte{1, 2}, rs: []io.Reader{reader("ab"), reader("c")}},
+		{name: "empty shares", xs: []byte{1, 2}, rs: []io.Reader{reader(""), reader("")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CombineStream(io.Discard, tt.xs, tt.rs); err == nil {
+				t.Error("CombineStream() should have returned an error")
+			}
+		})
+	}
+}
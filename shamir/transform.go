@@ -0,0 +1,231 @@
+package shamir
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"orcrux/securemem"
+	"strconv"
+	"strings"
+)
+
+// Transforms are reversible stages applied to the secret before it is split.
+// Their names follow the encoding in the tag of every share, in the order
+// they were applied, so that Recompose can undo them without being told:
+//
+//	01:hex+deflate+pad:...
+
+// undoTransforms maps each transform name to the function reversing it.
+var undoTransforms = map[string]func([]byte) ([]byte, error){
+	"deflate": inflate,
+	"pad":     unpad,
+}
+
+// MaxDecompressedSize is the largest secret Recompose and Combine will
+// decompress, guarding against shares crafted to expand without bound.
+var MaxDecompressedSize int64 = 64 << 20
+
+// deflate compresses secret with DEFLATE at the best compression level.
+func deflate(secret []byte) ([]byte, error) {
+	// Size the buffer for incompressible input up front, so it does not
+	// grow and leave stale copies of the compressed secret behind
+	var buf bytes.Buffer
+	buf.Grow(len(secret) + len(secret)/64 + 64)
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(secret); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// inflate decompresses data written by deflate, failing once the output
+// exceeds MaxDecompressedSize.
+func inflate(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid deflate data: %w", err)
+	}
+	if int64(len(out)) > MaxDecompressedSize {
+		return nil, fmt.Errorf("%w (%d bytes)", ErrDecompressedTooLarge, MaxDecompressedSize)
+	}
+	return out, nil
+}
+
+// PadPowerOfTwo pads the secret to the next power of two, and at least
+// MinPadding bytes.
+const PadPowerOfTwo = -1
+
+// Padding limits, in bytes.
+const (
+	MinPadding = 32
+	MaxPadding = 1 << 16
+)
+
+// padHeaderSize is the size of the length prefix and its tag.
+const padHeaderSize = 4 + 8
+
+// pad prefixes secret with its length and a tag authenticating both, then
+// appends random bytes up to a multiple of bucket bytes, or to the next power
+// of two for PadPowerOfTwo:
+//
+//	length (4 bytes, big endian) | SHA-256(length | secret)[:8] | secret | random
+//
+// The filler is random rather than zeros so that it does not show up in the
+// shares as a recognisable tail revealing where the secret ends.
+func pad(secret []byte, bucket int) ([]byte, error) {
+	size := padHeaderSize + len(secret)
+	if bucket == PadPowerOfTwo {
+		size = max(MinPadding, 1<<bits.Len(uint(size-1)))
+	} else {
+		size = (size + bucket - 1) / bucket * bucket
+	}
+
+	out := make([]byte, size)
+	binary.BigEndian.PutUint32(out, uint32(len(secret)))
+	copy(out[4:], padTag(out[:4], secret))
+	copy(out[padHeaderSize:], secret)
+	if _, err := rand.Read(out[padHeaderSize+len(secret):]); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// unpad checks the length prefix and tag written by pad and returns the
+// original secret. The filler is not checked: it is random, or zeros in
+// shares written before it was.
+func unpad(data []byte) ([]byte, error) {
+	if len(data) < padHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the header", ErrPaddingMismatch, len(data))
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(n) > uint64(len(data)-padHeaderSize) {
+		return nil, fmt.Errorf("%w: length %d exceeds the padded size", ErrPaddingMismatch, n)
+	}
+	secret := data[padHeaderSize : padHeaderSize+int(n)]
+	if string(padTag(data[:4], secret)) != string(data[4:padHeaderSize]) {
+		return nil, fmt.Errorf("%w: tag does not match", ErrPaddingMismatch)
+	}
+	// Copy the secret out, so the padded buffer can be wiped
+	return bytes.Clone(secret), nil
+}
+
+// padTag returns the truncated SHA-256 of the length prefix and secret.
+func padTag(length, secret []byte) []byte {
+	h := sha256.New()
+	h.Write(length)
+	h.Write(secret)
+	return h.Sum(nil)[:8]
+}
+
+// validatePadding checks the Padding field of SplitOptions.
+func validatePadding(padding int) error {
+	if padding == 0 || padding == PadPowerOfTwo {
+		return nil
+	}
+	if padding < MinPadding || padding > MaxPadding {
+		return &ParamError{Name: "padding", Value: padding, Min: MinPadding, Max: MaxPadding, Err: ErrInvalidPadding}
+	}
+	return nil
+}
+
+// parseOutput parses an output of the form "encoding[+option...]" into the
+// encoding and the options it selects. "deflate" compresses the secret, "pad"
+// pads it to the next power of two and "padN" to a multiple of N bytes.
+func parseOutput(output string) (Encoding, SplitOptions, error) {
+	tokens := strings.Split(output, "+")
+	enc, ok := Lookup(tokens[0])
+	if !ok {
+		return nil, SplitOptions{}, fmt.Errorf("%w (%s), got: %q", ErrInvalidOutput, strings.Join(Encodings(), ", "), output)
+	}
+
+	var opts SplitOptions
+	for _, token := range tokens[1:] {
+		token = strings.ToLower(strings.TrimSpace(token))
+		switch {
+		case token == "deflate":
+			opts.Compress = true
+		case token == "pad":
+			opts.Padding = PadPowerOfTwo
+		case strings.HasPrefix(token, "pad"):
+			n, err := strconv.Atoi(token[len("pad"):])
+			if err != nil {
+				return nil, SplitOptions{}, fmt.Errorf("%w: invalid padding %q", ErrInvalidOutput, token)
+			}
+			if err := validatePadding(n); err != nil {
+				return nil, SplitOptions{}, err
+			}
+			opts.Padding = n
+		default:
+			return nil, SplitOptions{}, fmt.Errorf("%w: unknown option %q in %q", ErrInvalidOutput, token, output)
+		}
+	}
+	return enc, opts, nil
+}
+
+// CheckOutput reports whether output is accepted by Split: a registered
+// encoding, optionally followed by "+deflate", "+pad" or "+padN".
+func CheckOutput(output string) error {
+	_, _, err := parseOutput(output)
+	return err
+}
+
+// splitTag separates the encoding of a share tag from its transforms.
+func splitTag(tag string) (encoding string, transforms []string) {
+	parts := strings.Split(tag, "+")
+	return parts[0], parts[1:]
+}
+
+// checkTransforms reports the first transform that cannot be undone.
+func checkTransforms(transforms []string) error {
+	for _, t := range transforms {
+		if _, ok := undoTransforms[t]; !ok {
+			return fmt.Errorf("unknown transform %q", t)
+		}
+	}
+	return nil
+}
+
+// undo reverses transforms on a recovered secret, last applied first. It
+// takes ownership of secret: every intermediate buffer, including secret
+// itself, is wiped once the next stage has been computed from it.
+func undo(secret []byte, transforms []string) ([]byte, error) {
+	for i := len(transforms) - 1; i >= 0; i-- {
+		out, err := undoTransforms[transforms[i]](secret)
+		securemem.Wipe(secret)
+		if err != nil {
+			return nil, err
+		}
+		secret = out
+	}
+	return secret, nil
+}
+
+// commonTransforms returns the transforms shared by all shares, reporting
+// the first share that disagrees with the others.
+func commonTransforms(shares []decodedShare) ([]string, error) {
+	want := strings.Join(shares[0].transforms, "+")
+	for _, s := range shares[1:] {
+		if got := strings.Join(s.transforms, "+"); got != want {
+			return nil, &ShareParseError{
+				Index:  s.index,
+				Reason: fmt.Sprintf("share was split with %q, expected %q", got, want),
+				Err:    ErrMixedTransforms,
+			}
+		}
+	}
+	return shares[0].transforms, nil
+}
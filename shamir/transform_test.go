@@ -0,0 +1,361 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"orcrux/securemem"
+	"strings"
+	"testing"
+)
+
+func TestPadSizes(t *testing.T) {
+	tests := []struct {
+		secretLen int
+		bucket    int
+		want      int
+	}{
+		{secretLen: 1, bucket: PadPowerOfTwo, want: 32},
+		{secretLen: 20, bucket: PadPowerOfTwo, want: 32},
+		{secretLen: 21, bucket: PadPowerOfTwo, want: 64},
+		{secretLen: 100, bucket: PadPowerOfTwo, want: 128},
+		{secretLen: 4, bucket: 64, want: 64},
+		{secretLen: 52, bucket: 64, want: 64},
+		{secretLen: 53, bucket: 64, want: 128},
+		{secretLen: 300, bucket: 256, want: 512},
+	}
+	for _, tt := range tests {
+		got, err := pad(bytes.Repeat([]byte{'s'}, tt.secretLen), tt.bucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("pad(%d bytes, %d) has %d bytes, want %d", tt.secretLen, tt.bucket, len(got), tt.want)
+		}
+	}
+}
+
+func TestUnpad(t *testing.T) {
+	secret := []byte("1234")
+	padded, err := pad(secret, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := unpad(padded)
+	if err != nil {
+		t.Fatalf("unpad() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("unpad() = %q, want %q", got, secret)
+	}
+
+	corrupt := map[string]func([]byte){
+		"length": func(b []byte) { b[3]++ },
+		"huge":   func(b []byte) { b[0] = 0xff },
+		"tag":    func(b []byte) { b[5] ^= 1 },
+		"secret": func(b []byte) { b[padHeaderSize] ^= 1 },
+	}
+	for name, change := range corrupt {
+		t.Run(name, func(t *testing.T) {
+			b := append([]byte(nil), padded...)
+			change(b)
+			if _, err := unpad(b); !errors.Is(err, ErrPaddingMismatch) {
+				t.Errorf("unpad() error = %v, want %v", err, ErrPaddingMismatch)
+			}
+		})
+	}
+	if _, err := unpad([]byte{0, 0}); !errors.Is(err, ErrPaddingMismatch) {
+		t.Errorf("unpad(short) error = %v, want %v", err, ErrPaddingMismatch)
+	}
+}
+
+func TestSplitPaddedHidesLength(t *testing.T) {
+	pin, err := Split([]byte("1234"), 3, 2, "hex+pad64")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	seed, err := Split([]byte("legal winner thank year wave sausage worth"), 3, 2, "hex+pad64")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	pinShards := strings.Split(strings.TrimSpace(pin), "\n")
+	seedShards := strings.Split(strings.TrimSpace(seed), "\n")
+	if len(pinShards[0]) != len(seedShards[0]) {
+		t.Errorf("padded shards differ in length: %q and %q", pinShards[0], seedShards[0])
+	}
+	if !strings.HasPrefix(pinShards[0], "01:hex+pad:") {
+		t.Errorf("shard = %q, want the pad transform in its tag", pinShards[0])
+	}
+
+	got, err := Recompose(pinShards[1:])
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if string(got) != "1234" {
+		t.Errorf("Recompose() = %q, want %q", got, "1234")
+	}
+}
+
+func TestSplitPowerOfTwoPadding(t *testing.T) {
+	out, err := Split([]byte("a short secret"), 3, 2, "base64+pad")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	share, err := ParseShare(strings.Split(out, "\n")[0])
+	if err != nil {
+		t.Fatalf("ParseShare() error = %v", err)
+	}
+	if len(share.Data) != 32 || len(share.Transforms) != 1 || share.Transforms[0] != "pad" {
+		t.Errorf("share has %d bytes and transforms %v, want 32 bytes padded", len(share.Data), share.Transforms)
+	}
+}
+
+func TestSplitOutputOptionErrors(t *testing.T) {
+	tests := map[string]error{
+		"hex+zip":      ErrInvalidOutput,
+		"hex+padding":  ErrInvalidOutput,
+		"hex+pad16":    ErrInvalidPadding,
+		"hex+pad70000": ErrInvalidPadding,
+		"rot13+pad":    ErrInvalidOutput,
+	}
+	for output, want := range tests {
+		if _, err := Split([]byte("s"), 3, 2, output); !errors.Is(err, want) {
+			t.Errorf("Split(%q) error = %v, want %v", output, err, want)
+		}
+		if err := CheckOutput(output); !errors.Is(err, want) {
+			t.Errorf("CheckOutput(%q) error = %v, want %v", output, err, want)
+		}
+	}
+	if err := CheckOutput("grouped+deflate+pad64"); err != nil {
+		t.Errorf("CheckOutput() error = %v", err)
+	}
+}
+
+func TestSplitSharesPadding(t *testing.T) {
+	secret := []byte("typed API secret")
+	shares, err := SplitShares(secret, SplitOptions{Shares: 3, Threshold: 2, Padding: 256})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	if len(shares[0].Data) != 256 {
+		t.Errorf("share has %d bytes, want 256", len(shares[0].Data))
+	}
+	if !strings.HasPrefix(shares[0].Format(Hex), "01:hex+pad:") {
+		t.Errorf("Format() = %q, want the pad transform", shares[0].Format(Hex)[:16])
+	}
+
+	got, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Combine() = %q, want %q", got, secret)
+	}
+
+	var pe *ParamError
+	if _, err := SplitShares(secret, SplitOptions{Shares: 3, Threshold: 2, Padding: 8}); !errors.As(err, &pe) || pe.Name != "padding" {
+		t.Errorf("SplitShares() with 8 byte padding error = %v, want a padding ParamError", err)
+	}
+}
+
+func TestRecomposeMixedTransforms(t *testing.T) {
+	padded, _ := SplitShares([]byte("secret"), SplitOptions{Shares: 3, Threshold: 2, Padding: 32})
+	plain, _ := SplitShares(make([]byte, 32), SplitOptions{Shares: 3, Threshold: 2})
+
+	_, err := Recompose([]string{padded[0].Format(Hex), plain[1].Format(Hex)})
+	var spe *ShareParseError
+	if !errors.As(err, &spe) || spe.Index != 1 || !errors.Is(err, ErrMixedTransforms) {
+		t.Errorf("Recompose() error = %v, want ErrMixedTransforms at index 1", err)
+	}
+
+	_, err = Recompose([]string{"01:hex+rot13:616263", "02:hex+rot13:646566"})
+	if !errors.As(err, &spe) || spe.Index != 0 || !strings.Contains(err.Error(), "unknown transform") {
+		t.Errorf("Recompose() error = %v, want an unknown transform at index 0", err)
+	}
+}
+
+func TestSplitDeflate(t *testing.T) {
+	secret := []byte(strings.Repeat(`{"user":"deploy","password":"hunter2"},`, 50))
+	out, err := Split(secret, 3, 2, "hex+deflate")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	shards := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.HasPrefix(shards[0], "01:hex+deflate:") {
+		t.Errorf("shard = %q, want the deflate transform in its tag", shards[0][:20])
+	}
+	if len(shards[0]) >= len(secret) {
+		t.Errorf("compressed shard has %d characters for a %d byte secret", len(shards[0]), len(secret))
+	}
+
+	got, err := Recompose(shards[1:])
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Error("Recompose() did not restore the compressed secret")
+	}
+}
+
+func TestSplitDeflateThenPad(t *testing.T) {
+	secret := []byte(strings.Repeat("compressible ", 100))
+	shares, err := SplitShares(secret, SplitOptions{Shares: 2, Threshold: 2, Compress: true, Padding: 64})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	if got := strings.Join(shares[0].Transforms, "+"); got != "deflate+pad" {
+		t.Errorf("Transforms = %q, want deflate before pad", got)
+	}
+	if len(shares[0].Data) != 64 {
+		t.Errorf("share has %d bytes, want the compressed secret padded to 64", len(shares[0].Data))
+	}
+
+	// The option order in the output does not change the order applied
+	out, err := Split(secret, 2, 2, "base64+pad64+deflate")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if !strings.HasPrefix(out, "01:base64+deflate+pad:") {
+		t.Errorf("Split() = %q, want deflate before pad", out[:24])
+	}
+
+	got, err := Recompose(strings.Split(strings.TrimSpace(out), "\n"))
+	if err != nil {
+		t.Fatalf("Recompose() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Error("Recompose() did not restore the secret")
+	}
+}
+
+func TestInflateLimit(t *testing.T) {
+	limit := MaxDecompressedSize
+	t.Cleanup(func() { MaxDecompressedSize = limit })
+	MaxDecompressedSize = 1024
+
+	// A kilobyte of zeros compresses to a handful of bytes
+	bomb, err := deflate(make([]byte, 1025))
+	if err != nil {
+		t.Fatalf("deflate() error = %v", err)
+	}
+	shares, err := SplitShares(bomb, SplitOptions{Shares: 2, Threshold: 2})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	for i := range shares {
+		shares[i].Transforms = []string{"deflate"}
+	}
+	if _, err := Combine(shares); !errors.Is(err, ErrDecompressedTooLarge) {
+		t.Errorf("Combine() error = %v, want %v", err, ErrDecompressedTooLarge)
+	}
+
+	exact, _ := deflate(make([]byte, 1024))
+	if got, err := inflate(exact); err != nil || len(got) != 1024 {
+		t.Errorf("inflate() at the limit = %d bytes, %v", len(got), err)
+	}
+	if _, err := inflate([]byte("not deflate")); err == nil {
+		t.Error("inflate() should reject corrupt data")
+	}
+}
+
+func TestUndoWipesIntermediateBuffers(t *testing.T) {
+	secret := []byte(strings.Repeat("wipe me ", 20))
+	compressed, err := deflate(secret)
+	if err != nil {
+		t.Fatalf("deflate() error = %v", err)
+	}
+	padded, err := pad(compressed, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed = bytes.Clone(compressed)
+
+	got, err := undo(padded, []string{"deflate", "pad"})
+	if err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatal("undo() did not restore the secret")
+	}
+	if !securemem.IsZero(padded) {
+		t.Error("undo() left the padded secret in memory")
+	}
+
+	// The buffer returned by unpad is a copy, not a view of the padded one
+	repadded, err := pad(compressed, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := unpad(repadded)
+	if err != nil {
+		t.Fatalf("unpad() error = %v", err)
+	}
+	got, err = undo(inner, []string{"deflate"})
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("undo() = %v, want the secret", err)
+	}
+	if !securemem.IsZero(inner) {
+		t.Error("undo() left the compressed secret in memory")
+	}
+}
+
+func TestUndoWipesOnError(t *testing.T) {
+	padded, err := pad([]byte("secret"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded[padHeaderSize] ^= 1
+	if _, err := undo(padded, []string{"pad"}); !errors.Is(err, ErrPaddingMismatch) {
+		t.Fatalf("undo() error = %v, want %v", err, ErrPaddingMismatch)
+	}
+	if !securemem.IsZero(padded) {
+		t.Error("undo() did not wipe its input after failing")
+	}
+}
+
+func TestSplitSharesKeepsCallerBuffers(t *testing.T) {
+	secret := []byte(strings.Repeat("caller owned ", 10))
+	want := bytes.Clone(secret)
+	shares, err := SplitShares(secret, SplitOptions{Shares: 3, Threshold: 2, Compress: true, Padding: PadPowerOfTwo})
+	if err != nil {
+		t.Fatalf("SplitShares() error = %v", err)
+	}
+	if !bytes.Equal(secret, want) {
+		t.Error("SplitShares() modified the caller's secret")
+	}
+
+	data := bytes.Clone(shares[0].Data)
+	if _, err := Combine(shares[:2]); err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if !bytes.Equal(shares[0].Data, data) {
+		t.Error("Combine() modified the caller's shares")
+	}
+}
+
+// TestPaddedSharesHideLength checks that the filler does not show up in the
+// shares: with zeros, every padding byte became the same value in a shard,
+// which revealed where the secret ends.
+func TestPaddedSharesHideLength(t *testing.T) {
+	for _, secret := range []string{"1234", "a much longer secret"} {
+		out, err := Split([]byte(secret), 3, 2, "hex+pad64")
+		if err != nil {
+			t.Fatalf("Split() error = %v", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			share, err := ParseShare(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(share.Data) != 64 {
+				t.Fatalf("padded share has %d bytes, want 64", len(share.Data))
+			}
+			// The last 32 bytes are filler for both secrets
+			tail := share.Data[32:]
+			if bytes.Count(tail, tail[:1]) == len(tail) {
+				t.Errorf("share %02x of %q ends with a constant run: %x", share.X, secret, tail)
+			}
+		}
+	}
+}
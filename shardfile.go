@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"orcrux/shamir"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// shardFileMagic is the first line of every shard file.
+	shardFileMagic = "ORCRUX SHARD FILE v1"
+
+	// shardFileExt is the extension given to shard files.
+	shardFileExt = ".orcrux"
+
+	// formatRaw marks shard files holding the bytes of a single file.
+	formatRaw = "raw"
+)
+
+// errUnsignedShardFiles is returned by CombineToFile in strict signature
+// mode: shard files are never signed, so they cannot be trusted there.
+var errUnsignedShardFiles = fmt.Errorf("shard files are %w, turn off Reject unsigned shards to restore them", ErrUnsigned)
+
+// shardFileHeader is the plain-text header written at the start of a shard
+// file. It records everything needed to restore the original file exactly;
+// the raw share data follows the blank line that ends the header.
+type shardFileHeader struct {
+	X         byte
+	Threshold int
+	Shards    int
+	Filename  string
+	Size      int64
+	SHA256    string
+	Format    string // "raw", or an archive format for split directories
+}
+
+// write serialises the header, including the terminating blank line.
+func (h shardFileHeader) write(w io.Writer) error {
+	format := h.Format
+	if format == "" {
+		format = formatRaw
+	}
+	_, err := fmt.Fprintf(w, "%s\nx: %02x\nthreshold: %d\nshards: %d\nfilename: %s\nsize: %d\nsha256: %s\nformat: %s\n\n",
+		shardFileMagic, h.X, h.Threshold, h.Shards, strconv.Quote(h.Filename), h.Size, h.SHA256, format)
+	return err
+}
+
+// isShardFile reports whether data starts with the magic line of a shard file.
+func isShardFile(data []byte) bool {
+	line, _, _ := strings.Cut(string(data[:min(len(data), len(shardFileMagic)+2)]), "\n")
+	return strings.TrimRight(line, "\r") == shardFileMagic
+}
+
+// readShardFileHeader parses a shard file header, leaving r positioned at the
+// start of the share data.
+func readShardFileHeader(r *bufio.Reader) (shardFileHeader, error) {
+	h := shardFileHeader{Format: formatRaw}
+
+	magic, err := r.ReadString('\n')
+	if err != nil || strings.TrimRight(magic, "\r\n") != shardFileMagic {
+		return h, errors.New("not an orcrux shard file")
+	}
+
+	seen := make(map[string]bool)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return h, errors.New("truncated shard file header")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return h, fmt.Errorf("invalid shard file header line: %q", line)
+		}
+		seen[key] = true
+
+		switch key {
+		case "x":
+			x, err := strconv.ParseUint(value, 16, 8)
+			if err != nil || x == 0 {
+				return h, fmt.Errorf("invalid x-coordinate: %q", value)
+			}
+			h.X = byte(x)
+		case "threshold":
+			h.Threshold, err = strconv.Atoi(value)
+		case "shards":
+			h.Shards, err = strconv.Atoi(value)
+		case "filename":
+			h.Filename, err = strconv.Unquote(value)
+		case "size":
+			h.Size, err = strconv.ParseInt(value, 10, 64)
+		case "sha256":
+			h.SHA256 = value
+		case "format":
+			h.Format = value
+		}
+		if err != nil {
+			return h, fmt.Errorf("invalid %s in shard file header: %q", key, value)
+		}
+	}
+
+	for _, key := range []string{"x", "threshold", "shards", "filename", "size", "sha256"} {
+		if !seen[key] {
+			return h, fmt.Errorf("shard file header is missing %q", key)
+		}
+	}
+	return h, nil
+}
+
+// safeFilename returns the base name of a file name taken from a shard
+// header, rejecting names that could escape the destination directory.
+func safeFilename(name string) (string, error) {
+	base := filepath.Base(filepath.Clean(name))
+	if base != name || base == "." || base == ".." || base == string(filepath.Separator) || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("unsafe file name in shard header: %q", name)
+	}
+	return base, nil
+}
+
+// hashFile returns the size and hex SHA-256 digest of the file at path.
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// splitFileToDir splits the file at src into n shard files written to dir,
+// any t of which restore it. It returns the paths of the shard files.
+//
+// The source is read twice: once to record its size and digest in the shard
+// headers, and once through shamir.SplitStream. If the file changes between
+// the two passes, the shard files are removed and an error is returned.
+func splitFileToDir(src, dir string, n, t int) ([]string, error) {
+	size, digest, err := hashFile(src)
+	if err != nil {
+		return nil, err
+	}
+	source := shardSource{Filename: filepath.Base(src), Size: size, SHA256: digest, Format: formatRaw}
+	return splitReaderToDir(func() (io.ReadCloser, error) { return os.Open(src) }, source, dir, n, t)
+}
+
+// shardSource describes the stream being split into shard files.
+type shardSource struct {
+	Filename string
+	Size     int64
+	SHA256   string
+	Format   string
+}
+
+// splitReaderToDir writes n shard files for the stream returned by open,
+// whose size and digest were computed beforehand.
+func splitReaderToDir(open func() (io.ReadCloser, error), source shardSource, dir string, n, t int) (paths []string, err error) {
+	if n < 2 || n > 255 {
+		return nil, errors.New("shards must be in [2, 255]")
+	}
+
+	files := make([]*os.File, 0, n)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+		if err != nil {
+			for _, p := range paths {
+				os.Remove(p)
+			}
+			paths = nil
+		}
+	}()
+
+	writers := make([]io.Writer, 0, n)
+	for i := 1; i <= n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s.%02x%s", source.Filename, i, shardFileExt))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return paths, err
+		}
+		files = append(files, f)
+		paths = append(paths, path)
+
+		header := shardFileHeader{X: byte(i), Threshold: t, Shards: n, Filename: source.Filename, Size: source.Size, SHA256: source.SHA256, Format: source.Format}
+		bw := bufio.NewWriter(f)
+		if err := header.write(bw); err != nil {
+			return paths, err
+		}
+		writers = append(writers, bw)
+	}
+
+	src, err := open()
+	if err != nil {
+		return paths, err
+	}
+	defer src.Close()
+
+	h := sha256.New()
+	written, err := shamir.SplitStream(io.TeeReader(src, h), writers, t)
+	if err != nil {
+		return paths, err
+	}
+	if written != source.Size || hex.EncodeToString(h.Sum(nil)) != source.SHA256 {
+		return paths, errors.New("source changed while it was being split")
+	}
+
+	for i, w := range writers {
+		if err := w.(*bufio.Writer).Flush(); err != nil {
+			return paths, err
+		}
+		if err := files[i].Sync(); err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
+
+// openShardFiles opens the given shard files, parses and cross-checks their
+// headers, and returns readers positioned at the share data.
+func openShardFiles(paths []string) (shardFileHeader, []byte, []io.Reader, func(), error) {
+	var files []*os.File
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	var first shardFileHeader
+	xs := make([]byte, 0, len(paths))
+	readers := make([]io.Reader, 0, len(paths))
+	seen := make(map[byte]bool)
+
+	for i, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return first, nil, nil, nil, err
+		}
+		files = append(files, f)
+
+		br := bufio.NewReader(f)
+		h, err := readShardFileHeader(br)
+		if err != nil {
+			closeAll()
+			return first, nil, nil, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if i == 0 {
+			first = h
+		} else if h.Filename != first.Filename || h.Size != first.Size || h.SHA256 != first.SHA256 || h.Threshold != first.Threshold || h.Format != first.Format {
+			closeAll()
+			return first, nil, nil, nil, fmt.Errorf("%s belongs to a different shard set", filepath.Base(path))
+		}
+		if seen[h.X] {
+			closeAll()
+			return first, nil, nil, nil, fmt.Errorf("%s duplicates shard %02x", filepath.Base(path), h.X)
+		}
+		seen[h.X] = true
+
+		xs = append(xs, h.X)
+		readers = append(readers, br)
+	}
+
+	if len(paths) < first.Threshold {
+		closeAll()
+		return first, nil, nil, nil, fmt.Errorf("%d shard files provided, %d required", len(paths), first.Threshold)
+	}
+	return first, xs, readers, closeAll, nil
+}
+
+// combineFilesToDir restores the original file or directory from shard files
+// into dir and returns its path. The output is written to a temporary
+// location and only moved into place once its size and SHA-256 match the
+// shard headers. An existing file is never overwritten.
+func combineFilesToDir(paths []string, dir string) (string, error) {
+	if len(paths) < 2 {
+		return "", errors.New("at least 2 shard files are required")
+	}
+
+	header, xs, readers, closeAll, err := openShardFiles(paths)
+	if err != nil {
+		return "", err
+	}
+	defer closeAll()
+
+	name, err := safeFilename(header.Filename)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(dir, name)
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+	if header.Format != formatRaw {
+		return restoreArchive(header, xs, readers, dir, name)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".orcrux-restore-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	if err := combineVerified(io.MultiWriter(tmp, h), h, xs, readers, header.Size, header.SHA256); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// combineVerified reconstructs shares into w and checks the result against
+// the expected size and digest, using h to observe what was written.
+func combineVerified(w io.Writer, h hash.Hash, xs []byte, readers []io.Reader, size int64, digest string) error {
+	written, err := shamir.CombineStream(w, xs, readers)
+	if err != nil {
+		return err
+	}
+	if written != size || hex.EncodeToString(h.Sum(nil)) != digest {
+		return errors.New("restored data does not match the checksum in the shard files")
+	}
+	return nil
+}
+
+// SplitFile splits a file from disk into shard files.
+//
+// This function presents a native file picker (any file type) for the file
+// to split, then a directory picker for the shard files. The file is streamed
+// through the shamir package, so it is never loaded into memory as a whole.
+// Each shard file starts with a header recording the original file name,
+// size and SHA-256, which CombineToFile uses to restore it exactly.
+//
+// Parameters:
+//   - shards: Total number of shard files to create (2-255)
+//   - shardsNeeded: Number of shard files required to restore the file
+//
+// Returns:
+//   - A JSON Response whose data is the list of shard file paths, or null if
+//     the user cancelled one of the dialogs
+func (a *App) SplitFile(shards int, shardsNeeded int) string {
+	src, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select a file to split"})
+	if err != nil || src == "" {
+		return newResponse(nil, err)
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select where to save the shard files"})
+	if err != nil || dir == "" {
+		return newResponse(nil, err)
+	}
+
+	paths, err := splitFileToDir(src, dir, shards, shardsNeeded)
+	return newResponse(paths, err)
+}
+
+// CombineToFile restores a file or directory from shard files created by
+// SplitFile or SplitDirectory.
+//
+// This function presents a native file picker to select the shard files and
+// a directory picker for the restored output. It is written under its
+// original name and only kept if its size and SHA-256 match the shard headers.
+//
+// Shard files carry no dealer signature, so they are refused while unsigned
+// shards are rejected.
+//
+// Returns:
+//   - A JSON Response whose data is the path of the restored file or
+//     directory, or null if the user cancelled one of the dialogs
+func (a *App) CombineToFile() string {
+	if _, strict := a.signatureSettings(); strict {
+		return newResponse(nil, errUnsignedShardFiles)
+	}
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select shard files",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Shard files", Pattern: "*" + shardFileExt},
+		},
+	})
+	if err != nil || len(paths) == 0 {
+		return newResponse(nil, err)
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select where to restore the file"})
+	if err != nil || dir == "" {
+		return newResponse(nil, err)
+	}
+
+	dest, err := combineFilesToDir(paths, dir)
+	return newResponse(dest, err)
+}
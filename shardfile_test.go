@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShardFileHeaderRoundTrip(t *testing.T) {
+	want := shardFileHeader{X: 0x0a, Threshold: 3, Shards: 5, Filename: "my \"key\".pem", Size: 1234, SHA256: strings.Repeat("ab", 32), Format: formatTarGzip}
+
+	var buf bytes.Buffer
+	if err := want.write(&buf); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	buf.WriteString("DATA")
+
+	r := bufio.NewReader(&buf)
+	got, err := readShardFileHeader(r)
+	if err != nil {
+		t.Fatalf("readShardFileHeader() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("readShardFileHeader() = %+v, want %+v", got, want)
+	}
+
+	rest, _ := r.ReadString(0)
+	if rest != "DATA" {
+		t.Errorf("reader not positioned at share data, remaining %q", rest)
+	}
+}
+
+func TestReadShardFileHeaderDefaultFormat(t *testing.T) {
+	input := shardFileMagic + "\nx: 01\nthreshold: 2\nshards: 3\nfilename: \"a.txt\"\nsize: 1\nsha256: 00\n\n"
+	h, err := readShardFileHeader(bufio.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("readShardFileHeader() error = %v", err)
+	}
+	if h.Format != formatRaw {
+		t.Errorf("readShardFileHeader() format = %q, want %q", h.Format, formatRaw)
+	}
+}
+
+func TestReadShardFileHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "not a shard file", input: "hello\n\n"},
+		{name: "truncated", input: shardFileMagic + "\nx: 01\n"},
+		{name: "missing field", input: shardFileMagic + "\nx: 01\nthreshold: 2\n\n"},
+		{name: "zero x-coordinate", input: shardFileMagic + "\nx: 00\n\n"},
+		{name: "bad size", input: shardFileMagic + "\nsize: many\n\n"},
+		{name: "malformed line", input: shardFileMagic + "\nnonsense\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readShardFileHeader(bufio.NewReader(strings.NewReader(tt.input))); err == nil {
+				t.Error("readShardFileHeader() should have returned an error")
+			}
+		})
+	}
+}
+
+func TestSafeFilename(t *testing.T) {
+	for _, name := range []string{"secret.bin", "key.pem", ".env"} {
+		if _, err := safeFilename(name); err != nil {
+			t.Errorf("safeFilename(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../secret", "/etc/passwd", "a/b", `a\b`} {
+		if _, err := safeFilename(name); err == nil {
+			t.Errorf("safeFilename(%q) should have returned an error", name)
+		}
+	}
+}
+
+func TestSplitAndCombineFile(t *testing.T) {
+	srcDir, shardDir, restoreDir := t.TempDir(), t.TempDir(), t.TempDir()
+
+	content := make([]byte, 100*1024+3)
+	for i := range content {
+		content[i] = byte(i * 31)
+	}
+	src := filepath.Join(srcDir, "keystore.jks")
+	if err := os.WriteFile(src, content, 0600); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+
+	paths, err := splitFileToDir(src, shardDir, 5, 3)
+	if err != nil {
+		t.Fatalf("splitFileToDir() error = %v", err)
+	}
+	if len(paths) != 5 {
+		t.Fatalf("splitFileToDir() wrote %d files, want 5", len(paths))
+	}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("shard file missing: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("shard file %s has mode %v, want 0600", p, info.Mode().Perm())
+		}
+	}
+
+	dest, err := combineFilesToDir([]string{paths[4], paths[0], paths[2]}, restoreDir)
+	if err != nil {
+		t.Fatalf("combineFilesToDir() error = %v", err)
+	}
+	if dest != filepath.Join(restoreDir, "keystore.jks") {
+		t.Errorf("combineFilesToDir() restored to %s", dest)
+	}
+	restored, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("Failed to read restored file: %v", err)
+	}
+	if !bytes.Equal(restored, content) {
+		t.Error("restored file does not match the original")
+	}
+
+	t.Run("refuses to overwrite", func(t *testing.T) {
+		if _, err := combineFilesToDir(paths[:3], restoreDir); err == nil {
+			t.Error("combineFilesToDir() should not overwrite an existing file")
+		}
+	})
+
+	t.Run("below threshold", func(t *testing.T) {
+		if _, err := combineFilesToDir(paths[:2], t.TempDir()); err == nil {
+			t.Error("combineFilesToDir() should fail below the threshold")
+		}
+	})
+
+	t.Run("duplicate shard", func(t *testing.T) {
+		if _, err := combineFilesToDir([]string{paths[0], paths[0], paths[1]}, t.TempDir()); err == nil {
+			t.Error("combineFilesToDir() should reject duplicate shards")
+		}
+	})
+
+	t.Run("corrupted shard", func(t *testing.T) {
+		data, err := os.ReadFile(paths[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[len(data)-1] ^= 0xff
+		corrupted := filepath.Join(t.TempDir(), "corrupted"+shardFileExt)
+		if err := os.WriteFile(corrupted, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		out := t.TempDir()
+		if _, err := combineFilesToDir([]string{paths[0], corrupted, paths[2]}, out); err == nil {
+			t.Error("combineFilesToDir() should detect a checksum mismatch")
+		}
+		if entries, _ := os.ReadDir(out); len(entries) != 0 {
+			t.Errorf("combineFilesToDir() left %d file(s) behind after failing", len(entries))
+		}
+	})
+}
+
+func TestCombineToFileStrict(t *testing.T) {
+	app := NewApp()
+	app.strictSignatures = true
+
+	// Refused before any dialog is opened
+	var response Response
+	json.Unmarshal([]byte(app.CombineToFile()), &response)
+	if response.Error == nil || response.Code != "unsigned" {
+		t.Errorf("CombineToFile() in strict mode = %v, code %q, want unsigned", response.Error, response.Code)
+	}
+}
+
+func TestSplitFileToDirErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(src, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		out := t.TempDir()
+		if _, err := splitFileToDir(src, out, 3, 4); err == nil {
+			t.Error("splitFileToDir() should reject an invalid threshold")
+		}
+		if entries, _ := os.ReadDir(out); len(entries) != 0 {
+			t.Errorf("splitFileToDir() left %d file(s) behind after failing", len(entries))
+		}
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		if _, err := splitFileToDir(filepath.Join(dir, "missing"), t.TempDir(), 3, 2); err == nil {
+			t.Error("splitFileToDir() should fail for a missing source")
+		}
+	})
+}
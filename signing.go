@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// shardSignatureSep separates a shard from its signature, as in
+	// "01:hex:...#<base64url signature>". No share encoding uses it.
+	shardSignatureSep = "#"
+
+	// Signatures are computed over a context string followed by the signed
+	// content, so a shard signature can never pass for a manifest signature.
+	shardSignatureContext    = "orcrux shard signature v1\n"
+	manifestSignatureContext = "orcrux manifest signature v1\n"
+
+	// pinnedDealerKeyFile is the name of the pinned dealer public key in the
+	// orcrux config directory.
+	pinnedDealerKeyFile = "dealer.pub"
+)
+
+var (
+	// ErrUnsigned is returned in strict mode for a shard or manifest without
+	// a dealer signature.
+	ErrUnsigned = errors.New("not signed by the dealer")
+
+	// ErrBadSignature is returned when a signature was not made with the
+	// pinned dealer key.
+	ErrBadSignature = errors.New("signature does not match the pinned dealer key")
+
+	// ErrNoDealerKey is returned when signatures must be checked but no
+	// dealer key is pinned.
+	ErrNoDealerKey = errors.New("no dealer key is pinned")
+)
+
+// DealerKeyStatus describes the keys used to sign and verify shards.
+type DealerKeyStatus struct {
+	Signing string `json:"signing,omitempty"` // Fingerprint of the loaded signing key
+	Pinned  string `json:"pinned,omitempty"`  // Fingerprint of the pinned dealer key
+	Strict  bool   `json:"strict"`            // Whether unsigned shards are rejected
+}
+
+// ShardSignature is the result of VerifyShard.
+type ShardSignature struct {
+	Signed bool   `json:"signed"`
+	Valid  bool   `json:"valid"`  // Signed with the pinned dealer key
+	Dealer string `json:"dealer"` // Fingerprint of the pinned dealer key
+}
+
+// keyFingerprint returns a short hex fingerprint of a dealer public key,
+// for people to compare.
+func keyFingerprint(pub ed25519.PublicKey) string {
+	if pub == nil {
+		return ""
+	}
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// parseDealerPrivateKey parses a PEM-encoded PKCS #8 Ed25519 private key, as
+// written by `openssl genpkey -algorithm ed25519`.
+func parseDealerPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("not a PEM private key")
+	}
+	defer securemem.Wipe(block.Bytes)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("dealer key must be an Ed25519 key")
+	}
+	return priv, nil
+}
+
+// parseDealerPublicKey parses a PEM-encoded Ed25519 public key. A private
+// key is accepted too, and its public half is returned.
+func parseDealerPublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("not a PEM key")
+	}
+	if block.Type == "PRIVATE KEY" {
+		priv, err := parseDealerPrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return priv.Public().(ed25519.PublicKey), nil
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("dealer key must be an Ed25519 key")
+	}
+	return pub, nil
+}
+
+// marshalDealerPublicKey encodes a dealer public key as PEM.
+func marshalDealerPublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// pinnedDealerKeyPath returns where the pinned dealer key is stored.
+func pinnedDealerKeyPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orcrux", pinnedDealerKeyFile), nil
+}
+
+// loadPinnedDealerKey reads the pinned dealer key at path. A missing file
+// means no key is pinned.
+func loadPinnedDealerKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDealerPublicKey(data)
+}
+
+// savePinnedDealerKey writes the pinned dealer key to path.
+func savePinnedDealerKey(path string, pub ed25519.PublicKey) error {
+	data, err := marshalDealerPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// splitShardSignature separates a shard from its signature. The signature is
+// nil for an unsigned shard.
+func splitShardSignature(text string) (string, []byte, error) {
+	share, encoded, signed := strings.Cut(strings.TrimSpace(text), shardSignatureSep)
+	if !signed {
+		return share, nil, nil
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", nil, errors.New("malformed shard signature")
+	}
+	return strings.TrimSpace(share), sig, nil
+}
+
+// shardSignedMessage returns the message signed for a shard: its canonical
+// hex form, so a signature survives re-encoding and transcription.
+func shardSignedMessage(text string) ([]byte, error) {
+	share, err := shamir.ParseShare(text)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(shardSignatureContext + share.Format(shamir.Hex)), nil
+}
+
+// signShard appends the dealer signature to a shard, replacing any previous one.
+func signShard(key ed25519.PrivateKey, text string) (string, error) {
+	share, _, err := splitShardSignature(text)
+	if err != nil {
+		return "", err
+	}
+	msg, err := shardSignedMessage(share)
+	if err != nil {
+		return "", err
+	}
+	sig := ed25519.Sign(key, msg)
+	return share + shardSignatureSep + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// signShards signs every shard of the newline-separated output of Split.
+func signShards(key ed25519.PrivateKey, out string) (string, error) {
+	var signed []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		s, err := signShard(key, line)
+		if err != nil {
+			return "", err
+		}
+		signed = append(signed, s)
+	}
+	return strings.Join(signed, "\n"), nil
+}
+
+// verifyShardSignature checks the signature of a shard and returns the
+// shard without it. signed is false for an unsigned shard, which is not an
+// error here.
+func verifyShardSignature(pub ed25519.PublicKey, text string) (share string, signed bool, err error) {
+	share, sig, err := splitShardSignature(text)
+	if err != nil || sig == nil {
+		return share, false, err
+	}
+	msg, err := shardSignedMessage(share)
+	if err != nil {
+		return share, true, err
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		return share, true, ErrBadSignature
+	}
+	return share, true, nil
+}
+
+// checkShardSignatures verifies the signatures of shards against the pinned
+// dealer key and returns the shards without their signatures.
+//
+// Without a pinned key, signatures are only stripped. With one, a shard with
+// a bad signature is always rejected, and in strict mode so is an unsigned
+// shard. Errors are ShareParseErrors carrying the index of the shard.
+func checkShardSignatures(pub ed25519.PublicKey, strict bool, shards []string) ([]string, error) {
+	if strict && pub == nil {
+		return nil, ErrNoDealerKey
+	}
+	stripped := make([]string, len(shards))
+	for i, text := range shards {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		var share string
+		var signed bool
+		var err error
+		if pub == nil {
+			share, _, err = splitShardSignature(text)
+		} else {
+			share, signed, err = verifyShardSignature(pub, text)
+			if err == nil && !signed && strict {
+				err = ErrUnsigned
+			}
+		}
+		if err != nil {
+			return nil, &shamir.ShareParseError{Index: i, Reason: err.Error(), Err: err}
+		}
+		stripped[i] = share
+	}
+	return stripped, nil
+}
+
+// signManifest signs the sealed manifest with the dealer key.
+func signManifest(key ed25519.PrivateKey, m *Manifest) {
+	sig := ed25519.Sign(key, []byte(manifestSignatureContext+m.Digest))
+	m.Signature = base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// checkManifestSignature verifies the signature of a sealed manifest with
+// the same rules as checkShardSignatures.
+func checkManifestSignature(pub ed25519.PublicKey, strict bool, m *Manifest) error {
+	if strict && pub == nil {
+		return ErrNoDealerKey
+	}
+	if pub == nil {
+		return nil
+	}
+	if m.Signature == "" {
+		if strict {
+			return fmt.Errorf("manifest %w", ErrUnsigned)
+		}
+		return nil
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(m.Signature)
+	if err != nil || !ed25519.Verify(pub, []byte(manifestSignatureContext+m.Digest), sig) {
+		return fmt.Errorf("manifest %w", ErrBadSignature)
+	}
+	return nil
+}
+
+// signatureSettings returns the pinned dealer key and the strict mode flag.
+func (a *App) signatureSettings() (ed25519.PublicKey, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pinnedKey, a.strictSignatures
+}
+
+// verifiedShards checks the signatures of shards with the App settings and
+// returns them ready for shamir.Recompose.
+func (a *App) verifiedShards(shards []string) ([]string, error) {
+	pub, strict := a.signatureSettings()
+	return checkShardSignatures(pub, strict, shards)
+}
+
+// splitResponse signs the output of a split with the dealer key, if one is
+// loaded, and wraps it in a response.
+func (a *App) splitResponse(out string, err error) string {
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	key := a.signingKey()
+	defer securemem.Wipe(key)
+	if key != nil {
+		out, err = signShards(key, out)
+	}
+	return newResponse(out, err)
+}
+
+// signingKey returns a copy of the loaded dealer key, or nil. The caller
+// wipes the copy once done, which leaves the loaded key free to be replaced
+// and wiped while shards are being signed.
+func (a *App) signingKey() ed25519.PrivateKey {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.dealerKey == nil {
+		return nil
+	}
+	return bytes.Clone(a.dealerKey)
+}
+
+// setDealerKey replaces the loaded dealer key, wiping the previous one.
+func (a *App) setDealerKey(key ed25519.PrivateKey) {
+	// The key is kept for the whole session; keep it out of swap
+	securemem.Lock(key)
+	a.mu.Lock()
+	old := a.dealerKey
+	a.dealerKey = key
+	a.mu.Unlock()
+	securemem.WipeAndUnlock(old)
+}
+
+// LoadDealerKey opens a file dialog to select the dealer's Ed25519 signing key.
+//
+// Once loaded, every shard produced by Split, SplitBytes and SplitPath and
+// every manifest is signed with it. The key is a PEM PKCS #8 file, such as
+// one created with `openssl genpkey -algorithm ed25519`; it is kept in memory
+// only.
+//
+// Returns:
+//   - A JSON response with the DealerKeyStatus, null data if the dialog was
+//     cancelled, or an error if the file is not an Ed25519 private key
+func (a *App) LoadDealerKey() string {
+	fd := runtime.OpenDialogOptions{
+		Title: "Select the dealer signing key",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PEM keys", Pattern: "*.pem;*.key"},
+		},
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, fd)
+	if err != nil || path == "" {
+		return newResponse(nil, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	defer securemem.Wipe(data)
+	key, err := parseDealerPrivateKey(data)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+
+	a.setDealerKey(key)
+	return newResponse(a.dealerKeyStatus(), nil)
+}
+
+// PinDealerKey opens a file dialog to select the dealer's public key and
+// pins it: shard and manifest signatures are checked against it from then on,
+// including after a restart.
+//
+// Returns:
+//   - A JSON response with the DealerKeyStatus, null data if the dialog was
+//     cancelled, or an error if the file is not an Ed25519 key or the pin
+//     cannot be saved
+func (a *App) PinDealerKey() string {
+	fd := runtime.OpenDialogOptions{
+		Title: "Select the dealer public key",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PEM keys", Pattern: "*.pem;*.pub"},
+		},
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, fd)
+	if err != nil || path == "" {
+		return newResponse(nil, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	pub, err := parseDealerPublicKey(data)
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	pinPath, err := pinnedDealerKeyPath()
+	if err != nil {
+		return newResponse(nil, err)
+	}
+	if err := savePinnedDealerKey(pinPath, pub); err != nil {
+		return newResponse(nil, err)
+	}
+
+	a.mu.Lock()
+	a.pinnedKey = pub
+	a.mu.Unlock()
+	return newResponse(a.dealerKeyStatus(), nil)
+}
+
+// SetStrictSignatures turns strict mode on or off. In strict mode, unsigned
+// shards and manifests are rejected, and so is everything if no dealer key
+// is pinned.
+func (a *App) SetStrictSignatures(strict bool) {
+	a.mu.Lock()
+	a.strictSignatures = strict
+	a.mu.Unlock()
+}
+
+// DealerKey returns the fingerprints of the loaded signing key and of the
+// pinned dealer key, and whether strict mode is on.
+//
+// Returns:
+//   - A JSON response with the DealerKeyStatus
+func (a *App) DealerKey() string {
+	return newResponse(a.dealerKeyStatus(), nil)
+}
+
+func (a *App) dealerKeyStatus() DealerKeyStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	status := DealerKeyStatus{Pinned: keyFingerprint(a.pinnedKey), Strict: a.strictSignatures}
+	if a.dealerKey != nil {
+		status.Signing = keyFingerprint(a.dealerKey.Public().(ed25519.PublicKey))
+	}
+	return status
+}
+
+// VerifyShard checks the signature of a single shard against the pinned
+// dealer key, so a custodian can authenticate a shard on receipt.
+//
+// Parameters:
+//   - shard: The shard, with or without a signature
+//
+// Returns:
+//   - A JSON response with the ShardSignature, or an error if no dealer key
+//     is pinned or the shard or its signature cannot be parsed
+func (a *App) VerifyShard(shard string) string {
+	pub, _ := a.signatureSettings()
+	if pub == nil {
+		return newResponse(nil, ErrNoDealerKey)
+	}
+	_, signed, err := verifyShardSignature(pub, shard)
+	if err != nil && !errors.Is(err, ErrBadSignature) {
+		return newResponse(nil, err)
+	}
+	return newResponse(ShardSignature{Signed: signed, Valid: signed && err == nil, Dealer: keyFingerprint(pub)}, nil)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"orcrux/securemem"
+	"orcrux/shamir"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newDealerKey returns a fresh dealer key and its PEM PKCS #8 encoding.
+func newDealerKey(t *testing.T) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestParseDealerKeys(t *testing.T) {
+	priv, privPEM := newDealerKey(t)
+	parsed, err := parseDealerPrivateKey(privPEM)
+	if err != nil || !parsed.Equal(priv) {
+		t.Fatalf("parseDealerPrivateKey() = %v, %v", parsed, err)
+	}
+
+	pubPEM, err := marshalDealerPublicKey(priv.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{pubPEM, privPEM} {
+		pub, err := parseDealerPublicKey(data)
+		if err != nil || !pub.Equal(priv.Public()) {
+			t.Errorf("parseDealerPublicKey() = %v, %v", pub, err)
+		}
+	}
+
+	if _, err := parseDealerPrivateKey(pubPEM); err == nil {
+		t.Error("parseDealerPrivateKey() should reject a public key")
+	}
+	if _, err := parseDealerPublicKey([]byte("not a key")); err == nil {
+		t.Error("parseDealerPublicKey() should reject non-PEM data")
+	}
+}
+
+func TestPinnedDealerKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orcrux", pinnedDealerKeyFile)
+	if pub, err := loadPinnedDealerKey(path); pub != nil || err != nil {
+		t.Fatalf("loadPinnedDealerKey() without a pin = %v, %v", pub, err)
+	}
+	priv, _ := newDealerKey(t)
+	if err := savePinnedDealerKey(path, priv.Public().(ed25519.PublicKey)); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := loadPinnedDealerKey(path)
+	if err != nil || !pub.Equal(priv.Public()) {
+		t.Errorf("loadPinnedDealerKey() = %v, %v", pub, err)
+	}
+}
+
+func TestShardSignatures(t *testing.T) {
+	priv, _ := newDealerKey(t)
+	pub := priv.Public().(ed25519.PublicKey)
+	other, _ := newDealerKey(t)
+
+	out, _ := shamir.Split([]byte("secret"), 3, 2, "grouped")
+	signed, err := signShards(priv, out)
+	if err != nil {
+		t.Fatalf("signShards() error = %v", err)
+	}
+	shards := strings.Split(signed, "\n")
+
+	stripped, err := checkShardSignatures(pub, true, shards)
+	if err != nil {
+		t.Fatalf("checkShardSignatures() error = %v", err)
+	}
+	if got, err := shamir.Recompose(stripped); err != nil || string(got) != "secret" {
+		t.Errorf("Recompose() of verified shards = %q, %v", got, err)
+	}
+
+	// Re-encoding a shard keeps its signature valid
+	text, sig, _ := splitShardSignature(shards[0])
+	share, _ := shamir.ParseShare(text)
+	reencoded := share.Format(shamir.Base64) + shardSignatureSep + strings.SplitN(shards[0], shardSignatureSep, 2)[1]
+	if _, signed, err := verifyShardSignature(pub, reencoded); !signed || err != nil {
+		t.Errorf("verifyShardSignature() of a re-encoded shard = %v, %v", signed, err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		t.Errorf("signature length = %d", len(sig))
+	}
+
+	forged, _ := signShard(other, shards[1])
+	unsigned, _, _ := splitShardSignature(shards[2])
+	tests := []struct {
+		name   string
+		shards []string
+		pub    ed25519.PublicKey
+		strict bool
+		want   error
+		index  int
+	}{
+		{"forged", []string{shards[0], forged}, pub, false, ErrBadSignature, 1},
+		{"unsigned strict", []string{unsigned, shards[0]}, pub, true, ErrUnsigned, 0},
+		{"strict without key", shards, nil, true, ErrNoDealerKey, -1},
+	}
+	for _, tt := range tests {
+		_, err := checkShardSignatures(tt.pub, tt.strict, tt.shards)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		var spe *shamir.ShareParseError
+		if tt.index >= 0 && (!errors.As(err, &spe) || spe.Index != tt.index) {
+			t.Errorf("%s: error = %v, want index %d", tt.name, err, tt.index)
+		}
+	}
+
+	// Unsigned shards pass outside strict mode, and signatures are only
+	// stripped when no key is pinned
+	if _, err := checkShardSignatures(pub, false, []string{unsigned, shards[0]}); err != nil {
+		t.Errorf("checkShardSignatures() of an unsigned shard error = %v", err)
+	}
+	if _, err := checkShardSignatures(nil, false, []string{forged}); err != nil {
+		t.Errorf("checkShardSignatures() without a key error = %v", err)
+	}
+	if _, err := checkShardSignatures(pub, false, []string{shards[0] + "x"}); err == nil {
+		t.Error("checkShardSignatures() should reject a malformed signature")
+	}
+}
+
+func TestManifestSignature(t *testing.T) {
+	priv, _ := newDealerKey(t)
+	pub := priv.Public().(ed25519.PublicKey)
+	other, _ := newDealerKey(t)
+
+	out, _ := shamir.Split([]byte("secret"), 2, 2, "hex")
+	signed, _ := signShards(priv, out)
+	m, err := newManifest(testSetID, strings.Split(signed, "\n"), nil, 2)
+	if err != nil {
+		t.Fatalf("newManifest() of signed shards error = %v", err)
+	}
+	if err := checkManifestSignature(pub, true, m); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("unsigned manifest in strict mode error = %v, want ErrUnsigned", err)
+	}
+
+	signManifest(priv, m)
+	data, _ := json.Marshal(m)
+	loaded, err := parseManifest(data)
+	if err != nil {
+		t.Fatalf("parseManifest() of a signed manifest error = %v", err)
+	}
+	if err := checkManifestSignature(pub, true, loaded); err != nil {
+		t.Errorf("checkManifestSignature() error = %v", err)
+	}
+	if err := checkManifestSignature(other.Public().(ed25519.PublicKey), false, loaded); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("checkManifestSignature() with another key error = %v, want ErrBadSignature", err)
+	}
+	if check := loaded.Check(strings.Split(signed, "\n")); !check.Ready {
+		t.Errorf("Check() of signed shards = %+v", check)
+	}
+}
+
+func TestAppReplaceDealerKey(t *testing.T) {
+	first, _ := newDealerKey(t)
+	second, _ := newDealerKey(t)
+	app := NewApp()
+	app.setDealerKey(first)
+
+	// Signers work on a copy, which the replacement does not affect
+	key := app.signingKey()
+	app.setDealerKey(second)
+	if !securemem.IsZero(first) {
+		t.Error("setDealerKey() left the previous key in memory")
+	}
+	if securemem.IsZero(key) {
+		t.Error("setDealerKey() wiped a copy still held by a signer")
+	}
+	if !app.signingKey().Equal(second) {
+		t.Error("signingKey() does not return the new key")
+	}
+}
+
+func TestAppSignsAndVerifies(t *testing.T) {
+	priv, _ := newDealerKey(t)
+	app := NewApp()
+	app.dealerKey = priv
+	app.pinnedKey = priv.Public().(ed25519.PublicKey)
+	app.SetStrictSignatures(true)
+
+	var split Response
+	json.Unmarshal([]byte(app.Split("hunter2", 3, 2, "hex")), &split)
+	shards := strings.Split(split.Data.(string), "\n")
+	for _, s := range shards {
+		if !strings.Contains(s, shardSignatureSep) {
+			t.Fatalf("Split() returned an unsigned shard %q", s)
+		}
+	}
+
+	var recomposed Response
+	json.Unmarshal([]byte(app.Recompose(shards[:2])), &recomposed)
+	if recomposed.Error != nil || recomposed.Data != "hunter2" {
+		t.Errorf("Recompose() = %+v", recomposed)
+	}
+
+	unsigned, _, _ := splitShardSignature(shards[0])
+	var rejected Response
+	json.Unmarshal([]byte(app.RecomposeBytes([]string{shards[1], unsigned})), &rejected)
+	if rejected.Code != "unsigned" || rejected.Index == nil || *rejected.Index != 1 {
+		t.Errorf("RecomposeBytes() of an unsigned shard = %+v", rejected)
+	}
+
+	var verified Response
+	json.Unmarshal([]byte(app.VerifyShard(unsigned)), &verified)
+	if v := verified.Data.(map[string]interface{}); v["signed"] != false || v["valid"] != false {
+		t.Errorf("VerifyShard() of an unsigned shard = %v", v)
+	}
+	json.Unmarshal([]byte(app.VerifyShard(shards[2])), &verified)
+	if v := verified.Data.(map[string]interface{}); v["valid"] != true || v["dealer"] != keyFingerprint(app.pinnedKey) {
+		t.Errorf("VerifyShard() = %v", v)
+	}
+
+	var noKey Response
+	json.Unmarshal([]byte(NewApp().VerifyShard(shards[0])), &noKey)
+	if noKey.Code != "no_dealer_key" {
+		t.Errorf("VerifyShard() without a pinned key code = %q", noKey.Code)
+	}
+}
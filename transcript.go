@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript events that close a ceremony. A transcript that does not end
+// with one of them was cut short.
+const (
+	eventCeremonyCompleted = "ceremony_completed"
+	eventCeremonyAborted   = "ceremony_aborted"
+)
+
+// transcriptGenesis is the previous hash of the first entry.
+var transcriptGenesis = strings.Repeat("0", sha256.Size*2)
+
+// transcriptEntry is one line of a ceremony transcript. Each entry records
+// the hash of the previous one, so removing, reordering or editing an entry
+// breaks the chain.
+type transcriptEntry struct {
+	Seq   int             `json:"seq"`
+	Time  time.Time       `json:"time"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Prev  string          `json:"prev"`
+	Hash  string          `json:"hash"`
+}
+
+// hash computes the hash of the entry, ignoring its Hash field.
+func (e transcriptEntry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// transcript appends hash-chained entries to a JSON Lines file.
+type transcript struct {
+	path string
+	f    *os.File
+	seq  int
+	head string // Hash of the last entry
+}
+
+// createTranscript creates a new transcript file at path. An existing file
+// is never overwritten.
+func createTranscript(path string) (*transcript, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &transcript{path: path, f: f, head: transcriptGenesis}, nil
+}
+
+// append writes an entry and syncs the file, so the transcript survives a
+// crash in the middle of a ceremony.
+func (t *transcript) append(event string, data interface{}) error {
+	entry := transcriptEntry{
+		Seq:   t.seq + 1,
+		Time:  time.Now().UTC(),
+		Event: event,
+		Prev:  t.head,
+	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		entry.Data = raw
+	}
+	hash, err := entry.hash()
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := t.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := t.f.Sync(); err != nil {
+		return err
+	}
+	t.seq, t.head = entry.Seq, entry.Hash
+	return nil
+}
+
+// close closes the transcript file.
+func (t *transcript) close() error {
+	return t.f.Close()
+}
+
+// transcriptSummary describes a verified transcript.
+type transcriptSummary struct {
+	Entries  int
+	Head     string // Hash of the last entry
+	Closed   string // Closing event, empty if the transcript was cut short
+	Started  time.Time
+	Finished time.Time
+}
+
+// verifyTranscript checks the hash chain of a transcript read from r.
+func verifyTranscript(r io.Reader) (transcriptSummary, error) {
+	summary := transcriptSummary{Head: transcriptGenesis}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		n := summary.Entries + 1
+		if summary.Closed != "" {
+			return summary, fmt.Errorf("line %d: entry after the ceremony was closed", n)
+		}
+
+		var entry transcriptEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return summary, fmt.Errorf("line %d: invalid entry: %w", n, err)
+		}
+		if entry.Seq != n {
+			return summary, fmt.Errorf("line %d: sequence number is %d", n, entry.Seq)
+		}
+		if entry.Prev != summary.Head {
+			return summary, fmt.Errorf("line %d: does not follow the previous entry", n)
+		}
+		hash, err := entry.hash()
+		if err != nil {
+			return summary, err
+		}
+		if hash != entry.Hash {
+			return summary, fmt.Errorf("line %d: hash does not match the entry", n)
+		}
+
+		if n == 1 {
+			summary.Started = entry.Time
+		}
+		summary.Entries, summary.Head, summary.Finished = n, entry.Hash, entry.Time
+		if entry.Event == eventCeremonyCompleted || entry.Event == eventCeremonyAborted {
+			summary.Closed = entry.Event
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, err
+	}
+	if summary.Entries == 0 {
+		return summary, errors.New("transcript is empty")
+	}
+	return summary, nil
+}
+
+// runVerifyTranscript implements "orcrux verify-transcript": it checks the
+// hash chain of a ceremony transcript and prints its final hash.
+//
+// Parameters:
+//   - args: Command line arguments following "verify-transcript"
+//   - stdout: Where the summary is printed
+//
+// Returns:
+//   - An error if the arguments are invalid, the chain is broken, the
+//     ceremony was not closed, or the final hash differs from -head
+func runVerifyTranscript(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify-transcript", flag.ContinueOnError)
+	head := fs.String("head", "", "expected hash of the last entry, as noted at the end of the ceremony")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: orcrux verify-transcript [-head hash] transcript.jsonl")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	summary, err := verifyTranscript(f)
+	if err != nil {
+		return err
+	}
+	if summary.Closed == "" {
+		return fmt.Errorf("transcript ends after %d entries without closing the ceremony", summary.Entries)
+	}
+	if *head != "" && !strings.EqualFold(*head, summary.Head) {
+		return fmt.Errorf("final hash is %s, expected %s", summary.Head, *head)
+	}
+
+	fmt.Fprintf(stdout, "transcript OK: %d entries, %s, %s to %s\nfinal hash: %s\n",
+		summary.Entries, strings.ReplaceAll(summary.Closed, "_", " "),
+		summary.Started.Format(time.RFC3339), summary.Finished.Format(time.RFC3339), summary.Head)
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestTranscript writes a closed transcript with the given events and
+// returns its path.
+func writeTestTranscript(t *testing.T, events ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	tr, err := createTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, event := range events {
+		if err := tr.append(event, map[string]int{"step": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tr.close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyTranscript(t *testing.T) {
+	path := writeTestTranscript(t, "ceremony_started", "participant_registered", eventCeremonyCompleted)
+	data, _ := os.ReadFile(path)
+
+	summary, err := verifyTranscript(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("verifyTranscript() error = %v", err)
+	}
+	if summary.Entries != 3 || summary.Closed != eventCeremonyCompleted || summary.Head == transcriptGenesis {
+		t.Errorf("verifyTranscript() = %+v", summary)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	tests := map[string]string{
+		"edited":    strings.Replace(string(data), `"step":1`, `"step":7`, 1),
+		"removed":   lines[0] + lines[2],
+		"reordered": lines[1] + lines[0] + lines[2],
+		"appended":  string(data) + lines[2],
+		"garbage":   string(data[:len(data)-10]) + "\n",
+		"empty":     "",
+	}
+	for name, text := range tests {
+		if _, err := verifyTranscript(strings.NewReader(text)); err == nil {
+			t.Errorf("verifyTranscript() accepted a %s transcript", name)
+		}
+	}
+}
+
+func TestCreateTranscriptKeepsExistingFile(t *testing.T) {
+	path := writeTestTranscript(t, "ceremony_started")
+	if _, err := createTranscript(path); err == nil {
+		t.Error("createTranscript() should not overwrite an existing transcript")
+	}
+}
+
+func TestRunVerifyTranscript(t *testing.T) {
+	path := writeTestTranscript(t, "ceremony_started", eventCeremonyAborted)
+	summary, _ := verifyTranscript(mustOpen(t, path))
+
+	var out bytes.Buffer
+	if err := runVerifyTranscript([]string{"-head", summary.Head, path}, &out); err != nil {
+		t.Fatalf("runVerifyTranscript() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "2 entries, ceremony aborted") || !strings.Contains(out.String(), summary.Head) {
+		t.Errorf("runVerifyTranscript() output = %q", out.String())
+	}
+
+	if err := runVerifyTranscript([]string{"-head", transcriptGenesis, path}, &out); err == nil {
+		t.Error("runVerifyTranscript() should reject a different final hash")
+	}
+	if err := runVerifyTranscript([]string{writeTestTranscript(t, "ceremony_started")}, &out); err == nil {
+		t.Error("runVerifyTranscript() should reject a transcript that was not closed")
+	}
+	if err := runVerifyTranscript(nil, &out); err == nil {
+		t.Error("runVerifyTranscript() should require a path")
+	}
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}